@@ -4,47 +4,37 @@ package bridge
 
 /*
 #cgo CFLAGS: -x objective-c -fmodules -fblocks
-#cgo LDFLAGS: -framework Foundation -framework AppKit -framework UniformTypeIdentifiers
+#cgo LDFLAGS: -framework Foundation -framework AppKit -framework UniformTypeIdentifiers -framework CoreServices -framework Security
 #include "bridge.h"
 #include <stdlib.h>
 */
 import "C"
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
-	"sort"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
 	"unsafe"
 )
 
-// BridgeError represents an error from the macOS bridge layer
-type BridgeError struct {
-	Code    int
-	Message string
-}
+// writeMu serializes operations that change LaunchServices handler settings.
+// Concurrent writes can fail with ErrSystem or leave an inconsistent state;
+// read-only queries do not take the lock.
+var writeMu sync.Mutex
 
-func (e *BridgeError) Error() string {
-	return fmt.Sprintf("bridge error (code %d): %s", e.Code, e.Message)
+// end logs the result of a traced cgo call, including the C error message if it failed
+func (t callTrace) end(code C.int, cError *C.char) {
+	if t.logger == nil {
+		return
+	}
+	t.finish(int(code), C.GoString(cError))
 }
 
-// Error codes matching bridge.h
-const (
-	ErrOK            = C.BRIDGE_OK
-	ErrInvalidApp    = C.BRIDGE_ERROR_INVALID_APP
-	ErrInvalidUTI    = C.BRIDGE_ERROR_INVALID_UTI
-	ErrInvalidScheme = C.BRIDGE_ERROR_INVALID_SCHEME
-	ErrSystem        = C.BRIDGE_ERROR_SYSTEM
-	ErrUserDeclined  = C.BRIDGE_ERROR_USER_DECLINED
-	ErrNotFound      = C.BRIDGE_ERROR_NOT_FOUND
-)
-
-// Common errors
-var (
-	ErrInvalidParameters = errors.New("invalid parameters")
-	ErrMemoryAllocation  = errors.New("memory allocation failed")
-)
-
 // Helper function to convert C error to Go error
 func cErrorToGoError(code C.int, cError *C.char) error {
 	if code == C.BRIDGE_OK {
@@ -65,475 +55,2642 @@ func cErrorToGoError(code C.int, cError *C.char) error {
 	}
 }
 
-// GetDefaultAppForUTI returns the default application path for a UTI
-//
-// Parameters:
-//   - uti: The Uniform Type Identifier (e.g., "public.plain-text")
-//
-// Returns:
-//   - appPath: Full path to the default application bundle
-//   - error: Error if any
-func GetDefaultAppForUTI(uti string) (string, error) {
-	if uti == "" {
-		return "", ErrInvalidParameters
+// cStatusErrorToGoError converts a C error code and message into a Go error,
+// recording the OSStatus reported by the system
+func cStatusErrorToGoError(code C.int, status C.int, cError *C.char) error {
+	err := cErrorToGoError(code, cError)
+	if bridgeErr, ok := err.(*BridgeError); ok {
+		bridgeErr.OSStatus = int(status)
 	}
+	return err
+}
 
-	cUTI := C.CString(uti)
-	defer C.free(unsafe.Pointer(cUTI))
+// ValidateAppBundle checks that a path points to a loadable application bundle
+func (h *systemHandler) ValidateAppBundle(appPath string) error {
+	if appPath == "" {
+		return ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
 
-	var cAppPath *C.char
 	var cError *C.char
 
-	code := C.GetDefaultAppForUTI(cUTI, &cAppPath, &cError)
+	trace := startCall("ValidateAppBundle", "app", appPath)
+	code := C.ValidateAppBundle(cAppPath, &cError)
+	trace.end(code, cError)
+
+	return cErrorToGoError(code, cError)
+}
+
+// resolveAppPath follows Finder aliases and symlinks so the path refers to the underlying bundle
+//
+// Paths that cannot be resolved are returned unchanged, leaving the caller to report the error.
+func resolveAppPath(appPath string) string {
+	cPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cPath))
+
+	var cResolved *C.char
+	var cError *C.char
+
+	trace := startCall("ResolveAliasPath", "app", appPath)
+	code := C.ResolveAliasPath(cPath, &cResolved, &cError)
+	trace.end(code, cError)
 
 	if code != C.BRIDGE_OK {
-		return "", cErrorToGoError(code, cError)
+		C.FreeCString(cError)
+		return appPath
 	}
 
-	if cAppPath == nil {
-		return "", &BridgeError{
-			Code:    int(ErrNotFound),
-			Message: fmt.Sprintf("no default app found for UTI: %s", uti),
-		}
+	resolved := C.GoString(cResolved)
+	C.FreeCString(cResolved)
+
+	return resolved
+}
+
+// Helper function to convert a C string array to a Go slice, freeing the C array
+func cStringArrayToSlice(cArr **C.char, count C.int) []string {
+	if count == 0 || cArr == nil {
+		return []string{}
 	}
 
-	appPath := C.GoString(cAppPath)
-	C.FreeCString(cAppPath)
+	result := make([]string, int(count))
+	cSlice := unsafe.Slice(cArr, int(count))
 
-	return appPath, nil
+	for i := 0; i < int(count); i++ {
+		result[i] = C.GoString(cSlice[i])
+	}
+
+	C.FreeCStringArray(cArr, count)
+
+	return result
 }
 
-// GetDefaultAppForScheme returns the default application path for a URL scheme
-//
-// Parameters:
-//   - scheme: The URL scheme (e.g., "http", "mailto")
+// Helper function to split a C string array returned for several keys into a map, freeing the C arrays
 //
-// Returns:
-//   - appPath: Full path to the default application bundle
-//   - error: Error if any
-func GetDefaultAppForScheme(scheme string) (string, error) {
-	if scheme == "" {
+// The first cCounts[0] strings belong to keys[0], the next cCounts[1] to
+// keys[1], and so on. Keys with no strings are left out of groups.
+func groupCStringArray(keys []string, cArr **C.char, cCounts *C.int, total C.int, groups map[string][]string) {
+	if cCounts == nil {
+		return
+	}
+	defer C.free(unsafe.Pointer(cCounts))
+
+	all := cStringArrayToSlice(cArr, total)
+	counts := unsafe.Slice(cCounts, len(keys))
+	offset := 0
+	for i, key := range keys {
+		n := int(counts[i])
+		if n > 0 {
+			groups[key] = all[offset : offset+n : offset+n]
+		}
+		offset += n
+	}
+}
+
+// urlContentTypeSchemes maps pasteboard-oriented URL UTIs to the scheme whose handler opens them
+var urlContentTypeSchemes = map[string]string{
+	"public.url":      "http",
+	"public.file-url": "file",
+}
+
+// GetDefaultAppForUTI returns the default application path for a UTI
+func (h *systemHandler) GetDefaultAppForUTI(uti string) (string, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
 		return "", ErrInvalidParameters
 	}
 
-	cScheme := C.CString(scheme)
-	defer C.free(unsafe.Pointer(cScheme))
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
 
 	var cAppPath *C.char
 	var cError *C.char
 
-	code := C.GetDefaultAppForScheme(cScheme, &cAppPath, &cError)
+	trace := startCall("GetDefaultAppForUTI", "uti", uti)
+	code := C.GetDefaultAppForUTI(cUTI, &cAppPath, &cError)
+	trace.end(code, cError)
 
 	if code != C.BRIDGE_OK {
-		return "", cErrorToGoError(code, cError)
+		return h.resolveDefaultAppForUTI(uti, "", cErrorToGoError(code, cError))
 	}
 
 	if cAppPath == nil {
-		return "", &BridgeError{
-			Code:    int(ErrNotFound),
-			Message: fmt.Sprintf("no default app found for scheme: %s", scheme),
-		}
+		return h.resolveDefaultAppForUTI(uti, "", nil)
 	}
 
 	appPath := C.GoString(cAppPath)
 	C.FreeCString(cAppPath)
 
-	return appPath, nil
+	return h.resolveDefaultAppForUTI(uti, appPath, nil)
 }
 
-// SetDefaultForUTI sets the default application for a UTI
-//
-// Parameters:
-//   - appPath: Full path to the application bundle
-//   - uti: The Uniform Type Identifier
+// resolveDefaultAppForUTI turns the result of a LaunchServices lookup for a UTI into its default handler
 //
-// Returns:
-//   - error: Error if any
-func SetDefaultForUTI(appPath, uti string) error {
-	if appPath == "" || uti == "" {
-		return ErrInvalidParameters
-	}
-
-	cAppPath := C.CString(appPath)
-	defer C.free(unsafe.Pointer(cAppPath))
-
-	cUTI := C.CString(uti)
-	defer C.free(unsafe.Pointer(cUTI))
+// appPath is the handler LaunchServices returned, or empty with lookupErr
+// (or nil for a plain miss) if it returned none. URL content types without a
+// handler of their own fall back to their scheme's handler, and the result is
+// checked to still be an application bundle.
+func (h *systemHandler) resolveDefaultAppForUTI(uti, appPath string, lookupErr error) (string, error) {
+	if appPath == "" {
+		if scheme, ok := urlContentTypeSchemes[uti]; ok {
+			schemeAppPath, err := h.GetDefaultAppForScheme(scheme)
+			if err != nil {
+				return "", err
+			}
+			return h.validateDefaultHandler(schemeAppPath)
+		}
 
-	var cError *C.char
+		if lookupErr != nil {
+			return "", lookupErr
+		}
 
-	code := C.SetDefaultForUTI(cAppPath, cUTI, &cError)
+		return "", &BridgeError{
+			Code:    int(ErrNotFound),
+			Message: fmt.Sprintf("no default app found for UTI: %s", uti),
+		}
+	}
 
-	return cErrorToGoError(code, cError)
+	return h.validateDefaultHandler(appPath)
 }
 
-// SetDefaultForScheme sets the default application for a URL scheme
-//
-// Parameters:
-//   - appPath: Full path to the application bundle
-//   - scheme: The URL scheme
-//
-// Returns:
-//   - error: Error if any
-func SetDefaultForScheme(appPath, scheme string) error {
-	if appPath == "" || scheme == "" {
-		return ErrInvalidParameters
+// GetDefaultAppsForUTIs returns the default application paths for several UTIs in a single call
+func (h *systemHandler) GetDefaultAppsForUTIs(utis []string) (map[string]string, error) {
+	defaults := make(map[string]string)
+	if len(utis) == 0 {
+		return defaults, nil
 	}
 
-	cAppPath := C.CString(appPath)
-	defer C.free(unsafe.Pointer(cAppPath))
-
-	cScheme := C.CString(scheme)
-	defer C.free(unsafe.Pointer(cScheme))
-
-	var cError *C.char
-
-	code := C.SetDefaultForScheme(cAppPath, cScheme, &cError)
-
-	return cErrorToGoError(code, cError)
-}
+	cUTIs := C.malloc(C.size_t(len(utis)) * C.size_t(unsafe.Sizeof((*C.char)(nil))))
+	defer C.free(cUTIs)
 
-// ResolveUTIsForExtension resolves a file extension to one or more UTIs
-//
-// Parameters:
-//   - extension: File extension without dot (e.g., "txt", "md")
-//
-// Returns:
-//   - utis: Slice of UTI identifiers
-//   - error: Error if any
-func ResolveUTIsForExtension(extension string) ([]string, error) {
-	if extension == "" {
-		return nil, ErrInvalidParameters
+	cUTIsSlice := unsafe.Slice((**C.char)(cUTIs), len(utis))
+	for i, uti := range utis {
+		cUTIsSlice[i] = C.CString(strings.TrimSpace(uti))
 	}
+	defer func() {
+		for _, cUTI := range cUTIsSlice {
+			C.free(unsafe.Pointer(cUTI))
+		}
+	}()
 
-	cExt := C.CString(extension)
-	defer C.free(unsafe.Pointer(cExt))
-
-	var cUTIs **C.char
-	var count C.int
+	var cAppPaths **C.char
 	var cError *C.char
 
-	code := C.ResolveUTIsForExtension(cExt, &cUTIs, &count, &cError)
+	count := C.int(len(utis))
+	trace := startCall("GetDefaultAppsForUTIs", "utis", utis)
+	code := C.GetDefaultAppsForUTIs((**C.char)(cUTIs), count, &cAppPaths, &cError)
+	trace.end(code, cError)
 
 	if code != C.BRIDGE_OK {
 		return nil, cErrorToGoError(code, cError)
 	}
 
-	if count == 0 {
-		return []string{}, nil
+	appPaths := make([]string, len(utis))
+	if cAppPaths != nil {
+		cAppPathsSlice := unsafe.Slice(cAppPaths, int(count))
+		for i := range utis {
+			if cAppPathsSlice[i] != nil {
+				appPaths[i] = C.GoString(cAppPathsSlice[i])
+			}
+		}
+		C.FreeCStringArray(cAppPaths, count)
 	}
 
-	// Convert C array to Go slice
-	utis := make([]string, int(count))
-	cUTIsSlice := (*[1 << 28]*C.char)(unsafe.Pointer(cUTIs))[:count:count]
-
-	for i := 0; i < int(count); i++ {
-		utis[i] = C.GoString(cUTIsSlice[i])
+	// Each UTI goes through the same fallback and validation as GetDefaultAppForUTI
+	for i, uti := range utis {
+		if appPath, err := h.resolveDefaultAppForUTI(strings.TrimSpace(uti), appPaths[i], nil); err == nil {
+			defaults[uti] = appPath
+		}
 	}
 
-	C.FreeCStringArray(cUTIs, count)
-
-	return utis, nil
+	return defaults, nil
 }
 
-// ResolveExtensionsForUTI returns all file extensions associated with a UTI
-//
-// Parameters:
-//   - uti: The UTI string (e.g., "public.plain-text", "public.html")
-//
-// Returns:
-//   - extensions: Slice of file extensions (without dots)
-//   - error: Error if any
-func ResolveExtensionsForUTI(uti string) ([]string, error) {
+// GetDefaultAppInfoForUTI returns the default application for a UTI with its name and bundle ID
+func (h *systemHandler) GetDefaultAppInfoForUTI(uti string) (AppInfo, error) {
+	uti = strings.TrimSpace(uti)
 	if uti == "" {
-		return nil, ErrInvalidParameters
+		return AppInfo{}, ErrInvalidParameters
 	}
 
 	cUTI := C.CString(uti)
 	defer C.free(unsafe.Pointer(cUTI))
 
-	var cExtensions **C.char
-	var count C.int
+	var cApp *C.AppInfo
 	var cError *C.char
 
-	code := C.GetExtensionsForUTI(cUTI, &cExtensions, &count, &cError)
+	trace := startCall("GetDefaultAppInfoForUTI", "uti", uti)
+	code := C.GetDefaultAppInfoForUTI(cUTI, &cApp, &cError)
+	trace.end(code, cError)
 
+	var app AppInfo
+	var lookupErr error
 	if code != C.BRIDGE_OK {
-		return nil, cErrorToGoError(code, cError)
+		lookupErr = cErrorToGoError(code, cError)
+	} else if cApp != nil {
+		app = cAppInfoToGo(cApp)
+		C.FreeAppInfo(cApp)
 	}
 
-	if count == 0 {
-		return []string{}, nil
+	// Decide exactly as GetDefaultAppForUTI does, including the scheme fallback
+	appPath, err := h.resolveDefaultAppForUTI(uti, app.Path, lookupErr)
+	if err != nil {
+		return AppInfo{}, err
+	}
+	if app.Path == "" {
+		return getAppInfoForPath(appPath)
 	}
 
-	// Convert C array to Go slice
-	extensions := make([]string, int(count))
-	cExtensionsSlice := (*[1 << 28]*C.char)(unsafe.Pointer(cExtensions))[:count:count]
+	return app, nil
+}
 
-	for i := 0; i < int(count); i++ {
-		extensions[i] = C.GoString(cExtensionsSlice[i])
+// validateDefaultHandler guards against stale LaunchServices entries that point at something other than an app
+func (h *systemHandler) validateDefaultHandler(appPath string) (string, error) {
+	if err := h.ValidateAppBundle(appPath); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrDefaultHandlerInvalid, appPath, err)
 	}
 
-	C.FreeCStringArray(cExtensions, count)
-
-	return extensions, nil
+	return appPath, nil
 }
 
-// ListAppsForUTI returns all applications that can open a UTI
-//
-// Parameters:
-//   - uti: The Uniform Type Identifier
-//
-// Returns:
-//   - appPaths: Slice of application bundle paths
-//   - error: Error if any
-func ListAppsForUTI(uti string) ([]string, error) {
-	if uti == "" {
-		return nil, ErrInvalidParameters
+// GetDefaultAppForScheme returns the default application path for a URL scheme
+func (h *systemHandler) GetDefaultAppForScheme(scheme string) (string, error) {
+	scheme = strings.TrimSpace(scheme)
+	if scheme == "" {
+		return "", ErrInvalidParameters
 	}
 
-	cUTI := C.CString(uti)
-	defer C.free(unsafe.Pointer(cUTI))
+	cScheme := C.CString(scheme)
+	defer C.free(unsafe.Pointer(cScheme))
 
-	var cAppPaths **C.char
-	var count C.int
+	var cAppPath *C.char
 	var cError *C.char
 
-	code := C.ListAppsForUTI(cUTI, &cAppPaths, &count, &cError)
+	trace := startCall("GetDefaultAppForScheme", "scheme", scheme)
+	code := C.GetDefaultAppForScheme(cScheme, &cAppPath, &cError)
+	trace.end(code, cError)
 
 	if code != C.BRIDGE_OK {
-		return nil, cErrorToGoError(code, cError)
-	}
-
-	if count == 0 {
-		return []string{}, nil
+		return "", cErrorToGoError(code, cError)
 	}
 
-	// Convert C array to Go slice
-	appPaths := make([]string, int(count))
-	cAppPathsSlice := (*[1 << 28]*C.char)(unsafe.Pointer(cAppPaths))[:count:count]
-
-	for i := 0; i < int(count); i++ {
-		appPaths[i] = C.GoString(cAppPathsSlice[i])
+	if cAppPath == nil {
+		return "", &BridgeError{
+			Code:    int(ErrNotFound),
+			Message: fmt.Sprintf("no default app found for scheme: %s", scheme),
+		}
 	}
 
-	C.FreeCStringArray(cAppPaths, count)
+	appPath := C.GoString(cAppPath)
+	C.FreeCString(cAppPath)
 
-	return appPaths, nil
+	return appPath, nil
 }
 
-// ListAppsForScheme returns all applications that can handle a URL scheme
-//
-// Parameters:
-//   - scheme: The URL scheme
-//
-// Returns:
-//   - appPaths: Slice of application bundle paths
-//   - error: Error if any
-func ListAppsForScheme(scheme string) ([]string, error) {
+// GetDefaultAppInfoForScheme returns the default application for a URL scheme with its name and bundle ID
+func (h *systemHandler) GetDefaultAppInfoForScheme(scheme string) (AppInfo, error) {
+	scheme = strings.TrimSpace(scheme)
 	if scheme == "" {
-		return nil, ErrInvalidParameters
+		return AppInfo{}, ErrInvalidParameters
 	}
 
 	cScheme := C.CString(scheme)
 	defer C.free(unsafe.Pointer(cScheme))
 
-	var cAppPaths **C.char
-	var count C.int
+	var cApp *C.AppInfo
 	var cError *C.char
 
-	code := C.ListAppsForScheme(cScheme, &cAppPaths, &count, &cError)
+	trace := startCall("GetDefaultAppInfoForScheme", "scheme", scheme)
+	code := C.GetDefaultAppInfoForScheme(cScheme, &cApp, &cError)
+	trace.end(code, cError)
 
 	if code != C.BRIDGE_OK {
-		return nil, cErrorToGoError(code, cError)
-	}
-
-	if count == 0 {
-		return []string{}, nil
+		return AppInfo{}, cErrorToGoError(code, cError)
 	}
 
-	// Convert C array to Go slice
-	appPaths := make([]string, int(count))
-	cAppPathsSlice := (*[1 << 28]*C.char)(unsafe.Pointer(cAppPaths))[:count:count]
-
-	for i := 0; i < int(count); i++ {
-		appPaths[i] = C.GoString(cAppPathsSlice[i])
+	if cApp == nil {
+		return AppInfo{}, &BridgeError{
+			Code:    int(ErrNotFound),
+			Message: fmt.Sprintf("no default app found for scheme: %s", scheme),
+		}
 	}
 
-	C.FreeCStringArray(cAppPaths, count)
-
-	return appPaths, nil
-}
+	app := cAppInfoToGo(cApp)
+	C.FreeAppInfo(cApp)
 
-// AppInfo represents an installed application with its metadata
-type AppInfo struct {
-	Name     string // Application display name
-	Path     string // Full path to application bundle
-	BundleID string // Bundle identifier (e.g., "com.apple.Safari")
+	return app, nil
 }
 
-// DocumentType represents a document type that an application can handle
-type DocumentType struct {
-	TypeName    string   // Human-readable name (e.g., "JPEG Image", "PDF Document")
-	Role        string   // Role: "Editor", "Viewer", "Shell", "None"
-	HandlerRank string   // Handler rank: "Owner", "Default", "Alternate", "None", or empty if not specified
-	UTIs        []string // Array of UTI identifiers
-	Extensions  []string // Array of file extensions
-	IsPackage   bool     // true if this is a package/bundle type
+// GetDefaultAppForURLContentType returns the default application for copied URLs (public.url)
+func (h *systemHandler) GetDefaultAppForURLContentType() (string, error) {
+	return h.GetDefaultAppForUTI("public.url")
 }
 
-// ListAllApplications returns all installed applications on the system
-//
-// Returns:
-//   - apps: Slice of AppInfo structures containing app metadata
-//   - error: Error if any
-func ListAllApplications() ([]AppInfo, error) {
-	var cApps **C.AppInfo
-	var count C.int
-	var cError *C.char
-
-	code := C.ListAllApplications(&cApps, &count, &cError)
-
-	if code != C.BRIDGE_OK {
-		return nil, cErrorToGoError(code, cError)
+// GetDefaultAppForExtension returns the default application path for a file extension
+func (h *systemHandler) GetDefaultAppForExtension(extension string) (string, error) {
+	extension = normalizeExtension(extension)
+	if extension == "" {
+		return "", ErrInvalidParameters
 	}
 
-	if count == 0 {
-		return []AppInfo{}, nil
+	preferred, _, err := preferredUTIForExtension(extension)
+	if err != nil {
+		return "", err
 	}
 
-	// Convert C array to Go slice
-	apps := make([]AppInfo, int(count))
-	cAppsSlice := (*[1 << 28]*C.AppInfo)(unsafe.Pointer(cApps))[:count:count]
+	utis, err := resolveUTIsForExtension(extension)
+	if err != nil {
+		return "", err
+	}
 
-	for i := 0; i < int(count); i++ {
-		cAppInfo := cAppsSlice[i]
-		apps[i] = AppInfo{
-			Name:     C.GoString(cAppInfo.name),
-			Path:     C.GoString(cAppInfo.path),
-			BundleID: C.GoString(cAppInfo.bundleID),
+	for _, uti := range uniqueInOrder(append([]string{preferred}, utis...)) {
+		appPath, err := h.GetDefaultAppForUTI(uti)
+		if err == nil {
+			return appPath, nil
+		}
+		if !hasErrorCode(err, ErrNotFound) && !hasErrorCode(err, ErrInvalidUTI) {
+			return "", err
 		}
 	}
 
-	C.FreeAppInfoArray(cApps, count)
-
-	return apps, nil
+	return "", &BridgeError{
+		Code:    int(ErrNotFound),
+		Message: fmt.Sprintf("no default app found for extension: %s", extension),
+	}
 }
 
-// getExtensionsForUTIs returns all file extensions for the given UTIs
-func getExtensionsForUTIs(utis []string) []string {
-	extensionsSet := make(map[string]bool)
+// GetDefaultAppForFile returns the application that opens a file on this machine
+func (h *systemHandler) GetDefaultAppForFile(filePath string) (AppInfo, error) {
+	uti, err := h.ResolveUTIForFile(filePath)
+	if err != nil {
+		return AppInfo{}, err
+	}
 
-	for _, uti := range utis {
-		extensions, err := ResolveExtensionsForUTI(uti)
-		if err != nil {
-			continue
-		}
-		for _, ext := range extensions {
-			extensionsSet[ext] = true
+	app, err := h.GetDefaultAppInfoForUTI(uti)
+	if hasErrorCode(err, ErrNotFound) {
+		return AppInfo{}, &BridgeError{
+			Code:    int(ErrNotFound),
+			Message: fmt.Sprintf("no default app found for file: %s (%s)", filePath, uti),
 		}
 	}
-
-	// Convert set to sorted slice
-	var result []string
-	for ext := range extensionsSet {
-		result = append(result, ext)
+	if err != nil {
+		return AppInfo{}, err
 	}
-	sort.Strings(result)
 
-	return result
+	return app, nil
+}
+
+// IsDefaultAppForUTI reports whether an application is the current default handler for a UTI
+func (h *systemHandler) IsDefaultAppForUTI(appPath, uti string) (bool, error) {
+	uti = strings.TrimSpace(uti)
+	if appPath == "" || uti == "" {
+		return false, ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	defaultAppPath, err := h.GetDefaultAppForUTI(uti)
+	if err != nil {
+		if hasErrorCode(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return appPathsEqual(appPath, defaultAppPath), nil
+}
+
+// SetDefaultForUTI sets the default application for a UTI
+func (h *systemHandler) SetDefaultForUTI(appPath, uti string) error {
+	uti = strings.TrimSpace(uti)
+	if appPath == "" || uti == "" {
+		return ErrInvalidParameters
+	}
+
+	if err := h.preflightSetDefaultForUTI(appPath, uti, false); err != nil {
+		return err
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	return setDefaultForUTI(appPath, uti)
+}
+
+// SetDefaultForUTIForce sets the default application for a UTI even if the app does not declare it
+func (h *systemHandler) SetDefaultForUTIForce(appPath, uti string) error {
+	uti = strings.TrimSpace(uti)
+	if appPath == "" || uti == "" {
+		return ErrInvalidParameters
+	}
+
+	if err := h.preflightSetDefaultForUTI(appPath, uti, true); err != nil {
+		return err
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	return setDefaultForUTI(appPath, uti)
+}
+
+// SetDefaultForUTIVerified sets the default application for a UTI only if the app's code signature is valid
+func (h *systemHandler) SetDefaultForUTIVerified(appPath, uti string) error {
+	uti = strings.TrimSpace(uti)
+	if appPath == "" || uti == "" {
+		return ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	if err := checkCodeSignature(appPath); err != nil {
+		return err
+	}
+
+	return h.SetDefaultForUTI(appPath, uti)
+}
+
+// SetDefaultForUTIConfirmed sets the default application for a UTI and checks that the change took effect
+func (h *systemHandler) SetDefaultForUTIConfirmed(appPath, uti string) error {
+	uti = strings.TrimSpace(uti)
+	if appPath == "" || uti == "" {
+		return ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	return confirmDefault(appPath,
+		func() error { return h.SetDefaultForUTI(appPath, uti) },
+		func() (string, error) { return h.GetDefaultAppForUTI(uti) })
+}
+
+// checkCodeSignature returns an error wrapping ErrCodeSignatureInvalid if the app's signature does not validate
+func checkCodeSignature(appPath string) error {
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	var isValid C.int
+	var status C.int
+	var cError *C.char
+
+	trace := startCall("CheckCodeSignature", "app", appPath)
+	code := C.CheckCodeSignature(cAppPath, &isValid, &status, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return cStatusErrorToGoError(code, status, cError)
+	}
+
+	if isValid == 0 {
+		reason := C.GoString(cError)
+		C.FreeCString(cError)
+		return fmt.Errorf("%w: %s: %s", ErrCodeSignatureInvalid, appPath, reason)
+	}
+
+	return nil
+}
+
+// preflightSetDefaultForUTI runs the checks every UTI setter makes before changing a default
+//
+// Unregistered UTIs are always rejected. Unless force is set, so is an app
+// that does not declare the UTI; an app that can't be inspected is left for
+// the setter to reject.
+func (h *systemHandler) preflightSetDefaultForUTI(appPath, uti string, force bool) error {
+	if err := h.requireRegisteredUTI(uti); err != nil {
+		return err
+	}
+
+	if force {
+		return nil
+	}
+
+	if supported, err := h.AppSupportsUTI(appPath, uti); err == nil && !supported {
+		return fmt.Errorf("%w: %s does not declare %s", ErrAppDoesNotSupportUTI, appPath, uti)
+	}
+
+	return nil
+}
+
+// requireRegisteredUTI returns an ErrInvalidUTI BridgeError if the system does not know uti
+//
+// Errors from the lookup itself are ignored so the setter can report them.
+func (h *systemHandler) requireRegisteredUTI(uti string) error {
+	if registered, err := h.IsRegisteredUTI(uti); err == nil && !registered {
+		return &BridgeError{
+			Code:    int(ErrInvalidUTI),
+			Message: fmt.Sprintf("UTI is not registered with the system: %s", uti),
+		}
+	}
+
+	return nil
+}
+
+// setDefaultForUTI implements SetDefaultForUTI; the caller must hold writeMu
+func setDefaultForUTI(appPath, uti string) error {
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var status C.int
+	var cError *C.char
+
+	trace := startCall("SetDefaultForUTI", "app", appPath, "uti", uti)
+	code := C.SetDefaultForUTI(cAppPath, cUTI, &status, &cError)
+	trace.end(code, cError)
+
+	return cStatusErrorToGoError(code, status, cError)
+}
+
+// SetDefaultForUTIWithRole sets the default application for a UTI in a single role
+func (h *systemHandler) SetDefaultForUTIWithRole(appPath, uti string, role Role) error {
+	uti = strings.TrimSpace(uti)
+	mask, ok := roleMask(role)
+	if appPath == "" || uti == "" || !ok || role == RoleNone {
+		return ErrInvalidParameters
+	}
+
+	if role == RoleAll {
+		return h.SetDefaultForUTI(appPath, uti)
+	}
+
+	if err := h.preflightSetDefaultForUTI(appPath, uti, false); err != nil {
+		return err
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var status C.int
+	var cError *C.char
+
+	trace := startCall("SetDefaultForUTIWithRole", "app", appPath, "uti", uti, "role", role)
+	code := C.SetDefaultForUTIWithRole(cAppPath, cUTI, mask, &status, &cError)
+	trace.end(code, cError)
+
+	return cStatusErrorToGoError(code, status, cError)
+}
+
+// defaultAppForUTIWithRole returns the handler LaunchServices has recorded for a single role of a UTI
+func defaultAppForUTIWithRole(uti string, role Role) (string, error) {
+	mask, ok := roleMask(role)
+	if uti == "" || !ok {
+		return "", ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cAppPath *C.char
+	var cError *C.char
+
+	trace := startCall("GetDefaultAppForUTIWithRole", "uti", uti, "role", role)
+	code := C.GetDefaultAppForUTIWithRole(cUTI, mask, &cAppPath, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return "", cErrorToGoError(code, cError)
+	}
+
+	appPath := C.GoString(cAppPath)
+	C.FreeCString(cAppPath)
+
+	return appPath, nil
+}
+
+// SetDefaultForUTIReturningPrevious sets the default application for a UTI and returns the one it replaced
+func (h *systemHandler) SetDefaultForUTIReturningPrevious(appPath, uti string) (string, error) {
+	uti = strings.TrimSpace(uti)
+	if appPath == "" || uti == "" {
+		return "", ErrInvalidParameters
+	}
+
+	if err := h.preflightSetDefaultForUTI(appPath, uti, false); err != nil {
+		return "", err
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	previousAppPath, err := h.GetDefaultAppForUTI(uti)
+	if err != nil {
+		if !hasErrorCode(err, ErrNotFound) {
+			return "", err
+		}
+		previousAppPath = ""
+	}
+
+	if err := setDefaultForUTI(appPath, uti); err != nil {
+		return "", err
+	}
+
+	return previousAppPath, nil
+}
+
+// SetDefaultForExtension sets the default application for a file extension
+func (h *systemHandler) SetDefaultForExtension(appPath, extension string) error {
+	extension = normalizeExtension(extension)
+	if appPath == "" || extension == "" {
+		return ErrInvalidParameters
+	}
+
+	uti, err := declaredUTIForExtension(extension)
+	if err != nil {
+		return err
+	}
+
+	return h.SetDefaultForUTI(appPath, uti)
+}
+
+// ResetDefaultForUTI clears the user's default application override for a UTI
+func (h *systemHandler) ResetDefaultForUTI(uti string) error {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return ErrInvalidParameters
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cError *C.char
+
+	trace := startCall("ResetDefaultForUTI", "uti", uti)
+	code := C.ResetDefaultForUTI(cUTI, &cError)
+	trace.end(code, cError)
+
+	return cErrorToGoError(code, cError)
+}
+
+// SetDefaultForScheme sets the default application for a URL scheme
+func (h *systemHandler) SetDefaultForScheme(appPath, scheme string) error {
+	scheme = strings.TrimSpace(scheme)
+	if appPath == "" || scheme == "" {
+		return ErrInvalidParameters
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	cScheme := C.CString(scheme)
+	defer C.free(unsafe.Pointer(cScheme))
+
+	var status C.int
+	var cError *C.char
+
+	trace := startCall("SetDefaultForScheme", "app", appPath, "scheme", scheme)
+	code := C.SetDefaultForScheme(cAppPath, cScheme, &status, &cError)
+	trace.end(code, cError)
+
+	return cStatusErrorToGoError(code, status, cError)
+}
+
+// SetDefaultForSchemeVerified sets the default application for a URL scheme and checks that the change took effect
+func (h *systemHandler) SetDefaultForSchemeVerified(appPath, scheme string) error {
+	scheme = strings.TrimSpace(scheme)
+	if appPath == "" || scheme == "" {
+		return ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	return confirmDefault(appPath,
+		func() error { return h.SetDefaultForScheme(appPath, scheme) },
+		func() (string, error) { return h.GetDefaultAppForScheme(scheme) })
+}
+
+// ResetDefaultForScheme clears the user's default application override for a URL scheme
+func (h *systemHandler) ResetDefaultForScheme(scheme string) error {
+	scheme = strings.TrimSpace(scheme)
+	if scheme == "" {
+		return ErrInvalidParameters
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	cScheme := C.CString(scheme)
+	defer C.free(unsafe.Pointer(cScheme))
+
+	var cError *C.char
+
+	trace := startCall("ResetDefaultForScheme", "scheme", scheme)
+	code := C.ResetDefaultForScheme(cScheme, &cError)
+	trace.end(code, cError)
+
+	return cErrorToGoError(code, cError)
+}
+
+// appPathForBundleID resolves a bundle identifier to the path of the installed application
+func (h *systemHandler) appPathForBundleID(bundleID string) (string, error) {
+	app, err := h.FindAppByBundleID(bundleID)
+	if err != nil {
+		return "", err
+	}
+	return app.Path, nil
+}
+
+// SetDefaultForUTIByBundleID sets the default application for a UTI, identifying the app by bundle ID
+func (h *systemHandler) SetDefaultForUTIByBundleID(bundleID, uti string) error {
+	uti = strings.TrimSpace(uti)
+	if bundleID == "" || uti == "" {
+		return ErrInvalidParameters
+	}
+
+	appPath, err := h.appPathForBundleID(bundleID)
+	if err != nil {
+		return err
+	}
+
+	return h.SetDefaultForUTI(appPath, uti)
+}
+
+// SetDefaultForExtensionByBundleID sets the default application for a file extension, identifying the app by bundle ID
+func (h *systemHandler) SetDefaultForExtensionByBundleID(bundleID, extension string) error {
+	if bundleID == "" || normalizeExtension(extension) == "" {
+		return ErrInvalidParameters
+	}
+
+	appPath, err := h.appPathForBundleID(bundleID)
+	if err != nil {
+		return err
+	}
+
+	return h.SetDefaultForExtension(appPath, extension)
+}
+
+// SetDefaultForSchemeByBundleID sets the default application for a URL scheme, identifying the app by bundle ID
+func (h *systemHandler) SetDefaultForSchemeByBundleID(bundleID, scheme string) error {
+	scheme = strings.TrimSpace(scheme)
+	if bundleID == "" || scheme == "" {
+		return ErrInvalidParameters
+	}
+
+	appPath, err := h.appPathForBundleID(bundleID)
+	if err != nil {
+		return err
+	}
+
+	return h.SetDefaultForScheme(appPath, scheme)
+}
+
+// Identifiers the default app helpers read and write
+var (
+	browserSchemes    = []string{"http", "https"}
+	browserUTIs       = []string{"public.html"}
+	mailClientSchemes = []string{"mailto"}
+	textEditorUTIs    = []string{"public.plain-text", "public.text"}
+)
+
+// GetDefaultBrowser returns the default web browser
+func (h *systemHandler) GetDefaultBrowser() (AppInfo, error) {
+	return h.GetDefaultAppInfoForScheme("https")
+}
+
+// SetDefaultBrowser makes an application the default web browser
+func (h *systemHandler) SetDefaultBrowser(appPath string) error {
+	if appPath == "" {
+		return ErrInvalidParameters
+	}
+
+	return setDefaults(appPath, browserUTIs, browserSchemes, h.SetDefaultForUTI, h.SetDefaultForScheme)
+}
+
+// GetDefaultMailClient returns the default mail client
+func (h *systemHandler) GetDefaultMailClient() (AppInfo, error) {
+	return h.GetDefaultAppInfoForScheme("mailto")
+}
+
+// SetDefaultMailClient makes an application the default mail client
+func (h *systemHandler) SetDefaultMailClient(appPath string) error {
+	if appPath == "" {
+		return ErrInvalidParameters
+	}
+
+	return setDefaults(appPath, nil, mailClientSchemes, h.SetDefaultForUTI, h.SetDefaultForScheme)
+}
+
+// GetDefaultTextEditor returns the default text editor
+func (h *systemHandler) GetDefaultTextEditor() (AppInfo, error) {
+	return h.GetDefaultAppInfoForUTI("public.plain-text")
+}
+
+// SetDefaultTextEditor makes an application the default text editor
+func (h *systemHandler) SetDefaultTextEditor(appPath string) error {
+	if appPath == "" {
+		return ErrInvalidParameters
+	}
+
+	return setDefaults(appPath, textEditorUTIs, nil, h.SetDefaultForUTI, h.SetDefaultForScheme)
+}
+
+// GetDefaultTerminal returns the default terminal application
+func (h *systemHandler) GetDefaultTerminal() (AppInfo, error) {
+	return h.GetDefaultAppInfoForUTI("public.unix-executable")
+}
+
+// OpenFile opens a file with its default application
+func (h *systemHandler) OpenFile(filePath string) error {
+	if filePath == "" {
+		return ErrInvalidParameters
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return ErrInvalidParameters
+	}
+
+	cFilePath := C.CString(filePath)
+	defer C.free(unsafe.Pointer(cFilePath))
+
+	var status C.int
+	var cError *C.char
+
+	trace := startCall("OpenFile", "file", filePath)
+	code := C.OpenFile(cFilePath, &status, &cError)
+	trace.end(code, cError)
+
+	return cStatusErrorToGoError(code, status, cError)
+}
+
+// OpenFileWithApp opens a file with a specific application, regardless of the default
+func (h *systemHandler) OpenFileWithApp(filePath, appPath string) error {
+	if filePath == "" || appPath == "" {
+		return ErrInvalidParameters
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	cFilePath := C.CString(filePath)
+	defer C.free(unsafe.Pointer(cFilePath))
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	var status C.int
+	var cError *C.char
+
+	trace := startCall("OpenFileWithApp", "file", filePath, "app", appPath)
+	code := C.OpenFileWithApp(cFilePath, cAppPath, &status, &cError)
+	trace.end(code, cError)
+
+	return cStatusErrorToGoError(code, status, cError)
+}
+
+// OpenFilesWithApp opens several files with a specific application in a single launch
+func (h *systemHandler) OpenFilesWithApp(appPath string, filePaths []string) error {
+	if appPath == "" || len(filePaths) == 0 {
+		return ErrInvalidParameters
+	}
+	for _, filePath := range filePaths {
+		if filePath == "" {
+			return ErrInvalidParameters
+		}
+		if _, err := os.Stat(filePath); err != nil {
+			return ErrInvalidParameters
+		}
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	cFilePaths := C.malloc(C.size_t(len(filePaths)) * C.size_t(unsafe.Sizeof((*C.char)(nil))))
+	defer C.free(cFilePaths)
+
+	cFilePathsSlice := unsafe.Slice((**C.char)(cFilePaths), len(filePaths))
+	for i, filePath := range filePaths {
+		cFilePathsSlice[i] = C.CString(filePath)
+	}
+	defer func() {
+		for _, cFilePath := range cFilePathsSlice {
+			C.free(unsafe.Pointer(cFilePath))
+		}
+	}()
+
+	var status C.int
+	var cError *C.char
+
+	trace := startCall("OpenFilesWithApp", "app", appPath, "files", filePaths)
+	code := C.OpenFilesWithApp(cAppPath, (**C.char)(cFilePaths), C.int(len(filePaths)), &status, &cError)
+	trace.end(code, cError)
+
+	return cStatusErrorToGoError(code, status, cError)
+}
+
+// ResolveUTIsForExtension resolves a file extension to one or more UTIs
+func (h *systemHandler) ResolveUTIsForExtension(extension string) ([]string, error) {
+	utis, err := resolveUTIsForExtension(extension)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedUnique(utis), nil
+}
+
+// resolveUTIsForExtension resolves a file extension to its UTIs in the order the type system returns them
+func resolveUTIsForExtension(extension string) ([]string, error) {
+	extension = normalizeExtension(extension)
+	if extension == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	cExt := C.CString(extension)
+	defer C.free(unsafe.Pointer(cExt))
+
+	var cUTIs **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ResolveUTIsForExtension", "extension", extension)
+	code := C.ResolveUTIsForExtension(cExt, &cUTIs, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	if count == 0 {
+		return []string{}, nil
+	}
+
+	// Convert C array to Go slice
+	utis := make([]string, int(count))
+	cUTIsSlice := unsafe.Slice(cUTIs, int(count))
+
+	for i := 0; i < int(count); i++ {
+		utis[i] = C.GoString(cUTIsSlice[i])
+	}
+
+	C.FreeCStringArray(cUTIs, count)
+
+	return uniqueInOrder(utis), nil
+}
+
+// ResolveUTIsForMIMEType returns the UTIs registered for a MIME type
+func (h *systemHandler) ResolveUTIsForMIMEType(mimeType string) ([]string, error) {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	cMIMEType := C.CString(mediaType)
+	defer C.free(unsafe.Pointer(cMIMEType))
+
+	var cUTIs **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ResolveUTIsForMIMEType", "mime_type", mimeType)
+	code := C.ResolveUTIsForMIMEType(cMIMEType, &cUTIs, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return cStringArrayToSlice(cUTIs, count), nil
+}
+
+// ResolveUTIForFile returns the UTI of a file on disk
+func (h *systemHandler) ResolveUTIForFile(filePath string) (string, error) {
+	if filePath == "" {
+		return "", ErrInvalidParameters
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return "", ErrInvalidParameters
+	}
+
+	cFilePath := C.CString(filePath)
+	defer C.free(unsafe.Pointer(cFilePath))
+
+	var cUTI *C.char
+	var cError *C.char
+
+	trace := startCall("ResolveUTIForFile", "file", filePath)
+	code := C.ResolveUTIForFile(cFilePath, &cUTI, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return "", cErrorToGoError(code, cError)
+	}
+
+	uti := C.GoString(cUTI)
+	C.FreeCString(cUTI)
+
+	return uti, nil
+}
+
+// ResolveExtensionsForUTI returns all file extensions associated with a UTI
+func (h *systemHandler) ResolveExtensionsForUTI(uti string) ([]string, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cExtensions **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("GetExtensionsForUTI", "uti", uti)
+	code := C.GetExtensionsForUTI(cUTI, &cExtensions, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	if count == 0 {
+		return []string{}, nil
+	}
+
+	// Convert C array to Go slice
+	extensions := make([]string, int(count))
+	cExtensionsSlice := unsafe.Slice(cExtensions, int(count))
+
+	for i := 0; i < int(count); i++ {
+		extensions[i] = C.GoString(cExtensionsSlice[i])
+	}
+
+	C.FreeCStringArray(cExtensions, count)
+
+	return sortedUnique(extensions), nil
+}
+
+// ResolveExtensionsForUTIs returns the file extensions of several UTIs in a single call
+func (h *systemHandler) ResolveExtensionsForUTIs(utis []string) (map[string][]string, error) {
+	extensions := make(map[string][]string)
+	if len(utis) == 0 {
+		return extensions, nil
+	}
+
+	cUTIs := C.malloc(C.size_t(len(utis)) * C.size_t(unsafe.Sizeof((*C.char)(nil))))
+	defer C.free(cUTIs)
+
+	cUTIsSlice := unsafe.Slice((**C.char)(cUTIs), len(utis))
+	for i, uti := range utis {
+		cUTIsSlice[i] = C.CString(strings.TrimSpace(uti))
+	}
+	defer func() {
+		for _, cUTI := range cUTIsSlice {
+			C.free(unsafe.Pointer(cUTI))
+		}
+	}()
+
+	var cExtensions **C.char
+	var cCounts *C.int
+	var total C.int
+	var cError *C.char
+
+	trace := startCall("GetExtensionsForUTIs", "utis", utis)
+	code := C.GetExtensionsForUTIs((**C.char)(cUTIs), C.int(len(utis)), &cExtensions, &cCounts, &total, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	groupCStringArray(utis, cExtensions, cCounts, total, extensions)
+
+	return extensions, nil
+}
+
+// PreferredExtensionForUTI returns the canonical file extension for a UTI
+func (h *systemHandler) PreferredExtensionForUTI(uti string) (string, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return "", ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cExtension *C.char
+	var cError *C.char
+
+	trace := startCall("GetPreferredExtensionForUTI", "uti", uti)
+	code := C.GetPreferredExtensionForUTI(cUTI, &cExtension, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return "", cErrorToGoError(code, cError)
+	}
+
+	extension := C.GoString(cExtension)
+	C.FreeCString(cExtension)
+
+	return extension, nil
+}
+
+// GetTagSpecification returns every filename extension, MIME type, pasteboard type and OSType of a UTI
+func (h *systemHandler) GetTagSpecification(uti string) (TagSpec, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return TagSpec{}, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cSpec *C.TagSpecification
+	var cError *C.char
+
+	trace := startCall("GetTagSpecificationForUTI", "uti", uti)
+	code := C.GetTagSpecificationForUTI(cUTI, &cSpec, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return TagSpec{}, cErrorToGoError(code, cError)
+	}
+
+	// The arrays stay owned by cSpec, so copy them without freeing
+	copyStrings := func(cArr **C.char, count C.int) []string {
+		result := make([]string, int(count))
+		if count > 0 && cArr != nil {
+			for i, cStr := range unsafe.Slice(cArr, int(count)) {
+				result[i] = C.GoString(cStr)
+			}
+		}
+		return result
+	}
+
+	spec := TagSpec{
+		Extensions:      copyStrings(cSpec.extensions, cSpec.extensionCount),
+		MIMETypes:       copyStrings(cSpec.mimeTypes, cSpec.mimeTypeCount),
+		PasteboardTypes: copyStrings(cSpec.pasteboardTypes, cSpec.pasteboardTypeCount),
+		OSTypes:         copyStrings(cSpec.osTypes, cSpec.osTypeCount),
+	}
+
+	C.FreeTagSpecification(cSpec)
+
+	return spec, nil
+}
+
+// IsDynamicUTI reports whether a UTI is a dynamic (dyn.*) placeholder type
+func (h *systemHandler) IsDynamicUTI(uti string) bool {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return false
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var isDynamic C.int
+	var cError *C.char
+
+	trace := startCall("UTIIsDynamic", "uti", uti)
+	code := C.UTIIsDynamic(cUTI, &isDynamic, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		C.FreeCString(cError)
+		return strings.HasPrefix(uti, "dyn.")
+	}
+
+	return isDynamic != 0
+}
+
+// IsRegisteredUTI reports whether a UTI is a type declared by the system or an installed application
+func (h *systemHandler) IsRegisteredUTI(uti string) (bool, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return false, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var isDeclared C.int
+	var cError *C.char
+
+	trace := startCall("UTIIsDeclared", "uti", uti)
+	code := C.UTIIsDeclared(cUTI, &isDeclared, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return false, cErrorToGoError(code, cError)
+	}
+
+	return isDeclared != 0, nil
+}
+
+// ResolveUTIsForExtensionDeclaredOnly resolves a file extension to its UTIs, excluding dynamic types
+func (h *systemHandler) ResolveUTIsForExtensionDeclaredOnly(extension string) ([]string, error) {
+	utis, err := h.ResolveUTIsForExtension(extension)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := make([]string, 0, len(utis))
+	for _, uti := range utis {
+		if !h.IsDynamicUTI(uti) {
+			declared = append(declared, uti)
+		}
+	}
+
+	return declared, nil
+}
+
+// ResolveMIMETypesForUTI returns all MIME types associated with a UTI
+func (h *systemHandler) ResolveMIMETypesForUTI(uti string) ([]string, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cMIMETypes **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("GetMIMETypesForUTI", "uti", uti)
+	code := C.GetMIMETypesForUTI(cUTI, &cMIMETypes, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return cStringArrayToSlice(cMIMETypes, count), nil
+}
+
+// PreferredUTIForExtension resolves a file extension to the single UTI the system prefers for it
+func (h *systemHandler) PreferredUTIForExtension(extension string) (string, error) {
+	uti, _, err := preferredUTIForExtension(extension)
+	return uti, err
+}
+
+// preferredUTIForExtension resolves an extension to its preferred UTI and reports whether it is dynamic
+func preferredUTIForExtension(extension string) (string, bool, error) {
+	extension = normalizeExtension(extension)
+	if extension == "" {
+		return "", false, ErrInvalidParameters
+	}
+
+	cExt := C.CString(extension)
+	defer C.free(unsafe.Pointer(cExt))
+
+	var cUTI *C.char
+	var isDynamic C.int
+	var cError *C.char
+
+	trace := startCall("GetPreferredUTIForExtension", "extension", extension)
+	code := C.GetPreferredUTIForExtension(cExt, &cUTI, &isDynamic, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return "", false, cErrorToGoError(code, cError)
+	}
+
+	uti := C.GoString(cUTI)
+	C.FreeCString(cUTI)
+
+	return uti, isDynamic != 0, nil
+}
+
+// ExtensionsShareUTI reports whether two file extensions resolve to the same preferred UTI
+func (h *systemHandler) ExtensionsShareUTI(extA, extB string) (bool, error) {
+	if normalizeExtension(extA) == "" || normalizeExtension(extB) == "" {
+		return false, ErrInvalidParameters
+	}
+
+	utiA, err := declaredUTIForExtension(extA)
+	if err != nil {
+		return false, err
+	}
+
+	utiB, err := declaredUTIForExtension(extB)
+	if err != nil {
+		return false, err
+	}
+
+	return utiA == utiB, nil
+}
+
+// declaredUTIForExtension returns the preferred UTI for an extension, failing if only a dynamic UTI exists
+func declaredUTIForExtension(extension string) (string, error) {
+	uti, isDynamic, err := preferredUTIForExtension(extension)
+	if err != nil {
+		return "", err
+	}
+
+	if isDynamic {
+		return "", &BridgeError{
+			Code:    int(ErrNotFound),
+			Message: fmt.Sprintf("no declared UTI found for extension: %s", extension),
+		}
+	}
+
+	return uti, nil
+}
+
+// ConformsTo reports whether a UTI conforms to another UTI
+func (h *systemHandler) ConformsTo(uti, parentUTI string) (bool, error) {
+	uti = strings.TrimSpace(uti)
+	parentUTI = strings.TrimSpace(parentUTI)
+	if uti == "" || parentUTI == "" {
+		return false, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	cParent := C.CString(parentUTI)
+	defer C.free(unsafe.Pointer(cParent))
+
+	var conforms C.int
+	var cError *C.char
+
+	trace := startCall("UTIConformsTo", "uti", uti, "parent_uti", parentUTI)
+	code := C.UTIConformsTo(cUTI, cParent, &conforms, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return false, cErrorToGoError(code, cError)
+	}
+
+	return conforms != 0, nil
+}
+
+// GetConformingUTIs returns every UTI that a type conforms to
+func (h *systemHandler) GetConformingUTIs(uti string) ([]string, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cUTIs **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("GetSupertypesForUTI", "uti", uti)
+	code := C.GetSupertypesForUTI(cUTI, &cUTIs, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return cStringArrayToSlice(cUTIs, count), nil
+}
+
+// GetUTIDescription returns the human-readable description of a UTI
+func (h *systemHandler) GetUTIDescription(uti string) (string, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return "", ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cDescription *C.char
+	var cError *C.char
+
+	trace := startCall("GetUTIDescription", "uti", uti)
+	code := C.GetUTIDescription(cUTI, &cDescription, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return "", cErrorToGoError(code, cError)
+	}
+
+	description := C.GoString(cDescription)
+	C.FreeCString(cDescription)
+
+	return description, nil
+}
+
+// GetSchemeDescription returns a human-readable name for a URL scheme
+func (h *systemHandler) GetSchemeDescription(scheme string) (string, error) {
+	scheme = strings.TrimSpace(scheme)
+	if scheme == "" {
+		return "", ErrInvalidParameters
+	}
+
+	if description, ok := schemeDescription(scheme); ok {
+		return description, nil
+	}
+
+	return scheme, nil
+}
+
+// GetUTIIconPNG renders the generic document icon for a UTI as PNG data
+func (h *systemHandler) GetUTIIconPNG(uti string, size int) ([]byte, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return nil, ErrInvalidParameters
+	}
+	if size <= 0 {
+		size = defaultIconSize
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cData *C.uchar
+	var length C.int
+	var cError *C.char
+
+	trace := startCall("GetUTIIconPNG", "uti", uti, "size", size)
+	code := C.GetUTIIconPNG(cUTI, C.int(size), &cData, &length, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	png := C.GoBytes(unsafe.Pointer(cData), length)
+	C.free(unsafe.Pointer(cData))
+
+	return png, nil
+}
+
+// ListAppsForUTI returns all applications that can open a UTI
+func (h *systemHandler) ListAppsForUTI(uti string) ([]string, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cAppPaths **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ListAppsForUTI", "uti", uti)
+	code := C.ListAppsForUTI(cUTI, &cAppPaths, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	if count == 0 {
+		return []string{}, nil
+	}
+
+	// Convert C array to Go slice
+	appPaths := make([]string, int(count))
+	cAppPathsSlice := unsafe.Slice(cAppPaths, int(count))
+
+	for i := 0; i < int(count); i++ {
+		appPaths[i] = C.GoString(cAppPathsSlice[i])
+	}
+
+	C.FreeCStringArray(cAppPaths, count)
+
+	return sortedUnique(appPaths), nil
+}
+
+// ListAppInfosForUTI returns all applications that can open a UTI along with their names and bundle IDs
+func (h *systemHandler) ListAppInfosForUTI(uti string) ([]AppInfo, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cApps **C.AppInfo
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ListAppInfosForUTI", "uti", uti)
+	code := C.ListAppInfosForUTI(cUTI, &cApps, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return uniqueAppsInOrder(cAppInfoArrayToSlice(cApps, count)), nil
+}
+
+// ListAppsForUTIWithRoles returns the applications that can open a UTI with the rank and role each declares for it
+func (h *systemHandler) ListAppsForUTIWithRoles(uti string) ([]RankedApp, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	apps, err := h.ListAppInfosForUTI(uti)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultApp, err := h.GetDefaultAppForUTI(uti)
+	if err != nil && !hasErrorCode(err, ErrNotFound) && !errors.Is(err, ErrDefaultHandlerInvalid) {
+		return nil, err
+	}
+
+	conforms := func(uti, parentUTI string) bool {
+		ok, err := h.ConformsTo(uti, parentUTI)
+		return err == nil && ok
+	}
+
+	ranked := make([]RankedApp, 0, len(apps))
+	for _, app := range apps {
+		entry := RankedApp{
+			AppInfo:   app,
+			IsDefault: defaultApp != "" && appPathsEqual(app.Path, defaultApp),
+		}
+
+		// Apps whose Info.plist can't be read still show up, just without a rank
+		if docTypes, err := h.ListSupportedDocumentTypes(app.Path); err == nil {
+			entry.HandlerRank, entry.Role = declaredHandlerRole(docTypes, uti, conforms)
+		}
+
+		ranked = append(ranked, entry)
+	}
+
+	sortRankedApps(ranked)
+
+	return ranked, nil
+}
+
+// FindConflictingHandlers returns the apps that share the strongest handler rank claimed for a UTI
+func (h *systemHandler) FindConflictingHandlers(uti string) ([]AppInfo, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	ranked, err := h.ListAppsForUTIWithRoles(uti)
+	if err != nil {
+		return nil, err
+	}
+
+	return strongestClaimants(ranked), nil
+}
+
+// roleMask returns the bridge.h role mask for a Role
+func roleMask(role Role) (C.uint, bool) {
+	switch role {
+	case RoleViewer:
+		return C.BRIDGE_ROLE_VIEWER, true
+	case RoleEditor:
+		return C.BRIDGE_ROLE_EDITOR, true
+	case RoleShell:
+		return C.BRIDGE_ROLE_SHELL, true
+	case RoleAll:
+		return C.BRIDGE_ROLE_ALL, true
+	case RoleNone:
+		return C.BRIDGE_ROLE_NONE, true
+	default:
+		return 0, false
+	}
+}
+
+// ListAppsForUTIWithRole returns the applications that can open a UTI in a given role
+func (h *systemHandler) ListAppsForUTIWithRole(uti string, role Role) ([]string, error) {
+	uti = strings.TrimSpace(uti)
+	mask, ok := roleMask(role)
+	if uti == "" || !ok {
+		return nil, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cAppPaths **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ListAppsForUTIWithRole", "uti", uti, "role", role)
+	code := C.ListAppsForUTIWithRole(cUTI, mask, &cAppPaths, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return sortedUnique(cStringArrayToSlice(cAppPaths, count)), nil
+}
+
+// ListAppsForUTIContext is like ListAppsForUTI but returns ctx.Err() once ctx is done
+func (h *systemHandler) ListAppsForUTIContext(ctx context.Context, uti string) ([]string, error) {
+	return runWithContext(ctx, func() ([]string, error) {
+		return h.ListAppsForUTI(uti)
+	})
+}
+
+// ListAppsForUTIs returns the applications that can open each of several UTIs in a single call
+func (h *systemHandler) ListAppsForUTIs(utis []string) (map[string][]string, error) {
+	appPaths := make(map[string][]string)
+	if len(utis) == 0 {
+		return appPaths, nil
+	}
+
+	cUTIs := C.malloc(C.size_t(len(utis)) * C.size_t(unsafe.Sizeof((*C.char)(nil))))
+	defer C.free(cUTIs)
+
+	cUTIsSlice := unsafe.Slice((**C.char)(cUTIs), len(utis))
+	for i, uti := range utis {
+		cUTIsSlice[i] = C.CString(strings.TrimSpace(uti))
+	}
+	defer func() {
+		for _, cUTI := range cUTIsSlice {
+			C.free(unsafe.Pointer(cUTI))
+		}
+	}()
+
+	var cAppPaths **C.char
+	var cCounts *C.int
+	var total C.int
+	var cError *C.char
+
+	trace := startCall("ListAppsForUTIs", "utis", utis)
+	code := C.ListAppsForUTIs((**C.char)(cUTIs), C.int(len(utis)), &cAppPaths, &cCounts, &total, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	groupCStringArray(utis, cAppPaths, cCounts, total, appPaths)
+	for uti, paths := range appPaths {
+		appPaths[uti] = sortedUnique(paths)
+	}
+
+	return appPaths, nil
+}
+
+// AppsHandlingAllUTIs returns the applications that can open every one of several UTIs
+func (h *systemHandler) AppsHandlingAllUTIs(utis []string) ([]string, error) {
+	appPaths, err := h.ListAppsForUTIs(utis)
+	if err != nil {
+		return nil, err
+	}
+
+	return intersectGroups(appPaths, utis), nil
+}
+
+// AppsHandlingAnyUTI returns the applications that can open at least one of several UTIs
+func (h *systemHandler) AppsHandlingAnyUTI(utis []string) ([]string, error) {
+	appPaths, err := h.ListAppsForUTIs(utis)
+	if err != nil {
+		return nil, err
+	}
+
+	all := []string{}
+	for _, paths := range appPaths {
+		all = append(all, paths...)
+	}
+
+	return sortedUnique(all), nil
+}
+
+// ListAppsForScheme returns all applications that can handle a URL scheme
+func (h *systemHandler) ListAppsForScheme(scheme string) ([]string, error) {
+	scheme = strings.TrimSpace(scheme)
+	if scheme == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	cScheme := C.CString(scheme)
+	defer C.free(unsafe.Pointer(cScheme))
+
+	var cAppPaths **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ListAppsForScheme", "scheme", scheme)
+	code := C.ListAppsForScheme(cScheme, &cAppPaths, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	if count == 0 {
+		return []string{}, nil
+	}
+
+	// Convert C array to Go slice
+	appPaths := make([]string, int(count))
+	cAppPathsSlice := unsafe.Slice(cAppPaths, int(count))
+
+	for i := 0; i < int(count); i++ {
+		appPaths[i] = C.GoString(cAppPathsSlice[i])
+	}
+
+	C.FreeCStringArray(cAppPaths, count)
+
+	return sortedUnique(appPaths), nil
+}
+
+// ListAllApplications returns all installed applications on the system
+func (h *systemHandler) ListAllApplications() ([]AppInfo, error) {
+	var cApps **C.AppInfo
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ListAllApplications")
+	code := C.ListAllApplications(&cApps, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return cAppInfoArrayToSlice(cApps, count), nil
+}
+
+// ForEachApplication calls fn for every installed application
+func (h *systemHandler) ForEachApplication(fn func(app AppInfo) bool) error {
+	if fn == nil {
+		return ErrInvalidParameters
+	}
+
+	var cApps **C.AppInfo
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ListAllApplications")
+	code := C.ListAllApplications(&cApps, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return cErrorToGoError(code, cError)
+	}
+
+	if count == 0 || cApps == nil {
+		return nil
+	}
+	defer C.FreeAppInfoArray(cApps, count)
+
+	for _, cApp := range unsafe.Slice(cApps, int(count)) {
+		if !fn(cAppInfoToGo(cApp)) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// ListAllApplicationsDeduplicated is like ListAllApplications but returns each bundle ID only once
+func (h *systemHandler) ListAllApplicationsDeduplicated() ([]AppInfo, error) {
+	apps, err := h.ListAllApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	return dedupeApplications(apps), nil
+}
+
+// FindAppsByName returns the installed applications whose display name contains a query
+func (h *systemHandler) FindAppsByName(query string) ([]AppInfo, error) {
+	if query == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	apps, err := h.ListAllApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	return filterApplicationsByName(apps, query), nil
+}
+
+// ListApplicationsByCategory returns the installed applications in an App Store category
+func (h *systemHandler) ListApplicationsByCategory(category string) ([]AppInfo, error) {
+	if category == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	apps, err := h.ListAllApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	return filterApplicationsByCategory(apps, category), nil
+}
+
+// ListApplicationsInDirectory returns the applications directly inside a directory
+func (h *systemHandler) ListApplicationsInDirectory(dir string) ([]AppInfo, error) {
+	if dir == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return nil, ErrInvalidParameters
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	apps := []AppInfo{}
+	for _, entry := range entries {
+		if !strings.EqualFold(filepath.Ext(entry.Name()), ".app") {
+			continue
+		}
+
+		app, err := getAppInfoForPath(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			if hasErrorCode(err, ErrInvalidApp) {
+				continue
+			}
+			return nil, err
+		}
+		apps = append(apps, app)
+	}
+
+	return apps, nil
+}
+
+// applicationDirectories returns the standard locations RebuildLaunchServicesDatabase registers apps from
+func applicationDirectories() []string {
+	dirs := []string{"/Applications", "/System/Applications"}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "Applications"))
+	}
+	return dirs
+}
+
+// RebuildLaunchServicesDatabase registers every application in the standard directories with LaunchServices
+func (h *systemHandler) RebuildLaunchServicesDatabase() error {
+	var errs []error
+	for _, dir := range applicationDirectories() {
+		bundles, err := findAppBundles(dir)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", dir, err))
+			continue
+		}
+
+		for _, bundle := range bundles {
+			// Lock per app so other writes are not held up for the whole walk
+			writeMu.Lock()
+			err := registerApplication(bundle)
+			writeMu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", bundle, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// appChangePollInterval is how often WatchApplicationChanges rescans the application directories
+const appChangePollInterval = 2 * time.Second
+
+// listInstalledAppBundles returns the app bundles in the standard application directories
+func listInstalledAppBundles() ([]string, error) {
+	var bundles []string
+	for _, dir := range applicationDirectories() {
+		found, err := findAppBundles(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		bundles = append(bundles, found...)
+	}
+	return bundles, nil
+}
+
+// WatchApplicationChanges reports applications being installed or removed
+func (h *systemHandler) WatchApplicationChanges() (<-chan AppChangeEvent, func(), error) {
+	return watchPolled(listInstalledAppBundles, diffAppBundles, appChangePollInterval)
+}
+
+// RegisterApp registers a single application bundle with LaunchServices
+func (h *systemHandler) RegisterApp(appPath string) error {
+	if appPath == "" {
+		return ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	if err := h.ValidateAppBundle(appPath); err != nil {
+		return err
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	return registerApplication(appPath)
+}
+
+// registerApplication registers one application bundle with LaunchServices; the caller must hold writeMu
+func registerApplication(appPath string) error {
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	var status C.int
+	var cError *C.char
+
+	trace := startCall("RegisterApplication", "app", appPath)
+	code := C.RegisterApplication(cAppPath, &status, &cError)
+	trace.end(code, cError)
+
+	return cStatusErrorToGoError(code, status, cError)
+}
+
+// ListAllApplicationsContext is like ListAllApplications but returns ctx.Err() once ctx is done
+func (h *systemHandler) ListAllApplicationsContext(ctx context.Context) ([]AppInfo, error) {
+	return runWithContext(ctx, h.ListAllApplications)
+}
+
+// Helper function to convert a C AppInfo array to a Go slice, freeing the C array
+func cAppInfoArrayToSlice(cApps **C.AppInfo, count C.int) []AppInfo {
+	if count == 0 || cApps == nil {
+		return []AppInfo{}
+	}
+
+	apps := make([]AppInfo, int(count))
+	cAppsSlice := unsafe.Slice(cApps, int(count))
+
+	for i := 0; i < int(count); i++ {
+		apps[i] = cAppInfoToGo(cAppsSlice[i])
+	}
+
+	C.FreeAppInfoArray(cApps, count)
+
+	return apps
+}
+
+// Helper function to copy a C AppInfo structure into Go (the caller still owns the C memory)
+//
+// Any field may be NULL; those become empty strings, and a missing name falls
+// back to the bundle's file name.
+func cAppInfoToGo(cApp *C.AppInfo) AppInfo {
+	if cApp == nil {
+		return AppInfo{}
+	}
+
+	info := AppInfo{
+		Name:         cStringOrEmpty(cApp.name),
+		Path:         cStringOrEmpty(cApp.path),
+		BundleID:     cStringOrEmpty(cApp.bundleID),
+		Version:      cStringOrEmpty(cApp.version),
+		BuildVersion: cStringOrEmpty(cApp.buildVersion),
+		Category:     cStringOrEmpty(cApp.category),
+	}
+	if info.Name == "" {
+		info.Name = appNameFromPath(info.Path)
+	}
+
+	return info
+}
+
+// Helper function to copy a C string that may be NULL
+func cStringOrEmpty(cStr *C.char) string {
+	if cStr == nil {
+		return ""
+	}
+	return C.GoString(cStr)
+}
+
+// ListAllRegisteredUTIs returns every UTI registered by installed applications
+func (h *systemHandler) ListAllRegisteredUTIs() ([]string, error) {
+	utis := []string{}
+	err := h.ListAllRegisteredUTIsFunc(func(uti string) bool {
+		utis = append(utis, uti)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return utis, nil
+}
+
+// ListAllRegisteredUTIsFunc calls fn for every UTI registered by installed applications
+func (h *systemHandler) ListAllRegisteredUTIsFunc(fn func(uti string) bool) error {
+	if fn == nil {
+		return ErrInvalidParameters
+	}
+
+	var cUTIs **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ListAllRegisteredUTIs")
+	code := C.ListAllRegisteredUTIs(&cUTIs, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return cErrorToGoError(code, cError)
+	}
+
+	if count == 0 || cUTIs == nil {
+		return nil
+	}
+	defer C.FreeCStringArray(cUTIs, count)
+
+	cSlice := unsafe.Slice(cUTIs, int(count))
+	for i := 0; i < int(count); i++ {
+		if !fn(C.GoString(cSlice[i])) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// ListAllSchemes returns every URL scheme declared by an installed application
+func (h *systemHandler) ListAllSchemes() ([]string, error) {
+	apps, err := h.ListAllApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	schemes := []string{}
+	for _, app := range apps {
+		appSchemes, err := h.ListSupportedSchemes(app.Path)
+		if err != nil {
+			continue
+		}
+		schemes = append(schemes, appSchemes...)
+	}
+
+	return sortedUnique(schemes), nil
+}
+
+// ListAllDeclaredUTIs returns every UTI an installed application declares it can open
+func (h *systemHandler) ListAllDeclaredUTIs() ([]string, error) {
+	var cUTIs **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ListAllDocumentTypeUTIs")
+	code := C.ListAllDocumentTypeUTIs(&cUTIs, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return cStringArrayToSlice(cUTIs, count), nil
+}
+
+// getAppInfoForPath returns the metadata of the application bundle at a path
+func getAppInfoForPath(appPath string) (AppInfo, error) {
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	var cApp *C.AppInfo
+	var cError *C.char
+
+	trace := startCall("GetAppInfoForPath", "app", appPath)
+	code := C.GetAppInfoForPath(cAppPath, &cApp, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return AppInfo{}, cErrorToGoError(code, cError)
+	}
+
+	info := cAppInfoToGo(cApp)
+	C.FreeAppInfo(cApp)
+
+	return info, nil
+}
+
+// FindAppByBundleID finds an installed application by its bundle identifier
+func (h *systemHandler) FindAppByBundleID(bundleID string) (AppInfo, error) {
+	if bundleID == "" {
+		return AppInfo{}, ErrInvalidParameters
+	}
+
+	cBundleID := C.CString(bundleID)
+	defer C.free(unsafe.Pointer(cBundleID))
+
+	var cApp *C.AppInfo
+	var cError *C.char
+
+	trace := startCall("FindAppByBundleID", "bundle_id", bundleID)
+	code := C.FindAppByBundleID(cBundleID, &cApp, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return AppInfo{}, cErrorToGoError(code, cError)
+	}
+
+	app := cAppInfoToGo(cApp)
+	C.FreeAppInfo(cApp)
+
+	return app, nil
+}
+
+// GetAppInfoForPID returns the application bundle that owns a running process
+func (h *systemHandler) GetAppInfoForPID(pid int) (AppInfo, error) {
+	if pid <= 0 {
+		return AppInfo{}, ErrInvalidParameters
+	}
+
+	var cApp *C.AppInfo
+	var cError *C.char
+
+	trace := startCall("GetAppInfoForPID", "pid", pid)
+	code := C.GetAppInfoForPID(C.int(pid), &cApp, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return AppInfo{}, cErrorToGoError(code, cError)
+	}
+
+	app := cAppInfoToGo(cApp)
+	C.FreeAppInfo(cApp)
+
+	return app, nil
+}
+
+// GetFrontmostApp returns the application the user is currently working in
+func (h *systemHandler) GetFrontmostApp() (AppInfo, error) {
+	var cApp *C.AppInfo
+	var cError *C.char
+
+	trace := startCall("GetFrontmostApplication")
+	code := C.GetFrontmostApplication(&cApp, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return AppInfo{}, cErrorToGoError(code, cError)
+	}
+
+	app := cAppInfoToGo(cApp)
+	C.FreeAppInfo(cApp)
+
+	return app, nil
+}
+
+// IsAppRunning reports whether an application is running
+func (h *systemHandler) IsAppRunning(bundleID string) (bool, error) {
+	if bundleID == "" {
+		return false, ErrInvalidParameters
+	}
+
+	cBundleID := C.CString(bundleID)
+	defer C.free(unsafe.Pointer(cBundleID))
+
+	var isRunning C.int
+	var cError *C.char
+
+	trace := startCall("AppIsRunning", "bundle_id", bundleID)
+	code := C.AppIsRunning(cBundleID, &isRunning, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return false, cErrorToGoError(code, cError)
+	}
+
+	return isRunning != 0, nil
+}
+
+// ActivateApp brings a running application to the foreground
+func (h *systemHandler) ActivateApp(bundleID string) error {
+	if bundleID == "" {
+		return ErrInvalidParameters
+	}
+
+	cBundleID := C.CString(bundleID)
+	defer C.free(unsafe.Pointer(cBundleID))
+
+	var cError *C.char
+
+	trace := startCall("ActivateApplication", "bundle_id", bundleID)
+	code := C.ActivateApplication(cBundleID, &cError)
+	trace.end(code, cError)
+
+	return cErrorToGoError(code, cError)
+}
+
+// QuitApp asks every running instance of an application to quit
+func (h *systemHandler) QuitApp(bundleID string, force bool) error {
+	if bundleID == "" {
+		return ErrInvalidParameters
+	}
+
+	cBundleID := C.CString(bundleID)
+	defer C.free(unsafe.Pointer(cBundleID))
+
+	cForce := C.int(0)
+	if force {
+		cForce = 1
+	}
+
+	var cError *C.char
+
+	trace := startCall("TerminateApplication", "bundle_id", bundleID, "force", force)
+	code := C.TerminateApplication(cBundleID, cForce, &cError)
+	trace.end(code, cError)
+
+	return cErrorToGoError(code, cError)
+}
+
+// GetBundleID returns the bundle identifier of the application at a path
+func (h *systemHandler) GetBundleID(appPath string) (string, error) {
+	if appPath == "" {
+		return "", ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	info, err := getAppInfoForPath(appPath)
+	if err != nil {
+		return "", err
+	}
+
+	if info.BundleID == "" {
+		return "", &BridgeError{
+			Code:    int(ErrNotFound),
+			Message: fmt.Sprintf("no bundle identifier declared by app: %s", appPath),
+		}
+	}
+
+	return info.BundleID, nil
+}
+
+// GetBundleInfoValue reads a top-level value from an application's Info.plist
+func (h *systemHandler) GetBundleInfoValue(appPath, key string) (string, error) {
+	if appPath == "" || key == "" {
+		return "", ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	var cValue *C.char
+	var cError *C.char
+
+	trace := startCall("GetBundleInfoValue", "app", appPath, "key", key)
+	code := C.GetBundleInfoValue(cAppPath, cKey, &cValue, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return "", cErrorToGoError(code, cError)
+	}
+
+	value := C.GoString(cValue)
+	C.FreeCString(cValue)
+
+	return value, nil
+}
+
+// GetAppCategory returns an application's App Store category
+func (h *systemHandler) GetAppCategory(appPath string) (string, error) {
+	category, err := h.GetBundleInfoValue(appPath, "LSApplicationCategoryType")
+	if hasErrorCode(err, ErrNotFound) {
+		return "", nil
+	}
+
+	return category, err
+}
+
+// IsSandboxed reports whether an application runs in the App Sandbox
+func (h *systemHandler) IsSandboxed(appPath string) (bool, error) {
+	if appPath == "" {
+		return false, ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	var isSandboxed C.int
+	var cError *C.char
+
+	trace := startCall("AppIsSandboxed", "app", appPath)
+	code := C.AppIsSandboxed(cAppPath, &isSandboxed, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return false, cErrorToGoError(code, cError)
+	}
+
+	return isSandboxed != 0, nil
+}
+
+// GetCodeSigningTeamID returns the Team Identifier from an application's code signature
+func (h *systemHandler) GetCodeSigningTeamID(appPath string) (string, error) {
+	if appPath == "" {
+		return "", ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	var cTeamID *C.char
+	var cError *C.char
+
+	trace := startCall("GetCodeSigningTeamID", "app", appPath)
+	code := C.GetCodeSigningTeamID(cAppPath, &cTeamID, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return "", cErrorToGoError(code, cError)
+	}
+
+	teamID := C.GoString(cTeamID)
+	C.FreeCString(cTeamID)
+
+	return teamID, nil
+}
+
+// defaultIconSize is the pixel size used when an icon is requested with a non-positive size
+const defaultIconSize = 64
+
+// GetAppIconPNG renders an application's icon as PNG data
+func (h *systemHandler) GetAppIconPNG(appPath string, size int) ([]byte, error) {
+	if appPath == "" {
+		return nil, ErrInvalidParameters
+	}
+	if size <= 0 {
+		size = defaultIconSize
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	var cData *C.uchar
+	var length C.int
+	var cError *C.char
+
+	trace := startCall("GetAppIconPNG", "app", appPath, "size", size)
+	code := C.GetAppIconPNG(cAppPath, C.int(size), &cData, &length, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	png := C.GoBytes(unsafe.Pointer(cData), length)
+	C.free(unsafe.Pointer(cData))
+
+	return png, nil
+}
+
+// GetAppSummary returns an application's metadata along with counts of the document types it handles
+func (h *systemHandler) GetAppSummary(appPath string) (AppSummary, error) {
+	if appPath == "" {
+		return AppSummary{}, ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	info, err := getAppInfoForPath(appPath)
+	if err != nil {
+		return AppSummary{}, err
+	}
+
+	displayVersion := info.Version
+	if displayVersion == "" {
+		displayVersion = info.BuildVersion
+	}
+
+	docTypes, err := h.ListSupportedDocumentTypes(appPath)
+	if err != nil {
+		return AppSummary{}, err
+	}
+
+	summary := AppSummary{
+		AppInfo:            info,
+		DisplayVersion:     displayVersion,
+		SupportedTypeCount: len(docTypes),
+	}
+	for _, docType := range docTypes {
+		if docType.HandlerRank == HandlerRankOwner {
+			summary.OwnedTypeCount++
+		}
+	}
+
+	return summary, nil
+}
+
+// GetAppProfile returns everything the bridge knows about what an application handles
+func (h *systemHandler) GetAppProfile(appPath string) (AppProfile, error) {
+	if appPath == "" {
+		return AppProfile{}, ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	info, err := getAppInfoForPath(appPath)
+	if err != nil {
+		return AppProfile{}, err
+	}
+
+	supportedTypes, err := h.ListSupportedDocumentTypes(appPath)
+	if err != nil {
+		return AppProfile{}, err
+	}
+
+	defaultTypes, err := h.defaultDocumentTypes(appPath, supportedTypes)
+	if err != nil {
+		return AppProfile{}, err
+	}
+
+	supportedSchemes, err := h.ListSupportedSchemes(appPath)
+	if err != nil {
+		return AppProfile{}, err
+	}
+
+	defaultSchemes, err := h.defaultSchemes(appPath, supportedSchemes)
+	if err != nil {
+		return AppProfile{}, err
+	}
+
+	return AppProfile{
+		AppInfo:                info,
+		SupportedDocumentTypes: supportedTypes,
+		DefaultDocumentTypes:   defaultTypes,
+		SupportedSchemes:       supportedSchemes,
+		DefaultSchemes:         defaultSchemes,
+	}, nil
+}
+
+// GetAllHandlersForUTIByRole returns the applications that can handle a UTI, grouped by role
+func (h *systemHandler) GetAllHandlersForUTIByRole(uti string) (map[string][]AppInfo, error) {
+	uti = strings.TrimSpace(uti)
+	if uti == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	cUTI := C.CString(uti)
+	defer C.free(unsafe.Pointer(cUTI))
+
+	var cEditors, cViewers, cAll **C.AppInfo
+	var editorCount, viewerCount, allCount C.int
+	var cError *C.char
+
+	trace := startCall("GetAllHandlersForUTIByRole", "uti", uti)
+	code := C.GetAllHandlersForUTIByRole(cUTI,
+		&cEditors, &editorCount,
+		&cViewers, &viewerCount,
+		&cAll, &allCount,
+		&cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return map[string][]AppInfo{
+		"Editor": cAppInfoArrayToSlice(cEditors, editorCount),
+		"Viewer": cAppInfoArrayToSlice(cViewers, viewerCount),
+		"All":    cAppInfoArrayToSlice(cAll, allCount),
+	}, nil
+}
+
+// getExtensionsForUTIs returns the sorted union of the file extensions of the given UTIs
+func (h *systemHandler) getExtensionsForUTIs(utis []string) []string {
+	extensionsByUTI, err := h.ResolveExtensionsForUTIs(utis)
+	if err != nil {
+		return nil
+	}
+
+	var result []string
+	for _, extensions := range extensionsByUTI {
+		result = append(result, extensions...)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+
+	return sortedUnique(result)
 }
 
+// documentTypeWorkers bounds the goroutines ListDefaultDocumentTypes uses to resolve extensions
+var documentTypeWorkers = runtime.NumCPU()
+
 // ListDefaultDocumentTypes returns all document types where the given application is the system default
-//
-// This function checks which document types the app supports AND is actually set as the default handler for.
-//
-// Parameters:
-//   - appPath: Full path to the application bundle
-//
-// Returns:
-//   - docTypes: Slice of DocumentType structures where this app is the system default
-//   - error: Error if any
-func ListDefaultDocumentTypes(appPath string) ([]DocumentType, error) {
+func (h *systemHandler) ListDefaultDocumentTypes(appPath string) ([]DocumentType, error) {
 	if appPath == "" {
 		return nil, ErrInvalidParameters
 	}
 
+	appPath = resolveAppPath(appPath)
+
 	// Get all document types this app supports
-	allDocTypes, err := ListSupportedDocumentTypes(appPath)
+	allDocTypes, err := h.ListSupportedDocumentTypes(appPath)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter to only those where this app is the default
-	var defaultDocTypes []DocumentType
+	return h.defaultDocumentTypes(appPath, allDocTypes)
+}
 
-	// Normalize app path for comparison
-	cleanAppPath := appPath
-	if resolved, err := filepath.EvalSymlinks(appPath); err == nil {
-		cleanAppPath = resolved
+// defaultDocumentTypes narrows an app's supported document types to the UTIs it is the default for
+//
+// appPath must already be resolved.
+func (h *systemHandler) defaultDocumentTypes(appPath string, allDocTypes []DocumentType) ([]DocumentType, error) {
+	// Look up the defaults for every UTI the app claims in one batch
+	var allUTIs []string
+	for _, docType := range allDocTypes {
+		allUTIs = append(allUTIs, docType.UTIs...)
+	}
+
+	defaults, err := h.GetDefaultAppsForUTIs(allUTIs)
+	if err != nil {
+		return nil, err
 	}
 
+	// The app's own symlinks are resolved once instead of for every UTI
+	isApp := newAppPathMatcher(appPath)
+
+	// Filter to only those where this app is the default
+	var defaultDocTypes []DocumentType
+
 	for _, docType := range allDocTypes {
 		// Collect only the UTIs where this app is actually the default
 		var matchingUTIs []string
 
 		for _, uti := range docType.UTIs {
-			defaultApp, err := GetDefaultAppForUTI(uti)
-			if err != nil {
-				// Skip UTIs that have no default or error
+			// Skip UTIs that have no default
+			defaultApp, ok := defaults[uti]
+			if !ok {
 				continue
 			}
 
-			// Normalize default app path
-			cleanDefaultApp := defaultApp
-			if resolved, err := filepath.EvalSymlinks(defaultApp); err == nil {
-				cleanDefaultApp = resolved
-			}
-
 			// Check if this app is the default for this specific UTI
-			if cleanAppPath == cleanDefaultApp || strings.EqualFold(cleanAppPath, cleanDefaultApp) {
+			if isApp.matches(defaultApp) {
 				matchingUTIs = append(matchingUTIs, uti)
 			}
 		}
 
 		// Only include if at least one UTI matches
 		if len(matchingUTIs) > 0 {
-			// Derive extensions from matching UTIs
-			matchingExtensions := getExtensionsForUTIs(matchingUTIs)
-
-			// Create filtered document type
-			filteredDocType := DocumentType{
+			// Create filtered document type; extensions are filled in below
+			defaultDocTypes = append(defaultDocTypes, DocumentType{
 				TypeName:    docType.TypeName,
 				Role:        docType.Role,
 				HandlerRank: docType.HandlerRank,
 				UTIs:        matchingUTIs,
-				Extensions:  matchingExtensions,
 				IsPackage:   docType.IsPackage,
-			}
-
-			defaultDocTypes = append(defaultDocTypes, filteredDocType)
+			})
 		}
 	}
 
+	// Derive extensions from matching UTIs; each worker only writes its own entry
+	forEachIndex(len(defaultDocTypes), documentTypeWorkers, func(i int) {
+		defaultDocTypes[i].Extensions = h.getExtensionsForUTIs(defaultDocTypes[i].UTIs)
+	})
+
 	return defaultDocTypes, nil
 }
 
 // ListSupportedDocumentTypes returns all document types that an application can handle
-//
-// This returns what the app CLAIMS it can handle, not what it's the default for.
-//
-// Parameters:
-//   - appPath: Full path to the application bundle
-//
-// Returns:
-//   - docTypes: Slice of DocumentType structures containing detailed info about supported file types
-//   - error: Error if any
-func ListSupportedDocumentTypes(appPath string) ([]DocumentType, error) {
+func (h *systemHandler) ListSupportedDocumentTypes(appPath string, opts ...DocumentTypeOption) ([]DocumentType, error) {
 	if appPath == "" {
 		return nil, ErrInvalidParameters
 	}
 
+	appPath = resolveAppPath(appPath)
+
+	var options documentTypeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	cAppPath := C.CString(appPath)
 	defer C.free(unsafe.Pointer(cAppPath))
 
@@ -541,7 +2698,9 @@ func ListSupportedDocumentTypes(appPath string) ([]DocumentType, error) {
 	var count C.int
 	var cError *C.char
 
+	trace := startCall("GetSupportedDocumentTypesForApp", "app", appPath)
 	code := C.GetSupportedDocumentTypesForApp(cAppPath, &cDocTypes, &count, &cError)
+	trace.end(code, cError)
 
 	if code != C.BRIDGE_OK {
 		return nil, cErrorToGoError(code, cError)
@@ -553,7 +2712,7 @@ func ListSupportedDocumentTypes(appPath string) ([]DocumentType, error) {
 
 	// Convert C array to Go slice
 	docTypes := make([]DocumentType, int(count))
-	cDocTypesSlice := (*[1 << 28]*C.DocumentType)(unsafe.Pointer(cDocTypes))[:count:count]
+	cDocTypesSlice := unsafe.Slice(cDocTypes, int(count))
 
 	for i := 0; i < int(count); i++ {
 		cDocType := cDocTypesSlice[i]
@@ -561,7 +2720,7 @@ func ListSupportedDocumentTypes(appPath string) ([]DocumentType, error) {
 		// Convert UTIs array
 		utis := make([]string, int(cDocType.utiCount))
 		if cDocType.utiCount > 0 && cDocType.utis != nil {
-			cUTIsSlice := (*[1 << 28]*C.char)(unsafe.Pointer(cDocType.utis))[:cDocType.utiCount:cDocType.utiCount]
+			cUTIsSlice := unsafe.Slice(cDocType.utis, int(cDocType.utiCount))
 			for j := 0; j < int(cDocType.utiCount); j++ {
 				utis[j] = C.GoString(cUTIsSlice[j])
 			}
@@ -570,21 +2729,22 @@ func ListSupportedDocumentTypes(appPath string) ([]DocumentType, error) {
 		// Convert extensions array
 		extensions := make([]string, int(cDocType.extensionCount))
 		if cDocType.extensionCount > 0 && cDocType.extensions != nil {
-			cExtensionsSlice := (*[1 << 28]*C.char)(unsafe.Pointer(cDocType.extensions))[:cDocType.extensionCount:cDocType.extensionCount]
+			cExtensionsSlice := unsafe.Slice(cDocType.extensions, int(cDocType.extensionCount))
 			for j := 0; j < int(cDocType.extensionCount); j++ {
 				extensions[j] = C.GoString(cExtensionsSlice[j])
 			}
 		}
 
-		// Get handler rank (may be NULL)
-		handlerRank := ""
+		// Get handler rank (may be NULL); unexpected values become the Unknown constants
+		var handlerRank HandlerRank
 		if cDocType.handlerRank != nil {
-			handlerRank = C.GoString(cDocType.handlerRank)
+			handlerRank, _ = ParseHandlerRank(C.GoString(cDocType.handlerRank))
 		}
+		role, _ := ParseRole(C.GoString(cDocType.role))
 
 		docTypes[i] = DocumentType{
 			TypeName:    C.GoString(cDocType.typeName),
-			Role:        C.GoString(cDocType.role),
+			Role:        role,
 			HandlerRank: handlerRank,
 			UTIs:        utis,
 			Extensions:  extensions,
@@ -594,5 +2754,396 @@ func ListSupportedDocumentTypes(appPath string) ([]DocumentType, error) {
 
 	C.FreeDocumentTypeArray(cDocTypes, count)
 
+	if options.derived {
+		for i := range docTypes {
+			derived := h.getExtensionsForUTIs(docTypes[i].UTIs)
+			if derived == nil {
+				derived = []string{}
+			}
+			docTypes[i].DerivedExtensions = derived
+		}
+	}
+
 	return docTypes, nil
 }
+
+// GroupSupportedDocumentTypesByFamily groups the document types an application can handle by family
+func (h *systemHandler) GroupSupportedDocumentTypesByFamily(appPath string) (map[TypeFamily][]DocumentType, error) {
+	docTypes, err := h.ListSupportedDocumentTypes(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	conforms := func(uti, parentUTI string) bool {
+		ok, err := h.ConformsTo(uti, parentUTI)
+		return err == nil && ok
+	}
+
+	groups := make(map[TypeFamily][]DocumentType)
+	for _, docType := range docTypes {
+		family := primaryFamily(docType.UTIs, conforms)
+		groups[family] = append(groups[family], docType)
+	}
+
+	return groups, nil
+}
+
+// ListSupportedDocumentTypesByRole returns the document types an application declares with a given role
+func (h *systemHandler) ListSupportedDocumentTypesByRole(appPath string, role Role) ([]DocumentType, error) {
+	if _, ok := roleMask(role); appPath == "" || !ok {
+		return nil, ErrInvalidParameters
+	}
+
+	docTypes, err := h.ListSupportedDocumentTypes(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterDocumentTypesByRole(docTypes, role), nil
+}
+
+// GetDocumentTypeForUTI returns the document type an application declares for a UTI
+func (h *systemHandler) GetDocumentTypeForUTI(appPath, uti string) (DocumentType, error) {
+	uti = strings.TrimSpace(uti)
+	if appPath == "" || uti == "" {
+		return DocumentType{}, ErrInvalidParameters
+	}
+
+	docTypes, err := h.ListSupportedDocumentTypes(appPath)
+	if err != nil {
+		return DocumentType{}, err
+	}
+
+	docType, ok := findDocumentTypeForUTI(docTypes, uti)
+	if !ok {
+		return DocumentType{}, &BridgeError{
+			Code:    int(ErrNotFound),
+			Message: fmt.Sprintf("%s does not declare a document type for UTI: %s", appPath, uti),
+		}
+	}
+
+	return docType, nil
+}
+
+// AppSupportsUTI reports whether an application can handle a UTI
+func (h *systemHandler) AppSupportsUTI(appPath, uti string) (bool, error) {
+	uti = strings.TrimSpace(uti)
+	if appPath == "" || uti == "" {
+		return false, ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	docTypes, err := h.ListSupportedDocumentTypes(appPath)
+	if err != nil {
+		return false, err
+	}
+
+	conforms := func(uti, parentUTI string) bool {
+		ok, err := h.ConformsTo(uti, parentUTI)
+		return err == nil && ok
+	}
+
+	if declaresOrConformsTo(docTypes, uti, conforms) {
+		return true, nil
+	}
+
+	apps, err := h.ListAppsForUTI(uti)
+	if err != nil {
+		// Unknown UTIs have no handlers at all
+		if hasErrorCode(err, ErrInvalidUTI) || hasErrorCode(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	isApp := newAppPathMatcher(appPath)
+	for _, app := range apps {
+		if isApp.matches(app) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ListSupportedSchemes returns the URL schemes an application declares in CFBundleURLTypes
+func (h *systemHandler) ListSupportedSchemes(appPath string) ([]string, error) {
+	if appPath == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	var cSchemes **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("GetSupportedSchemesForApp", "app", appPath)
+	code := C.GetSupportedSchemesForApp(cAppPath, &cSchemes, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return cStringArrayToSlice(cSchemes, count), nil
+}
+
+// ListExportedUTIs returns the UTIs an application defines in UTExportedTypeDeclarations
+func (h *systemHandler) ListExportedUTIs(appPath string) ([]string, error) {
+	return listDeclaredUTIs(appPath, false)
+}
+
+// ListImportedUTIs returns the UTIs an application declares in UTImportedTypeDeclarations
+func (h *systemHandler) ListImportedUTIs(appPath string) ([]string, error) {
+	return listDeclaredUTIs(appPath, true)
+}
+
+// listDeclaredUTIs implements ListExportedUTIs and ListImportedUTIs
+func listDeclaredUTIs(appPath string, imported bool) ([]string, error) {
+	if appPath == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	cAppPath := C.CString(appPath)
+	defer C.free(unsafe.Pointer(cAppPath))
+
+	var cImported C.int
+	if imported {
+		cImported = 1
+	}
+
+	var cUTIs **C.char
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("GetDeclaredUTIsForApp", "app", appPath, "imported", imported)
+	code := C.GetDeclaredUTIsForApp(cAppPath, cImported, &cUTIs, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	return cStringArrayToSlice(cUTIs, count), nil
+}
+
+// ListDefaultSchemes returns the URL schemes for which the given application is the system default
+func (h *systemHandler) ListDefaultSchemes(appPath string) ([]string, error) {
+	if appPath == "" {
+		return nil, ErrInvalidParameters
+	}
+
+	appPath = resolveAppPath(appPath)
+
+	schemes, err := h.ListSupportedSchemes(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.defaultSchemes(appPath, schemes)
+}
+
+// defaultSchemes narrows an app's declared URL schemes to those it is the default for
+//
+// appPath must already be resolved.
+func (h *systemHandler) defaultSchemes(appPath string, schemes []string) ([]string, error) {
+	defaultSchemes := []string{}
+	for _, scheme := range schemes {
+		defaultApp, err := h.GetDefaultAppForScheme(scheme)
+		if err != nil {
+			// A scheme without a usable default is simply not ours
+			if hasErrorCode(err, ErrNotFound) || errors.Is(err, ErrDefaultHandlerInvalid) {
+				continue
+			}
+			return nil, err
+		}
+
+		if appPathsEqual(appPath, defaultApp) {
+			defaultSchemes = append(defaultSchemes, scheme)
+		}
+	}
+
+	return defaultSchemes, nil
+}
+
+// CheckSchemeHandlerConsistency reports whether the default handler for a URL scheme still declares it
+func (h *systemHandler) CheckSchemeHandlerConsistency(scheme string) (bool, string, error) {
+	scheme = strings.TrimSpace(scheme)
+	if scheme == "" {
+		return false, "", ErrInvalidParameters
+	}
+
+	appPath, err := h.GetDefaultAppForScheme(scheme)
+	if err != nil {
+		return false, "", err
+	}
+
+	schemes, err := h.ListSupportedSchemes(appPath)
+	if err != nil {
+		return false, appPath, err
+	}
+
+	for _, declared := range schemes {
+		if strings.EqualFold(declared, scheme) {
+			return true, appPath, nil
+		}
+	}
+
+	return false, appPath, nil
+}
+
+// listHandlerPreferences returns the user's customized default handlers
+func listHandlerPreferences() ([]handlerPreference, error) {
+	var cPrefs **C.HandlerPreference
+	var count C.int
+	var cError *C.char
+
+	trace := startCall("ListHandlerPreferences")
+	code := C.ListHandlerPreferences(&cPrefs, &count, &cError)
+	trace.end(code, cError)
+
+	if code != C.BRIDGE_OK {
+		return nil, cErrorToGoError(code, cError)
+	}
+
+	if count == 0 || cPrefs == nil {
+		return []handlerPreference{}, nil
+	}
+
+	prefs := make([]handlerPreference, int(count))
+	cSlice := unsafe.Slice(cPrefs, int(count))
+
+	for i := 0; i < int(count); i++ {
+		cPref := cSlice[i]
+		prefs[i] = handlerPreference{
+			Kind:       C.GoString(cPref.kind),
+			Identifier: C.GoString(cPref.identifier),
+			Role:       C.GoString(cPref.role),
+			App: AppInfo{
+				Name:     C.GoString(cPref.appName),
+				Path:     C.GoString(cPref.appPath),
+				BundleID: C.GoString(cPref.bundleID),
+			},
+		}
+	}
+
+	C.FreeHandlerPreferenceArray(cPrefs, count)
+
+	return prefs, nil
+}
+
+// defaultChangePollInterval is how often WatchDefaultChanges re-reads the user's handler preferences
+const defaultChangePollInterval = 2 * time.Second
+
+// WatchDefaultChanges reports changes to the user's default handlers as they happen
+func (h *systemHandler) WatchDefaultChanges() (<-chan HandlerChangeEvent, func(), error) {
+	return watchPolled(listHandlerPreferences, diffHandlerPreferences, defaultChangePollInterval)
+}
+
+// DumpHandlerConfiguration returns a human-readable report of the user's default handlers
+func (h *systemHandler) DumpHandlerConfiguration() (string, error) {
+	prefs, err := listHandlerPreferences()
+	if err != nil {
+		return "", err
+	}
+
+	return formatHandlerConfiguration(prefs), nil
+}
+
+// GetAllDefaultHandlers returns the default application for every UTI with an explicit handler
+func (h *systemHandler) GetAllDefaultHandlers() (map[string]string, error) {
+	prefs, err := listHandlerPreferences()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var utis []string
+	for _, pref := range prefs {
+		if pref.Kind != "uti" || seen[pref.Identifier] {
+			continue
+		}
+		seen[pref.Identifier] = true
+		utis = append(utis, pref.Identifier)
+	}
+
+	return h.GetDefaultAppsForUTIs(utis)
+}
+
+// SnapshotDefaults captures the current default handlers for later restoration
+func (h *systemHandler) SnapshotDefaults() (HandlerSnapshot, error) {
+	prefs, err := listHandlerPreferences()
+	if err != nil {
+		return HandlerSnapshot{}, err
+	}
+
+	snapshot := HandlerSnapshot{
+		UTIs:    make(map[string]AppInfo),
+		Schemes: make(map[string]AppInfo),
+	}
+
+	seen := make(map[string]bool)
+	var utis, schemes []string
+	for _, pref := range prefs {
+		key := pref.Kind + ":" + pref.Identifier
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		switch pref.Kind {
+		case "uti":
+			utis = append(utis, pref.Identifier)
+		case "scheme":
+			schemes = append(schemes, pref.Identifier)
+		}
+	}
+
+	defaults, err := h.GetDefaultAppsForUTIs(utis)
+	if err != nil {
+		return HandlerSnapshot{}, err
+	}
+
+	// Many types share a handler, so look each app up only once
+	apps := make(map[string]AppInfo)
+	for uti, appPath := range defaults {
+		app, ok := apps[appPath]
+		if !ok {
+			if app, err = getAppInfoForPath(appPath); err != nil {
+				continue
+			}
+			apps[appPath] = app
+		}
+		snapshot.UTIs[uti] = app
+	}
+
+	for _, scheme := range schemes {
+		app, err := h.GetDefaultAppInfoForScheme(scheme)
+		if err != nil {
+			if hasErrorCode(err, ErrNotFound) || errors.Is(err, ErrDefaultHandlerInvalid) {
+				continue
+			}
+			return HandlerSnapshot{}, err
+		}
+		snapshot.Schemes[scheme] = app
+	}
+
+	return snapshot, nil
+}
+
+// RestoreDefaults applies the default handlers recorded in a snapshot
+func (h *systemHandler) RestoreDefaults(snapshot HandlerSnapshot) error {
+	return restoreSnapshot(snapshot, h.FindAppByBundleID, h.SetDefaultForUTIForce, h.SetDefaultForScheme)
+}
+
+// ResolveSnapshot resolves the bundle IDs in a snapshot to the apps installed on this machine
+func (h *systemHandler) ResolveSnapshot(snapshot HandlerSnapshot) (HandlerSnapshot, []string, error) {
+	return resolveSnapshot(snapshot, h.FindAppByBundleID)
+}
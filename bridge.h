@@ -1,6 +1,14 @@
 #ifndef MACOS_APPHANDLERS_BRIDGE_H
 #define MACOS_APPHANDLERS_BRIDGE_H
 
+// Memory contract
+//
+// Every function sets its out-pointers to NULL (and counts to 0) before doing
+// any work. On success the caller owns whatever they point to and frees it with
+// the function named in the parameter's comment. On failure they are left NULL
+// and 0, with anything allocated along the way already released, so only
+// outError needs freeing (with FreeCString).
+
 // Return codes
 #define BRIDGE_OK 0
 #define BRIDGE_ERROR_INVALID_APP -1
@@ -10,12 +18,22 @@
 #define BRIDGE_ERROR_USER_DECLINED -5
 #define BRIDGE_ERROR_NOT_FOUND -6
 
+// Role masks (matching LSRolesMask)
+#define BRIDGE_ROLE_NONE 0x00000001
+#define BRIDGE_ROLE_VIEWER 0x00000002
+#define BRIDGE_ROLE_EDITOR 0x00000004
+#define BRIDGE_ROLE_SHELL 0x00000008
+#define BRIDGE_ROLE_ALL 0xFFFFFFFF
+
 // Application information structure
 typedef struct
 {
-    char *name;     // Application display name
-    char *path;     // Full path to application bundle
-    char *bundleID; // Bundle identifier (e.g., "com.apple.Safari")
+    char *name;         // Application display name
+    char *path;         // Full path to application bundle
+    char *bundleID;     // Bundle identifier (e.g., "com.apple.Safari")
+    char *version;      // CFBundleShortVersionString, empty if not declared
+    char *buildVersion; // CFBundleVersion, empty if not declared
+    char *category;     // LSApplicationCategoryType, empty if not declared
 } AppInfo;
 
 // Document type information structure
@@ -31,6 +49,30 @@ typedef struct
     int isPackage;       // 1 if this is a package/bundle type, 0 otherwise
 } DocumentType;
 
+// Handler preference structure (one role of an LSHandlers entry)
+typedef struct
+{
+    char *kind;       // "uti" for content types, "scheme" for URL schemes
+    char *identifier; // UTI or URL scheme
+    char *role;       // Role: "All", "Viewer", "Editor", "Shell"
+    char *bundleID;   // Bundle identifier of the handler
+    char *appName;    // Handler display name, empty if the app is not installed
+    char *appPath;    // Handler bundle path, empty if the app is not installed
+} HandlerPreference;
+
+// Tag specification structure (the tags a UTI is known by, per tag class)
+typedef struct
+{
+    char **extensions;       // Filename extensions, without dots
+    int extensionCount;      // Number of extensions
+    char **mimeTypes;        // MIME types
+    int mimeTypeCount;       // Number of MIME types
+    char **pasteboardTypes;  // Legacy NSPasteboard types (com.apple.nspboard-type)
+    int pasteboardTypeCount; // Number of pasteboard types
+    char **osTypes;          // Classic Mac OS four-character type codes (com.apple.ostype)
+    int osTypeCount;         // Number of OSTypes
+} TagSpecification;
+
 // Get the default application for a UTI
 //
 // Parameters:
@@ -41,6 +83,28 @@ typedef struct
 // Returns: BRIDGE_OK on success, error code otherwise
 int GetDefaultAppForUTI(const char *uti, char **outAppPath, char **outError);
 
+// Get the default applications for several UTIs in one call
+//
+// Parameters:
+//   utis: Array of Uniform Type Identifiers
+//   utiCount: Number of UTIs in the array
+//   outAppPaths: Pointer to receive array of utiCount app paths, parallel to utis; an entry is NULL
+//                when the UTI is unknown or has no default (caller must free using FreeCStringArray)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetDefaultAppsForUTIs(const char **utis, int utiCount, char ***outAppPaths, char **outError);
+
+// Get the default application for a UTI along with its metadata
+//
+// Parameters:
+//   uti: The Uniform Type Identifier (e.g., "public.plain-text")
+//   outApp: Pointer to receive the AppInfo structure (caller must free using FreeAppInfo)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetDefaultAppInfoForUTI(const char *uti, AppInfo **outApp, char **outError);
+
 // Get the default application for a URL scheme
 //
 // Parameters:
@@ -51,25 +115,98 @@ int GetDefaultAppForUTI(const char *uti, char **outAppPath, char **outError);
 // Returns: BRIDGE_OK on success, error code otherwise
 int GetDefaultAppForScheme(const char *scheme, char **outAppPath, char **outError);
 
+// Get the default application for a URL scheme along with its metadata
+//
+// Parameters:
+//   scheme: The URL scheme (e.g., "http", "mailto")
+//   outApp: Pointer to receive the AppInfo structure (caller must free using FreeAppInfo)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetDefaultAppInfoForScheme(const char *scheme, AppInfo **outApp, char **outError);
+
 // Set the default application for a UTI
 //
 // Parameters:
 //   appPath: Full path to the application bundle (e.g., "/Applications/TextEdit.app")
 //   uti: The Uniform Type Identifier (e.g., "public.plain-text")
+//   outStatus: Pointer to receive the OSStatus reported by the system on failure (0 if none)
 //   outError: Pointer to receive error message if any (caller must free)
 //
 // Returns: BRIDGE_OK on success, error code otherwise
-int SetDefaultForUTI(const char *appPath, const char *uti, char **outError);
+int SetDefaultForUTI(const char *appPath, const char *uti, int *outStatus, char **outError);
+
+// Set the default application for a UTI in a single role
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/Applications/TextEdit.app")
+//   uti: The Uniform Type Identifier (e.g., "public.plain-text")
+//   roleMask: BRIDGE_ROLE_VIEWER, BRIDGE_ROLE_EDITOR, BRIDGE_ROLE_SHELL or BRIDGE_ROLE_ALL
+//   outStatus: Pointer to receive the OSStatus reported by the system on failure (0 if none)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int SetDefaultForUTIWithRole(const char *appPath, const char *uti, unsigned int roleMask, int *outStatus, char **outError);
+
+// Get the default application for a UTI in a single role
+//
+// Parameters:
+//   uti: The Uniform Type Identifier (e.g., "public.plain-text")
+//   roleMask: One of the BRIDGE_ROLE_* masks
+//   outAppPath: Pointer to receive the application path (caller must free)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, BRIDGE_ERROR_NOT_FOUND if no handler is set for the role,
+//          error code otherwise
+int GetDefaultAppForUTIWithRole(const char *uti, unsigned int roleMask, char **outAppPath, char **outError);
+
+// Reset the default application for a UTI to the system's choice
+//
+// Removes the user's LSHandlers overrides for the UTI (every role) from the
+// LaunchServices preferences.
+//
+// Parameters:
+//   uti: The Uniform Type Identifier (e.g., "public.plain-text")
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ResetDefaultForUTI(const char *uti, char **outError);
+
+// Reset the default application for a URL scheme to the system's choice
+//
+// Removes the user's LSHandlers overrides for the scheme from the
+// LaunchServices preferences.
+//
+// Parameters:
+//   scheme: The URL scheme (e.g., "webcal")
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ResetDefaultForScheme(const char *scheme, char **outError);
 
 // Set the default application for a URL scheme
 //
 // Parameters:
 //   appPath: Full path to the application bundle
 //   scheme: The URL scheme (e.g., "http", "mailto")
+//   outStatus: Pointer to receive the OSStatus reported by the system on failure (0 if none)
 //   outError: Pointer to receive error message if any (caller must free)
 //
 // Returns: BRIDGE_OK on success, error code otherwise
-int SetDefaultForScheme(const char *appPath, const char *scheme, char **outError);
+int SetDefaultForScheme(const char *appPath, const char *scheme, int *outStatus, char **outError);
+
+// Register an application bundle with LaunchServices
+//
+// Updates the LaunchServices database entry for the bundle even if it is
+// already registered (LSRegisterURL with inUpdate set).
+//
+// Parameters:
+//   appPath: Full path to the application bundle
+//   outStatus: Pointer to receive the OSStatus reported by the system on failure (0 if none)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int RegisterApplication(const char *appPath, int *outStatus, char **outError);
 
 // Resolve file extension to UTI(s)
 //
@@ -82,6 +219,17 @@ int SetDefaultForScheme(const char *appPath, const char *scheme, char **outError
 // Returns: BRIDGE_OK on success, error code otherwise
 int ResolveUTIsForExtension(const char *extension, char ***outUTIs, int *outCount, char **outError);
 
+// Resolve MIME type to UTI(s)
+//
+// Parameters:
+//   mimeType: MIME type without parameters (e.g., "text/html")
+//   outUTIs: Pointer to receive array of UTI strings (caller must free using FreeCStringArray)
+//   outCount: Pointer to receive count of UTIs returned, 0 if nothing matches
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ResolveUTIsForMIMEType(const char *mimeType, char ***outUTIs, int *outCount, char **outError);
+
 // Get file extensions for a UTI
 //
 // Parameters:
@@ -93,6 +241,150 @@ int ResolveUTIsForExtension(const char *extension, char ***outUTIs, int *outCoun
 // Returns: BRIDGE_OK on success, error code otherwise
 int GetExtensionsForUTI(const char *uti, char ***outExtensions, int *outCount, char **outError);
 
+// Get file extensions for several UTIs in one call
+//
+// The extensions of all UTIs are returned back to back in a single array:
+// the first counts[0] belong to utis[0], the next counts[1] to utis[1], and so on.
+// Each UTI's extensions are sorted and deduplicated. Unknown UTIs get a count of 0.
+//
+// Parameters:
+//   utis: Array of Uniform Type Identifiers
+//   utiCount: Number of UTIs in the array
+//   outExtensions: Pointer to receive array of outTotal extension strings (caller must free using FreeCStringArray)
+//   outCounts: Pointer to receive array of utiCount extension counts, parallel to utis (caller must free)
+//   outTotal: Pointer to receive the total number of extensions returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetExtensionsForUTIs(const char **utis, int utiCount, char ***outExtensions, int **outCounts, int *outTotal, char **outError);
+
+// Get the preferred file extension for a UTI
+//
+// Parameters:
+//   uti: The UTI string (e.g., "public.jpeg")
+//   outExtension: Pointer to receive the extension without a dot (caller must free)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, BRIDGE_ERROR_NOT_FOUND if the UTI has no
+//          filename extension, error code otherwise
+int GetPreferredExtensionForUTI(const char *uti, char **outExtension, char **outError);
+
+// Get every tag of a UTI, grouped by tag class
+//
+// Parameters:
+//   uti: The UTI string (e.g., "public.jpeg")
+//   outSpec: Pointer to receive the TagSpecification (caller must free using FreeTagSpecification)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetTagSpecificationForUTI(const char *uti, TagSpecification **outSpec, char **outError);
+
+// Free a TagSpecification structure allocated by GetTagSpecificationForUTI
+//
+// Parameters:
+//   spec: The TagSpecification structure to free
+void FreeTagSpecification(TagSpecification *spec);
+
+// Get MIME types for a UTI
+//
+// Parameters:
+//   uti: The UTI string (e.g., "public.html", "public.jpeg")
+//   outMIMETypes: Pointer to receive array of MIME type strings, preferred first (caller must free using FreeCStringArray)
+//   outCount: Pointer to receive count of MIME types returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetMIMETypesForUTI(const char *uti, char ***outMIMETypes, int *outCount, char **outError);
+
+// Get the preferred UTI for a file extension
+//
+// Parameters:
+//   extension: File extension without dot (e.g., "txt", "md")
+//   outUTI: Pointer to receive the UTI string (caller must free)
+//   outIsDynamic: Pointer to receive 1 if the UTI is a dynamic (dyn.*) type, 0 otherwise
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetPreferredUTIForExtension(const char *extension, char **outUTI, int *outIsDynamic, char **outError);
+
+// Resolve the UTI of a file on disk
+//
+// The file's extension is used when it maps to a declared type; otherwise the
+// content type recorded for the file (NSURLContentTypeKey) is used.
+//
+// Parameters:
+//   filePath: Full path to the file
+//   outUTI: Pointer to receive the UTI string (caller must free)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, BRIDGE_ERROR_NOT_FOUND if the type cannot be determined,
+//          error code otherwise
+int ResolveUTIForFile(const char *filePath, char **outUTI, char **outError);
+
+// Check whether a UTI is a dynamic (dyn.*) type
+//
+// Parameters:
+//   uti: The UTI to check (e.g., "dyn.ah62d4rv4ge80e5pe")
+//   outIsDynamic: Pointer to receive 1 if the UTI is dynamic, 0 otherwise
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int UTIIsDynamic(const char *uti, int *outIsDynamic, char **outError);
+
+// Check whether a UTI is declared by the system or an installed application
+//
+// Parameters:
+//   uti: The UTI to check (e.g., "public.plain-text")
+//   outIsDeclared: Pointer to receive 1 if the UTI is a declared type, 0 if it is unknown or dynamic
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int UTIIsDeclared(const char *uti, int *outIsDeclared, char **outError);
+
+// Check whether a UTI conforms to another UTI
+//
+// Parameters:
+//   uti: The UTI to check (e.g., "public.jpeg")
+//   parentUTI: The UTI to check conformance against (e.g., "public.image")
+//   outConforms: Pointer to receive 1 if uti conforms to parentUTI, 0 otherwise
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int UTIConformsTo(const char *uti, const char *parentUTI, int *outConforms, char **outError);
+
+// Get all UTIs a type conforms to (transitive, excluding the type itself)
+//
+// Parameters:
+//   uti: The UTI to inspect (e.g., "public.jpeg")
+//   outUTIs: Pointer to receive array of sorted UTI strings (caller must free using FreeCStringArray)
+//   outCount: Pointer to receive count of UTIs returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetSupertypesForUTI(const char *uti, char ***outUTIs, int *outCount, char **outError);
+
+// Get the localized description for a UTI
+//
+// Parameters:
+//   uti: The Uniform Type Identifier (e.g., "public.plain-text")
+//   outDescription: Pointer to receive the description, or the identifier if there is none (caller must free)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetUTIDescription(const char *uti, char **outDescription, char **outError);
+
+// Render the generic document icon for a UTI as PNG data
+//
+// Parameters:
+//   uti: The Uniform Type Identifier (e.g., "public.jpeg")
+//   size: Width and height of the rendered icon in pixels
+//   outData: Pointer to receive the PNG bytes (caller must free)
+//   outLength: Pointer to receive the number of bytes returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetUTIIconPNG(const char *uti, int size, unsigned char **outData, int *outLength, char **outError);
+
 // List all applications that can open a UTI
 //
 // Parameters:
@@ -104,6 +396,46 @@ int GetExtensionsForUTI(const char *uti, char ***outExtensions, int *outCount, c
 // Returns: BRIDGE_OK on success, error code otherwise
 int ListAppsForUTI(const char *uti, char ***outAppPaths, int *outCount, char **outError);
 
+// List all applications that can open each of several UTIs in one call
+//
+// The app paths of all UTIs are returned back to back in a single array:
+// the first counts[0] belong to utis[0], the next counts[1] to utis[1], and so on.
+// Unknown UTIs get a count of 0.
+//
+// Parameters:
+//   utis: Array of Uniform Type Identifiers
+//   utiCount: Number of UTIs in the array
+//   outAppPaths: Pointer to receive array of outTotal app path strings (caller must free using FreeCStringArray)
+//   outCounts: Pointer to receive array of utiCount app counts, parallel to utis (caller must free)
+//   outTotal: Pointer to receive the total number of app paths returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ListAppsForUTIs(const char **utis, int utiCount, char ***outAppPaths, int **outCounts, int *outTotal, char **outError);
+
+// List the applications that can open a UTI in a given role
+//
+// Parameters:
+//   uti: The Uniform Type Identifier
+//   roleMask: One of the BRIDGE_ROLE_* masks; BRIDGE_ROLE_ALL behaves like ListAppsForUTI
+//   outAppPaths: Pointer to receive array of app path strings (caller must free using FreeCStringArray)
+//   outCount: Pointer to receive count of apps returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ListAppsForUTIWithRole(const char *uti, unsigned int roleMask, char ***outAppPaths, int *outCount, char **outError);
+
+// List all applications that can open a UTI along with their metadata
+//
+// Parameters:
+//   uti: The Uniform Type Identifier
+//   outApps: Pointer to receive array of AppInfo structures in LaunchServices' order (caller must free using FreeAppInfoArray)
+//   outCount: Pointer to receive count of apps returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ListAppInfosForUTI(const char *uti, AppInfo ***outApps, int *outCount, char **outError);
+
 // List all applications that can handle a URL scheme
 //
 // Parameters:
@@ -115,6 +447,81 @@ int ListAppsForUTI(const char *uti, char ***outAppPaths, int *outCount, char **o
 // Returns: BRIDGE_OK on success, error code otherwise
 int ListAppsForScheme(const char *scheme, char ***outAppPaths, int *outCount, char **outError);
 
+// List the applications that can handle a UTI, grouped by role
+//
+// Parameters:
+//   uti: The Uniform Type Identifier
+//   outEditors: Pointer to receive array of AppInfo structures for the editor role (caller must free using FreeAppInfoArray)
+//   outEditorCount: Pointer to receive count of editor applications
+//   outViewers: Pointer to receive array of AppInfo structures for the viewer role (caller must free using FreeAppInfoArray)
+//   outViewerCount: Pointer to receive count of viewer applications
+//   outAll: Pointer to receive array of AppInfo structures for any role (caller must free using FreeAppInfoArray)
+//   outAllCount: Pointer to receive count of applications for any role
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetAllHandlersForUTIByRole(const char *uti,
+                               AppInfo ***outEditors, int *outEditorCount,
+                               AppInfo ***outViewers, int *outViewerCount,
+                               AppInfo ***outAll, int *outAllCount,
+                               char **outError);
+
+// Open a file with its default application
+//
+// Parameters:
+//   filePath: Full path to the file to open (e.g., "/Users/me/notes.txt")
+//   outStatus: Pointer to receive the OSStatus reported by the system on failure (0 if none)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, BRIDGE_ERROR_NOT_FOUND if no application can open the file,
+//          error code otherwise
+int OpenFile(const char *filePath, int *outStatus, char **outError);
+
+// Open a file with a specific application
+//
+// Parameters:
+//   filePath: Full path to the file to open
+//   appPath: Full path to the application bundle (e.g., "/Applications/TextEdit.app")
+//   outStatus: Pointer to receive the OSStatus reported by the system on failure (0 if none)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int OpenFileWithApp(const char *filePath, const char *appPath, int *outStatus, char **outError);
+
+// Open several files with a specific application in a single launch
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/Applications/TextEdit.app")
+//   filePaths: Array of full paths to the files to open
+//   fileCount: Number of paths in the array
+//   outStatus: Pointer to receive the OSStatus reported by the system on failure (0 if none)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int OpenFilesWithApp(const char *appPath, const char **filePaths, int fileCount, int *outStatus, char **outError);
+
+// Check that a path points to a loadable application bundle
+//
+// A valid bundle is a directory with a readable Info.plist, an APPL package
+// type (or .app extension when none is declared) and an executable.
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/Applications/TextEdit.app")
+//   outError: Pointer to receive error message describing why the bundle is invalid (caller must free)
+//
+// Returns: BRIDGE_OK if the bundle is valid, BRIDGE_ERROR_INVALID_APP otherwise
+int ValidateAppBundle(const char *appPath, char **outError);
+
+// Resolve a path that may be a Finder alias or symlink to the item it points to
+//
+// Parameters:
+//   path: Path to resolve (e.g., "~/Desktop/TextEdit alias")
+//   outResolvedPath: Pointer to receive the resolved path (caller must free)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ResolveAliasPath(const char *path, char **outResolvedPath, char **outError);
+
 // Free a single C string allocated by bridge functions
 //
 // Parameters:
@@ -138,6 +545,177 @@ void FreeCStringArray(char **arr, int count);
 // Returns: BRIDGE_OK on success, error code otherwise
 int ListAllApplications(AppInfo ***outApps, int *outCount, char **outError);
 
+// List every UTI registered by installed applications
+//
+// Collects the UTIs apps claim in CFBundleDocumentTypes and declare in
+// UTExportedTypeDeclarations / UTImportedTypeDeclarations, deduplicated and sorted.
+//
+// Parameters:
+//   outUTIs: Pointer to receive array of UTI strings (caller must free using FreeCStringArray)
+//   outCount: Pointer to receive count of UTIs returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ListAllRegisteredUTIs(char ***outUTIs, int *outCount, char **outError);
+
+// List every UTI installed applications claim to open
+//
+// Collects only the UTIs in CFBundleDocumentTypes' LSItemContentTypes,
+// deduplicated and sorted. Exported and imported type declarations are skipped.
+//
+// Parameters:
+//   outUTIs: Pointer to receive array of UTI strings (caller must free using FreeCStringArray)
+//   outCount: Pointer to receive count of UTIs returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ListAllDocumentTypeUTIs(char ***outUTIs, int *outCount, char **outError);
+
+// Get metadata for the application bundle at a path
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/Applications/TextEdit.app")
+//   outApp: Pointer to receive the AppInfo structure (caller must free using FreeAppInfo)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetAppInfoForPath(const char *appPath, AppInfo **outApp, char **outError);
+
+// Read a top-level value from an application bundle's Info.plist
+//
+// Localized values (InfoPlist.strings) take precedence. Values that are not
+// strings are returned as their description.
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/Applications/TextEdit.app")
+//   key: The Info.plist key (e.g., "LSMinimumSystemVersion")
+//   outValue: Pointer to receive the value (caller must free using FreeCString)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, BRIDGE_ERROR_NOT_FOUND if the key is absent,
+//          error code otherwise
+int GetBundleInfoValue(const char *appPath, const char *key, char **outValue, char **outError);
+
+// Check whether an application is signed with the App Sandbox entitlement
+//
+// Reads the entitlements embedded in the bundle's code signature. Unsigned
+// apps are reported as not sandboxed.
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/System/Applications/Calculator.app")
+//   outIsSandboxed: Pointer to receive 1 if com.apple.security.app-sandbox is true, 0 otherwise
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int AppIsSandboxed(const char *appPath, int *outIsSandboxed, char **outError);
+
+// Get the Team Identifier from an application's code signature
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/Applications/Firefox.app")
+//   outTeamID: Pointer to receive the team identifier, empty for unsigned or ad-hoc signed apps
+//              (caller must free using FreeCString)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetCodeSigningTeamID(const char *appPath, char **outTeamID, char **outError);
+
+// Check whether an application's code signature is valid
+//
+// Runs SecStaticCodeCheckValidity over the bundle, which hashes its executable
+// and resources. A missing or broken signature is not an error of the call
+// itself: it is reported through outIsValid and outStatus.
+//
+// Parameters:
+//   appPath: Full path to the application bundle
+//   outIsValid: Pointer to receive 1 if the signature is valid, 0 otherwise
+//   outStatus: Pointer to receive the OSStatus of the failed check (0 if valid)
+//   outError: Pointer to receive error message if any, including why the signature is invalid (caller must free)
+//
+// Returns: BRIDGE_OK if the check ran, error code otherwise
+int CheckCodeSignature(const char *appPath, int *outIsValid, int *outStatus, char **outError);
+
+// Find an installed application by its bundle identifier
+//
+// Parameters:
+//   bundleID: The bundle identifier (e.g., "com.apple.Safari")
+//   outApp: Pointer to receive the AppInfo structure (caller must free using FreeAppInfo)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, BRIDGE_ERROR_NOT_FOUND if no application has the identifier,
+//          error code otherwise
+int FindAppByBundleID(const char *bundleID, AppInfo **outApp, char **outError);
+
+// Get the application bundle that owns a running process
+//
+// Parameters:
+//   pid: The process identifier
+//   outApp: Pointer to receive the AppInfo structure (caller must free using FreeAppInfo)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, BRIDGE_ERROR_NOT_FOUND if no application bundle owns the process,
+//          error code otherwise
+int GetAppInfoForPID(int pid, AppInfo **outApp, char **outError);
+
+// Get the frontmost application, the one receiving key events
+//
+// Parameters:
+//   outApp: Pointer to receive the AppInfo structure (caller must free using FreeAppInfo)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, BRIDGE_ERROR_NOT_FOUND if there is no frontmost application,
+//          error code otherwise
+int GetFrontmostApplication(AppInfo **outApp, char **outError);
+
+// Check whether an application with a bundle identifier is running
+//
+// Parameters:
+//   bundleID: The bundle identifier (e.g., "com.apple.Safari")
+//   outIsRunning: Pointer to receive 1 if at least one instance is running, 0 otherwise
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int AppIsRunning(const char *bundleID, int *outIsRunning, char **outError);
+
+// Bring a running application to the foreground, with all of its windows
+//
+// Parameters:
+//   bundleID: The bundle identifier (e.g., "com.apple.Safari")
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, BRIDGE_ERROR_NOT_FOUND if the application is not running,
+//          error code otherwise
+int ActivateApplication(const char *bundleID, char **outError);
+
+// Ask every running instance of an application to quit
+//
+// Parameters:
+//   bundleID: The bundle identifier (e.g., "com.apple.Safari")
+//   force: 1 to terminate immediately (forceTerminate), 0 to ask the app to quit (terminate)
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK if every instance accepted the request, BRIDGE_ERROR_NOT_FOUND if the
+//          application is not running, error code otherwise
+int TerminateApplication(const char *bundleID, int force, char **outError);
+
+// Render an application's icon as PNG data
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/Applications/TextEdit.app")
+//   size: Width and height of the rendered icon in pixels
+//   outData: Pointer to receive the PNG bytes (caller must free)
+//   outLength: Pointer to receive the number of bytes returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetAppIconPNG(const char *appPath, int size, unsigned char **outData, int *outLength, char **outError);
+
+// Free a single AppInfo structure allocated by bridge functions
+//
+// Parameters:
+//   app: The AppInfo structure to free
+void FreeAppInfo(AppInfo *app);
+
 // Free an array of AppInfo structures allocated by bridge functions
 //
 // Parameters:
@@ -156,6 +734,29 @@ void FreeAppInfoArray(AppInfo **apps, int count);
 // Returns: BRIDGE_OK on success, error code otherwise
 int GetSupportedDocumentTypesForApp(const char *appPath, DocumentType ***outDocTypes, int *outCount, char **outError);
 
+// Get the URL schemes an application declares in CFBundleURLTypes
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/Applications/Safari.app")
+//   outSchemes: Pointer to receive array of scheme strings (caller must free using FreeCStringArray)
+//   outCount: Pointer to receive count of schemes returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetSupportedSchemesForApp(const char *appPath, char ***outSchemes, int *outCount, char **outError);
+
+// Get the UTIs an application declares in UTExportedTypeDeclarations or UTImportedTypeDeclarations
+//
+// Parameters:
+//   appPath: Full path to the application bundle (e.g., "/Applications/Pages.app")
+//   imported: Non-zero to read UTImportedTypeDeclarations, zero for UTExportedTypeDeclarations
+//   outUTIs: Pointer to receive array of UTI strings (caller must free using FreeCStringArray)
+//   outCount: Pointer to receive count of UTIs returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int GetDeclaredUTIsForApp(const char *appPath, int imported, char ***outUTIs, int *outCount, char **outError);
+
 // Free an array of DocumentType structures allocated by bridge functions
 //
 // Parameters:
@@ -163,4 +764,21 @@ int GetSupportedDocumentTypesForApp(const char *appPath, DocumentType ***outDocT
 //   count: The number of DocumentType structures in the array
 void FreeDocumentTypeArray(DocumentType **docTypes, int count);
 
+// List the user's handler preferences stored by LaunchServices (LSHandlers)
+//
+// Parameters:
+//   outPrefs: Pointer to receive array of HandlerPreference structures (caller must free using FreeHandlerPreferenceArray)
+//   outCount: Pointer to receive count of preferences returned
+//   outError: Pointer to receive error message if any (caller must free)
+//
+// Returns: BRIDGE_OK on success, error code otherwise
+int ListHandlerPreferences(HandlerPreference ***outPrefs, int *outCount, char **outError);
+
+// Free an array of HandlerPreference structures allocated by bridge functions
+//
+// Parameters:
+//   prefs: The array of HandlerPreference structures to free
+//   count: The number of HandlerPreference structures in the array
+void FreeHandlerPreferenceArray(HandlerPreference **prefs, int count);
+
 #endif // MACOS_APPHANDLERS_BRIDGE_H
@@ -1,6 +1,10 @@
+//go:build darwin
+
 #import <Foundation/Foundation.h>
 #import <AppKit/AppKit.h>
 #import <UniformTypeIdentifiers/UniformTypeIdentifiers.h>
+#import <CoreServices/CoreServices.h>
+#import <Security/Security.h>
 #import "bridge.h"
 #import <string.h>
 
@@ -24,12 +28,147 @@ static void SetError(char** outError, NSString* message) {
     }
 }
 
+// Helper function to extract the OSStatus behind an NSError, 0 if it did not come from one
+static int OSStatusForError(NSError* error) {
+    while (error) {
+        if ([error.domain isEqualToString:NSOSStatusErrorDomain]) {
+            return (int)error.code;
+        }
+        error = error.userInfo[NSUnderlyingErrorKey];
+    }
+    return 0;
+}
+
+// Helper function to check whether an NSError means the user cancelled the confirmation prompt
+static BOOL IsUserCancelledError(NSError* error) {
+    while (error) {
+        if ([error.domain isEqualToString:NSCocoaErrorDomain] && error.code == NSUserCancelledError) {
+            return YES;
+        }
+        if ([error.domain isEqualToString:NSOSStatusErrorDomain] && error.code == userCanceledErr) {
+            return YES;
+        }
+        error = error.userInfo[NSUnderlyingErrorKey];
+    }
+    return NO;
+}
+
 // Helper function to get absolute path from URL
 static char* URLToPath(NSURL* url) {
     if (!url) return NULL;
     return NSStringToCString([url path]);
 }
 
+// Helper function to get an application's display name from its bundle
+static NSString* AppNameForBundle(NSBundle* bundle, NSString* path) {
+    NSString* appName = [bundle objectForInfoDictionaryKey:@"CFBundleName"];
+
+    // Fallback to display name if CFBundleName is not available
+    if (!appName) {
+        appName = [bundle objectForInfoDictionaryKey:@"CFBundleDisplayName"];
+    }
+
+    // Fallback to filename without .app extension
+    if (!appName) {
+        appName = [[path lastPathComponent] stringByDeletingPathExtension];
+    }
+
+    return appName;
+}
+
+// Helper function to read a string value from a bundle's Info.plist, empty if absent or not a string
+static NSString* BundleInfoString(NSBundle* bundle, NSString* key) {
+    id value = [bundle objectForInfoDictionaryKey:key];
+    return [value isKindOfClass:[NSString class]] ? (NSString*)value : @"";
+}
+
+// Helper function to create an AppInfo structure for an application URL (caller must free)
+static AppInfo* NewAppInfoForURL(NSURL* appURL) {
+    AppInfo* info = (AppInfo*)calloc(1, sizeof(AppInfo));
+    if (!info) return NULL;
+
+    NSString* path = [appURL path];
+    NSBundle* bundle = [NSBundle bundleWithURL:appURL];
+    NSString* appName = bundle ? AppNameForBundle(bundle, path) : [[path lastPathComponent] stringByDeletingPathExtension];
+
+    info->name = NSStringToCString(appName ?: @"");
+    info->path = NSStringToCString(path);
+    info->bundleID = NSStringToCString([bundle bundleIdentifier] ?: @"");
+    info->version = NSStringToCString(BundleInfoString(bundle, @"CFBundleShortVersionString"));
+    info->buildVersion = NSStringToCString(BundleInfoString(bundle, @"CFBundleVersion"));
+    info->category = NSStringToCString(BundleInfoString(bundle, @"LSApplicationCategoryType"));
+    return info;
+}
+
+// Helper function to convert application URLs to an array of AppInfo structures
+static int AppInfoArrayForURLs(NSArray<NSURL*>* appURLs, AppInfo*** outApps, int* outCount, char** outError) {
+    *outApps = NULL;
+    *outCount = 0;
+
+    int count = (int)[appURLs count];
+    if (count == 0) {
+        return BRIDGE_OK;
+    }
+
+    AppInfo** apps = (AppInfo**)calloc(count, sizeof(AppInfo*));
+    if (!apps) {
+        SetError(outError, @"Memory allocation failed");
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    for (int i = 0; i < count; i++) {
+        apps[i] = NewAppInfoForURL(appURLs[i]);
+        if (!apps[i]) {
+            FreeAppInfoArray(apps, i);
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+    }
+
+    *outApps = apps;
+    *outCount = count;
+    return BRIDGE_OK;
+}
+
+// Helper function to resolve bundle identifiers to application URLs, skipping uninstalled ones
+static NSArray<NSURL*>* AppURLsForBundleIDs(NSArray* bundleIDs) {
+    NSMutableArray<NSURL*>* appURLs = [NSMutableArray array];
+    NSMutableSet<NSString*>* seenPaths = [NSMutableSet set];
+    NSWorkspace* workspace = [NSWorkspace sharedWorkspace];
+
+    for (id bundleID in bundleIDs) {
+        if (![bundleID isKindOfClass:[NSString class]]) {
+            continue;
+        }
+
+        NSURL* appURL = [workspace URLForApplicationWithBundleIdentifier:(NSString*)bundleID];
+        if (!appURL || [seenPaths containsObject:[appURL path]]) {
+            continue;
+        }
+
+        [seenPaths addObject:[appURL path]];
+        [appURLs addObject:appURL];
+    }
+
+    return appURLs;
+}
+
+// Helper function to list the applications registered for a content type with a given role
+static NSArray<NSURL*>* AppURLsForContentTypeRole(UTType* utType, LSRolesMask role) {
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+    CFArrayRef bundleIDs = LSCopyAllRoleHandlersForContentType((__bridge CFStringRef)[utType identifier], role);
+#pragma clang diagnostic pop
+
+    if (!bundleIDs) {
+        return @[];
+    }
+
+    NSArray<NSURL*>* appURLs = AppURLsForBundleIDs((__bridge NSArray*)bundleIDs);
+    CFRelease(bundleIDs);
+    return appURLs;
+}
+
 // Get the default application for a UTI
 int GetDefaultAppForUTI(const char* uti, char** outAppPath, char** outError) {
     @autoreleasepool {
@@ -73,6 +212,81 @@ int GetDefaultAppForUTI(const char* uti, char** outAppPath, char** outError) {
     }
 }
 
+// Get the default applications for several UTIs in one call
+int GetDefaultAppsForUTIs(const char** utis, int utiCount, char*** outAppPaths, char** outError) {
+    @autoreleasepool {
+        if ((!utis && utiCount > 0) || utiCount < 0 || !outAppPaths) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outAppPaths = NULL;
+
+        if (utiCount == 0) {
+            return BRIDGE_OK;
+        }
+
+        char** appPaths = (char**)calloc(utiCount, sizeof(char*));
+        if (!appPaths) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        NSWorkspace* workspace = [NSWorkspace sharedWorkspace];
+
+        for (int i = 0; i < utiCount; i++) {
+            NSString* utiString = utis[i] ? [NSString stringWithUTF8String:utis[i]] : nil;
+            UTType* utType = utiString ? [UTType typeWithIdentifier:utiString] : nil;
+            if (!utType) {
+                // Unresolvable UTIs are left as NULL rather than failing the batch
+                continue;
+            }
+
+            NSURL* appURL = [workspace URLForApplicationToOpenContentType:utType];
+            if (appURL) {
+                appPaths[i] = URLToPath(appURL);
+            }
+        }
+
+        *outAppPaths = appPaths;
+        return BRIDGE_OK;
+    }
+}
+
+// Get the default application for a UTI along with its metadata
+int GetDefaultAppInfoForUTI(const char* uti, AppInfo** outApp, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outApp) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outApp = NULL;
+
+        char* appPath = NULL;
+        int code = GetDefaultAppForUTI(uti, &appPath, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        NSString* appPathString = appPath ? [NSString stringWithUTF8String:appPath] : nil;
+        FreeCString(appPath);
+        if (!appPathString) {
+            SetError(outError, @"Failed to convert default app path");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        *outApp = NewAppInfoForURL([NSURL fileURLWithPath:appPathString]);
+
+        if (!*outApp) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
 // Get the default application for a URL scheme
 int GetDefaultAppForScheme(const char* scheme, char** outAppPath, char** outError) {
     @autoreleasepool {
@@ -112,9 +326,45 @@ int GetDefaultAppForScheme(const char* scheme, char** outAppPath, char** outErro
     }
 }
 
+// Get the default application for a URL scheme along with its metadata
+int GetDefaultAppInfoForScheme(const char* scheme, AppInfo** outApp, char** outError) {
+    @autoreleasepool {
+        if (!scheme || !outApp) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_SCHEME;
+        }
+
+        *outApp = NULL;
+
+        char* appPath = NULL;
+        int code = GetDefaultAppForScheme(scheme, &appPath, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        NSString* appPathString = appPath ? [NSString stringWithUTF8String:appPath] : nil;
+        FreeCString(appPath);
+        if (!appPathString) {
+            SetError(outError, @"Failed to convert default app path");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        *outApp = NewAppInfoForURL([NSURL fileURLWithPath:appPathString]);
+
+        if (!*outApp) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
 // Set the default application for a UTI
-int SetDefaultForUTI(const char* appPath, const char* uti, char** outError) {
+int SetDefaultForUTI(const char* appPath, const char* uti, int* outStatus, char** outError) {
     @autoreleasepool {
+        if (outStatus) *outStatus = 0;
+
         if (!appPath || !uti) {
             SetError(outError, @"Invalid parameters");
             return BRIDGE_ERROR_INVALID_APP;
@@ -148,6 +398,12 @@ int SetDefaultForUTI(const char* appPath, const char* uti, char** outError) {
             return BRIDGE_ERROR_INVALID_UTI;
         }
 
+        // LaunchServices accepts dynamic types but the setting never takes effect
+        if ([utType isDynamic]) {
+            SetError(outError, [NSString stringWithFormat:@"Cannot set a default for dynamic UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
         // Use semaphore to wait for async completion
         dispatch_semaphore_t semaphore = dispatch_semaphore_create(0);
         __block int resultCode = BRIDGE_OK;
@@ -159,7 +415,7 @@ int SetDefaultForUTI(const char* appPath, const char* uti, char** outError) {
                          completionHandler:^(NSError * _Nullable error) {
             if (error) {
                 resultError = [error retain];
-                if ([error.domain isEqualToString:NSCocoaErrorDomain] && error.code == NSUserCancelledError) {
+                if (IsUserCancelledError(error)) {
                     resultCode = BRIDGE_ERROR_USER_DECLINED;
                 } else {
                     resultCode = BRIDGE_ERROR_SYSTEM;
@@ -183,6 +439,7 @@ int SetDefaultForUTI(const char* appPath, const char* uti, char** outError) {
                                        [resultError domain],
                                        (long)[resultError code]];
             SetError(outError, detailedError);
+            if (outStatus) *outStatus = OSStatusForError(resultError);
             [resultError release];
             return resultCode;
         }
@@ -191,9 +448,177 @@ int SetDefaultForUTI(const char* appPath, const char* uti, char** outError) {
     }
 }
 
+// Set the default application for a UTI in a single role
+int SetDefaultForUTIWithRole(const char* appPath, const char* uti, unsigned int roleMask, int* outStatus, char** outError) {
+    @autoreleasepool {
+        if (outStatus) *outStatus = 0;
+
+        if (!appPath || !uti) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSString* appPathString = [NSString stringWithUTF8String:appPath];
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+
+        if (!appPathString || !utiString) {
+            SetError(outError, @"Invalid UTF-8 in parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSBundle* bundle = [NSBundle bundleWithPath:appPathString];
+        NSString* bundleID = [bundle bundleIdentifier];
+        if (!bundleID) {
+            SetError(outError, [NSString stringWithFormat:@"Application not found or has no bundle identifier: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        // LaunchServices accepts dynamic types but the setting never takes effect
+        if ([utType isDynamic]) {
+            SetError(outError, [NSString stringWithFormat:@"Cannot set a default for dynamic UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+        OSStatus status = LSSetDefaultRoleHandlerForContentType((__bridge CFStringRef)[utType identifier],
+                                                                (LSRolesMask)roleMask,
+                                                                (__bridge CFStringRef)bundleID);
+#pragma clang diagnostic pop
+
+        if (status != noErr) {
+            SetError(outError, [NSString stringWithFormat:@"Failed to set role handler for %s (OSStatus %d)", uti, (int)status]);
+            if (outStatus) *outStatus = (int)status;
+            return status == userCanceledErr ? BRIDGE_ERROR_USER_DECLINED : BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// Get the default application for a UTI in a single role
+int GetDefaultAppForUTIWithRole(const char* uti, unsigned int roleMask, char** outAppPath, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outAppPath) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outAppPath = NULL;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+        NSString* bundleID = CFBridgingRelease(LSCopyDefaultRoleHandlerForContentType((__bridge CFStringRef)[utType identifier],
+                                                                                      (LSRolesMask)roleMask));
+#pragma clang diagnostic pop
+
+        NSURL* appURL = bundleID ? [[NSWorkspace sharedWorkspace] URLForApplicationWithBundleIdentifier:bundleID] : nil;
+        if (!appURL) {
+            SetError(outError, [NSString stringWithFormat:@"No default application found for UTI: %s", uti]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        *outAppPath = URLToPath(appURL);
+        return BRIDGE_OK;
+    }
+}
+
+// Helper function to remove the LSHandlers entries whose key matches a value, ignoring case
+static int RemoveHandlerOverrides(NSString* key, NSString* value, char** outError) {
+    CFStringRef domain = CFSTR("com.apple.LaunchServices/com.apple.launchservices.secure");
+    NSArray* handlers = CFBridgingRelease(CFPreferencesCopyAppValue(CFSTR("LSHandlers"), domain));
+    if (!handlers || ![handlers isKindOfClass:[NSArray class]]) {
+        // Nothing has been customized, so the system default is already in effect
+        return BRIDGE_OK;
+    }
+
+    NSMutableArray* remaining = [NSMutableArray arrayWithCapacity:[handlers count]];
+    for (id handler in handlers) {
+        id entryValue = [handler isKindOfClass:[NSDictionary class]] ? ((NSDictionary*)handler)[key] : nil;
+        if ([entryValue isKindOfClass:[NSString class]] &&
+            [(NSString*)entryValue caseInsensitiveCompare:value] == NSOrderedSame) {
+            continue;
+        }
+        [remaining addObject:handler];
+    }
+
+    if ([remaining count] == [handlers count]) {
+        return BRIDGE_OK;
+    }
+
+    CFPreferencesSetAppValue(CFSTR("LSHandlers"), (__bridge CFArrayRef)remaining, domain);
+    if (!CFPreferencesAppSynchronize(domain)) {
+        SetError(outError, @"Failed to write LaunchServices preferences");
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    return BRIDGE_OK;
+}
+
+// Remove the user's handler overrides for a UTI from the LaunchServices preferences
+int ResetDefaultForUTI(const char* uti, char** outError) {
+    @autoreleasepool {
+        if (!uti) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        if (![UTType typeWithIdentifier:utiString]) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        return RemoveHandlerOverrides(@"LSHandlerContentType", utiString, outError);
+    }
+}
+
+// Remove the user's handler overrides for a URL scheme from the LaunchServices preferences
+int ResetDefaultForScheme(const char* scheme, char** outError) {
+    @autoreleasepool {
+        if (!scheme || strlen(scheme) == 0) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_SCHEME;
+        }
+
+        NSString* schemeString = [NSString stringWithUTF8String:scheme];
+        if (!schemeString) {
+            SetError(outError, @"Invalid UTF-8 in scheme string");
+            return BRIDGE_ERROR_INVALID_SCHEME;
+        }
+
+        return RemoveHandlerOverrides(@"LSHandlerURLScheme", schemeString, outError);
+    }
+}
+
 // Set the default application for a URL scheme
-int SetDefaultForScheme(const char* appPath, const char* scheme, char** outError) {
+int SetDefaultForScheme(const char* appPath, const char* scheme, int* outStatus, char** outError) {
     @autoreleasepool {
+        if (outStatus) *outStatus = 0;
+
         if (!appPath || !scheme) {
             SetError(outError, @"Invalid parameters");
             return BRIDGE_ERROR_INVALID_APP;
@@ -231,7 +656,7 @@ int SetDefaultForScheme(const char* appPath, const char* scheme, char** outError
                          completionHandler:^(NSError * _Nullable error) {
             if (error) {
                 resultError = [error retain];
-                if ([error.domain isEqualToString:NSCocoaErrorDomain] && error.code == NSUserCancelledError) {
+                if (IsUserCancelledError(error)) {
                     resultCode = BRIDGE_ERROR_USER_DECLINED;
                 } else {
                     resultCode = BRIDGE_ERROR_SYSTEM;
@@ -255,6 +680,7 @@ int SetDefaultForScheme(const char* appPath, const char* scheme, char** outError
                                        [resultError domain],
                                        (long)[resultError code]];
             SetError(outError, detailedError);
+            if (outStatus) *outStatus = OSStatusForError(resultError);
             [resultError release];
             return resultCode;
         }
@@ -263,6 +689,39 @@ int SetDefaultForScheme(const char* appPath, const char* scheme, char** outError
     }
 }
 
+// Register an application bundle with LaunchServices
+int RegisterApplication(const char* appPath, int* outStatus, char** outError) {
+    @autoreleasepool {
+        if (outStatus) *outStatus = 0;
+
+        if (!appPath) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSString* appPathString = [NSString stringWithUTF8String:appPath];
+        if (!appPathString) {
+            SetError(outError, @"Invalid UTF-8 in application path");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        if (![[NSFileManager defaultManager] fileExistsAtPath:appPathString]) {
+            SetError(outError, [NSString stringWithFormat:@"Application not found: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSURL* appURL = [NSURL fileURLWithPath:appPathString];
+        OSStatus status = LSRegisterURL((CFURLRef)appURL, true);
+        if (status != noErr) {
+            SetError(outError, [NSString stringWithFormat:@"Failed to register %s (OSStatus %d)", appPath, (int)status]);
+            if (outStatus) *outStatus = (int)status;
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
 // Resolve file extension to UTI(s)
 int ResolveUTIsForExtension(const char* extension, char*** outUTIs, int* outCount, char** outError) {
     @autoreleasepool {
@@ -294,6 +753,7 @@ int ResolveUTIsForExtension(const char* extension, char*** outUTIs, int* outCoun
         *outUTIs = (char**)malloc(sizeof(char*) * (*outCount));
 
         if (!*outUTIs) {
+            *outCount = 0;
             SetError(outError, @"Memory allocation failed");
             return BRIDGE_ERROR_SYSTEM;
         }
@@ -307,22 +767,72 @@ int ResolveUTIsForExtension(const char* extension, char*** outUTIs, int* outCoun
     }
 }
 
-// Get file extensions for a UTI
-int GetExtensionsForUTI(const char* uti, char*** outExtensions, int* outCount, char** outError) {
+// Resolve MIME type to UTI(s)
+int ResolveUTIsForMIMEType(const char* mimeType, char*** outUTIs, int* outCount, char** outError) {
     @autoreleasepool {
-        if (!uti || !outExtensions || !outCount) {
+        if (!mimeType || !outUTIs || !outCount) {
             SetError(outError, @"Invalid parameters");
             return BRIDGE_ERROR_INVALID_UTI;
         }
 
-        *outExtensions = NULL;
+        *outUTIs = NULL;
         *outCount = 0;
 
-        if (strlen(uti) == 0) {
-            SetError(outError, @"UTI cannot be empty");
+        NSString* mimeString = [NSString stringWithUTF8String:mimeType];
+        if (!mimeString) {
+            SetError(outError, @"Invalid UTF-8 in MIME type string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        NSArray<UTType*>* types = [UTType typesWithTag:mimeString
+                                              tagClass:UTTagClassMIMEType
+                                     conformingToType:nil];
+
+        NSMutableArray<NSString*>* identifiers = [NSMutableArray array];
+        for (UTType* type in types) {
+            // Dynamic types are synthesized for unknown tags, so they are not real matches
+            if (![type isDynamic]) {
+                [identifiers addObject:[type identifier]];
+            }
+        }
+
+        int count = (int)[identifiers count];
+        if (count == 0) {
+            // Not an error - nothing is registered for this MIME type
+            return BRIDGE_OK;
+        }
+
+        char** utis = (char**)calloc(count, sizeof(char*));
+        if (!utis) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < count; i++) {
+            utis[i] = NSStringToCString(identifiers[i]);
+            if (!utis[i]) {
+                FreeCStringArray(utis, i);
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+        }
+
+        *outUTIs = utis;
+        *outCount = count;
+        return BRIDGE_OK;
+    }
+}
+
+// Get the preferred file extension for a UTI
+int GetPreferredExtensionForUTI(const char* uti, char** outExtension, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outExtension) {
+            SetError(outError, @"Invalid parameters");
             return BRIDGE_ERROR_INVALID_UTI;
         }
 
+        *outExtension = NULL;
+
         NSString* utiString = [NSString stringWithUTF8String:uti];
         if (!utiString) {
             SetError(outError, @"Invalid UTF-8 in UTI string");
@@ -331,41 +841,178 @@ int GetExtensionsForUTI(const char* uti, char*** outExtensions, int* outCount, c
 
         UTType* utType = [UTType typeWithIdentifier:utiString];
         if (!utType) {
-            // Not an error - UTI might not exist
-            return BRIDGE_OK;
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        NSString* extension = [utType preferredFilenameExtension];
+        if (!extension || [extension length] == 0) {
+            SetError(outError, [NSString stringWithFormat:@"UTI has no filename extension: %s", uti]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        *outExtension = NSStringToCString(extension);
+        if (!*outExtension) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// Helper function to copy the strings of one tag class into a C string array
+static BOOL CopyTagStrings(NSDictionary* tags, NSString* tagClass, char*** outArray, int* outCount) {
+    *outArray = NULL;
+    *outCount = 0;
+
+    NSArray* values = tags[tagClass];
+    if (![values isKindOfClass:[NSArray class]] || [values count] == 0) {
+        return YES;
+    }
+
+    char** array = (char**)calloc([values count], sizeof(char*));
+    if (!array) {
+        return NO;
+    }
+
+    int count = 0;
+    for (id value in values) {
+        if (![value isKindOfClass:[NSString class]]) {
+            continue;
+        }
+        array[count] = NSStringToCString(value);
+        if (!array[count]) {
+            FreeCStringArray(array, count);
+            return NO;
+        }
+        count++;
+    }
+
+    *outArray = array;
+    *outCount = count;
+    return YES;
+}
+
+// Get every tag of a UTI, grouped by tag class
+int GetTagSpecificationForUTI(const char* uti, TagSpecification** outSpec, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outSpec) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outSpec = NULL;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
         }
 
-        NSMutableSet<NSString*>* extensionsSet = [NSMutableSet set];
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
 
-        // Get preferred filename extension
-        NSString* preferredExt = [utType preferredFilenameExtension];
-        if (preferredExt && [preferredExt length] > 0) {
-            [extensionsSet addObject:preferredExt];
+        TagSpecification* spec = (TagSpecification*)calloc(1, sizeof(TagSpecification));
+        if (!spec) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
         }
 
-        // Get all filename extensions for this UTI
         NSDictionary* tags = [utType tags];
-        if (tags) {
-            NSArray* fileExtensions = tags[UTTagClassFilenameExtension];
-            if (fileExtensions && [fileExtensions isKindOfClass:[NSArray class]]) {
-                for (id ext in fileExtensions) {
-                    if ([ext isKindOfClass:[NSString class]] && [(NSString*)ext length] > 0) {
-                        [extensionsSet addObject:(NSString*)ext];
-                    }
+        if (!CopyTagStrings(tags, UTTagClassFilenameExtension, &spec->extensions, &spec->extensionCount) ||
+            !CopyTagStrings(tags, UTTagClassMIMEType, &spec->mimeTypes, &spec->mimeTypeCount) ||
+            !CopyTagStrings(tags, @"com.apple.nspboard-type", &spec->pasteboardTypes, &spec->pasteboardTypeCount) ||
+            !CopyTagStrings(tags, @"com.apple.ostype", &spec->osTypes, &spec->osTypeCount)) {
+            FreeTagSpecification(spec);
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        *outSpec = spec;
+        return BRIDGE_OK;
+    }
+}
+
+// Free a TagSpecification structure
+void FreeTagSpecification(TagSpecification* spec) {
+    if (spec) {
+        FreeCStringArray(spec->extensions, spec->extensionCount);
+        FreeCStringArray(spec->mimeTypes, spec->mimeTypeCount);
+        FreeCStringArray(spec->pasteboardTypes, spec->pasteboardTypeCount);
+        FreeCStringArray(spec->osTypes, spec->osTypeCount);
+        free(spec);
+    }
+}
+
+// Helper function to get the sorted, deduplicated filename extensions of a type
+static NSArray<NSString*>* SortedExtensionsForType(UTType* utType) {
+    NSMutableSet<NSString*>* extensionsSet = [NSMutableSet set];
+
+    // Get preferred filename extension
+    NSString* preferredExt = [utType preferredFilenameExtension];
+    if (preferredExt && [preferredExt length] > 0) {
+        [extensionsSet addObject:preferredExt];
+    }
+
+    // Get all filename extensions for this UTI
+    NSDictionary* tags = [utType tags];
+    if (tags) {
+        NSArray* fileExtensions = tags[UTTagClassFilenameExtension];
+        if (fileExtensions && [fileExtensions isKindOfClass:[NSArray class]]) {
+            for (id ext in fileExtensions) {
+                if ([ext isKindOfClass:[NSString class]] && [(NSString*)ext length] > 0) {
+                    [extensionsSet addObject:(NSString*)ext];
                 }
             }
         }
+    }
+
+    return [[extensionsSet allObjects] sortedArrayUsingSelector:@selector(compare:)];
+}
+
+// Get file extensions for a UTI
+int GetExtensionsForUTI(const char* uti, char*** outExtensions, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outExtensions || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outExtensions = NULL;
+        *outCount = 0;
+
+        if (strlen(uti) == 0) {
+            SetError(outError, @"UTI cannot be empty");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            // Not an error - UTI might not exist
+            return BRIDGE_OK;
+        }
 
-        if ([extensionsSet count] == 0) {
+        NSArray<NSString*>* sortedExtensions = SortedExtensionsForType(utType);
+        if ([sortedExtensions count] == 0) {
             // Not an error - UTI might not have any file extensions
             return BRIDGE_OK;
         }
 
-        NSArray* sortedExtensions = [[extensionsSet allObjects] sortedArrayUsingSelector:@selector(compare:)];
         *outCount = (int)[sortedExtensions count];
 
         *outExtensions = (char**)malloc(sizeof(char*) * (*outCount));
         if (!*outExtensions) {
+            *outCount = 0;
             SetError(outError, @"Memory allocation failed");
             return BRIDGE_ERROR_SYSTEM;
         }
@@ -390,256 +1037,1747 @@ int GetExtensionsForUTI(const char* uti, char*** outExtensions, int* outCount, c
     }
 }
 
-// List all applications that can open a UTI
-int ListAppsForUTI(const char* uti, char*** outAppPaths, int* outCount, char** outError) {
+// Get file extensions for several UTIs in one call
+int GetExtensionsForUTIs(const char** utis, int utiCount, char*** outExtensions, int** outCounts, int* outTotal, char** outError) {
     @autoreleasepool {
-        if (!uti || !outAppPaths || !outCount) {
+        if ((!utis && utiCount > 0) || utiCount < 0 || !outExtensions || !outCounts || !outTotal) {
             SetError(outError, @"Invalid parameters");
             return BRIDGE_ERROR_INVALID_UTI;
         }
 
-        *outAppPaths = NULL;
-        *outCount = 0;
+        *outExtensions = NULL;
+        *outCounts = NULL;
+        *outTotal = 0;
 
-        NSString* utiString = [NSString stringWithUTF8String:uti];
-        if (!utiString) {
-            SetError(outError, @"Invalid UTF-8 in UTI string");
-            return BRIDGE_ERROR_INVALID_UTI;
+        if (utiCount == 0) {
+            return BRIDGE_OK;
         }
 
-        UTType* utType = [UTType typeWithIdentifier:utiString];
-        if (!utType) {
-            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
-            return BRIDGE_ERROR_INVALID_UTI;
+        int* counts = (int*)calloc(utiCount, sizeof(int));
+        if (!counts) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
         }
 
-        NSWorkspace* workspace = [NSWorkspace sharedWorkspace];
-        NSArray<NSURL*>* appURLs = [workspace URLsForApplicationsToOpenContentType:utType];
+        NSMutableArray<NSString*>* allExtensions = [NSMutableArray array];
 
-        if (!appURLs || [appURLs count] == 0) {
-            // Return empty list, not an error
-            return BRIDGE_OK;
+        for (int i = 0; i < utiCount; i++) {
+            NSString* utiString = utis[i] ? [NSString stringWithUTF8String:utis[i]] : nil;
+            UTType* utType = [utiString length] > 0 ? [UTType typeWithIdentifier:utiString] : nil;
+            if (!utType) {
+                // Unresolvable UTIs get no extensions rather than failing the batch
+                continue;
+            }
+
+            NSArray<NSString*>* extensions = SortedExtensionsForType(utType);
+            counts[i] = (int)[extensions count];
+            [allExtensions addObjectsFromArray:extensions];
         }
 
-        *outCount = (int)[appURLs count];
-        *outAppPaths = (char**)malloc(sizeof(char*) * (*outCount));
+        int total = (int)[allExtensions count];
+        if (total == 0) {
+            *outCounts = counts;
+            return BRIDGE_OK;
+        }
 
-        if (!*outAppPaths) {
+        char** extensions = (char**)calloc(total, sizeof(char*));
+        if (!extensions) {
+            free(counts);
             SetError(outError, @"Memory allocation failed");
             return BRIDGE_ERROR_SYSTEM;
         }
 
-        for (int i = 0; i < *outCount; i++) {
-            NSURL* appURL = appURLs[i];
-            (*outAppPaths)[i] = URLToPath(appURL);
+        for (int i = 0; i < total; i++) {
+            extensions[i] = NSStringToCString(allExtensions[i]);
+            if (!extensions[i]) {
+                FreeCStringArray(extensions, i);
+                free(counts);
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
         }
 
+        *outExtensions = extensions;
+        *outCounts = counts;
+        *outTotal = total;
         return BRIDGE_OK;
     }
 }
 
-// List all applications that can handle a URL scheme
-int ListAppsForScheme(const char* scheme, char*** outAppPaths, int* outCount, char** outError) {
+// Get MIME types for a UTI
+int GetMIMETypesForUTI(const char* uti, char*** outMIMETypes, int* outCount, char** outError) {
     @autoreleasepool {
-        if (!scheme || !outAppPaths || !outCount) {
+        if (!uti || !outMIMETypes || !outCount) {
             SetError(outError, @"Invalid parameters");
-            return BRIDGE_ERROR_INVALID_SCHEME;
+            return BRIDGE_ERROR_INVALID_UTI;
         }
 
-        *outAppPaths = NULL;
+        *outMIMETypes = NULL;
         *outCount = 0;
 
-        NSString* schemeString = [NSString stringWithUTF8String:scheme];
-        if (!schemeString) {
-            SetError(outError, @"Invalid UTF-8 in scheme string");
-            return BRIDGE_ERROR_INVALID_SCHEME;
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString || [utiString length] == 0) {
+            SetError(outError, @"Invalid UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
         }
 
-        NSString* urlString = [NSString stringWithFormat:@"%@://", schemeString];
-        NSURL* url = [NSURL URLWithString:urlString];
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            // Not an error - UTI might not exist
+            return BRIDGE_OK;
+        }
 
-        if (!url) {
-            SetError(outError, [NSString stringWithFormat:@"Invalid URL scheme: %s", scheme]);
-            return BRIDGE_ERROR_INVALID_SCHEME;
+        // Preferred MIME type first, then any others in sorted order
+        NSMutableOrderedSet<NSString*>* mimeTypesSet = [NSMutableOrderedSet orderedSet];
+
+        NSString* preferredMIMEType = [utType preferredMIMEType];
+        if (preferredMIMEType && [preferredMIMEType length] > 0) {
+            [mimeTypesSet addObject:[preferredMIMEType lowercaseString]];
         }
 
-        NSWorkspace* workspace = [NSWorkspace sharedWorkspace];
-        NSArray<NSURL*>* appURLs = [workspace URLsForApplicationsToOpenURL:url];
+        NSArray* mimeTypes = [utType tags][UTTagClassMIMEType];
+        if ([mimeTypes isKindOfClass:[NSArray class]]) {
+            for (id mimeType in [mimeTypes sortedArrayUsingSelector:@selector(compare:)]) {
+                if ([mimeType isKindOfClass:[NSString class]] && [(NSString*)mimeType length] > 0) {
+                    [mimeTypesSet addObject:[(NSString*)mimeType lowercaseString]];
+                }
+            }
+        }
 
-        if (!appURLs || [appURLs count] == 0) {
-            // Return empty list, not an error
+        if ([mimeTypesSet count] == 0) {
+            // Not an error - many UTIs have no MIME type
             return BRIDGE_OK;
         }
 
-        *outCount = (int)[appURLs count];
-        *outAppPaths = (char**)malloc(sizeof(char*) * (*outCount));
+        int count = (int)[mimeTypesSet count];
 
-        if (!*outAppPaths) {
+        char** result = (char**)calloc(count, sizeof(char*));
+        if (!result) {
             SetError(outError, @"Memory allocation failed");
             return BRIDGE_ERROR_SYSTEM;
         }
 
-        for (int i = 0; i < *outCount; i++) {
-            NSURL* appURL = appURLs[i];
-            (*outAppPaths)[i] = URLToPath(appURL);
+        for (int i = 0; i < count; i++) {
+            result[i] = NSStringToCString(mimeTypesSet[i]);
+            if (!result[i]) {
+                FreeCStringArray(result, i);
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
         }
 
+        *outMIMETypes = result;
+        *outCount = count;
         return BRIDGE_OK;
     }
 }
 
-// List all installed applications on the system
-int ListAllApplications(AppInfo*** outApps, int* outCount, char** outError) {
+// Get the preferred UTI for a file extension
+int GetPreferredUTIForExtension(const char* extension, char** outUTI, int* outIsDynamic, char** outError) {
     @autoreleasepool {
-        if (!outApps || !outCount) {
+        if (!extension || !outUTI || !outIsDynamic) {
             SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outUTI = NULL;
+        *outIsDynamic = 0;
+
+        NSString* extString = [NSString stringWithUTF8String:extension];
+        if (!extString) {
+            SetError(outError, @"Invalid UTF-8 in extension string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        // Prefers declared types, falls back to a dynamic type
+        UTType* utType = [UTType typeWithFilenameExtension:extString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"No UTI found for extension: %s", extension]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        *outUTI = NSStringToCString([utType identifier]);
+        if (!*outUTI) {
+            SetError(outError, @"Memory allocation failed");
             return BRIDGE_ERROR_SYSTEM;
         }
 
-        *outApps = NULL;
-        *outCount = 0;
+        *outIsDynamic = [utType isDynamic] ? 1 : 0;
+        return BRIDGE_OK;
+    }
+}
 
-        NSMutableArray<NSDictionary*>* appInfoList = [NSMutableArray array];
-        NSFileManager* fileManager = [NSFileManager defaultManager];
-        NSWorkspace* workspace = [NSWorkspace sharedWorkspace];
+// Resolve the UTI of a file on disk, from its extension or its recorded content type
+int ResolveUTIForFile(const char* filePath, char** outUTI, char** outError) {
+    @autoreleasepool {
+        if (!filePath || !outUTI) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_SYSTEM;
+        }
 
-        // Standard application directories to search
-        NSArray<NSString*>* appDirectories = @[
-            @"/Applications",
-            @"/System/Applications",
-            @"/System/Applications/Utilities",
-            [@"~/Applications" stringByExpandingTildeInPath]
-        ];
+        *outUTI = NULL;
 
-        // Enumerate all application directories
-        for (NSString* directory in appDirectories) {
-            BOOL isDirectory;
-            if (![fileManager fileExistsAtPath:directory isDirectory:&isDirectory] || !isDirectory) {
-                continue;
-            }
+        NSString* filePathString = [NSString stringWithUTF8String:filePath];
+        if (!filePathString) {
+            SetError(outError, @"Invalid UTF-8 in file path string");
+            return BRIDGE_ERROR_SYSTEM;
+        }
 
-            NSError* error = nil;
-            NSArray<NSString*>* contents = [fileManager contentsOfDirectoryAtPath:directory error:&error];
+        if (![[NSFileManager defaultManager] fileExistsAtPath:filePathString]) {
+            SetError(outError, [NSString stringWithFormat:@"File not found: %s", filePath]);
+            return BRIDGE_ERROR_SYSTEM;
+        }
 
-            if (error) {
-                continue; // Skip directories we can't read
+        UTType* utType = nil;
+
+        NSString* extension = [filePathString pathExtension];
+        if ([extension length] > 0) {
+            UTType* extensionType = [UTType typeWithFilenameExtension:extension];
+            if (extensionType && ![extensionType isDynamic]) {
+                utType = extensionType;
             }
+        }
 
-            for (NSString* item in contents) {
-                if (![item hasSuffix:@".app"]) {
-                    continue;
-                }
+        // No usable extension, so ask the file system what the file contains
+        if (!utType) {
+            NSURL* fileURL = [NSURL fileURLWithPath:filePathString];
+            UTType* contentType = nil;
+            NSError* error = nil;
+            if ([fileURL getResourceValue:&contentType forKey:NSURLContentTypeKey error:&error] &&
+                contentType && ![contentType isDynamic]) {
+                utType = contentType;
+            }
+        }
 
-                NSString* fullPath = [directory stringByAppendingPathComponent:item];
-                NSURL* appURL = [NSURL fileURLWithPath:fullPath];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Could not determine the type of file: %s", filePath]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
 
-                // Get bundle information
-                NSBundle* bundle = [NSBundle bundleWithURL:appURL];
-                if (!bundle) {
-                    continue;
-                }
+        *outUTI = NSStringToCString([utType identifier]);
+        if (!*outUTI) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
 
-                NSString* bundleID = [bundle bundleIdentifier];
-                NSString* appName = [bundle objectForInfoDictionaryKey:@"CFBundleName"];
+        return BRIDGE_OK;
+    }
+}
 
-                // Fallback to display name if CFBundleName is not available
-                if (!appName) {
-                    appName = [bundle objectForInfoDictionaryKey:@"CFBundleDisplayName"];
-                }
+// Check whether a UTI is a dynamic (dyn.*) type
+int UTIIsDynamic(const char* uti, int* outIsDynamic, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outIsDynamic) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
 
-                // Fallback to filename without .app extension
-                if (!appName) {
-                    appName = [[item lastPathComponent] stringByDeletingPathExtension];
+        *outIsDynamic = 0;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outIsDynamic = [utType isDynamic] ? 1 : 0;
+        return BRIDGE_OK;
+    }
+}
+
+// Check whether a UTI is declared by the system or an installed application
+int UTIIsDeclared(const char* uti, int* outIsDeclared, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outIsDeclared) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outIsDeclared = 0;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        // Unknown identifiers are not an error here, they are simply not declared
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        *outIsDeclared = (utType && [utType isDeclared] && ![utType isDynamic]) ? 1 : 0;
+        return BRIDGE_OK;
+    }
+}
+
+// Check whether a UTI conforms to another UTI
+int UTIConformsTo(const char* uti, const char* parentUTI, int* outConforms, char** outError) {
+    @autoreleasepool {
+        if (!uti || !parentUTI || !outConforms) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outConforms = 0;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        NSString* parentString = [NSString stringWithUTF8String:parentUTI];
+        if (!utiString || !parentString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* parentType = [UTType typeWithIdentifier:parentString];
+        if (!parentType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", parentUTI]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outConforms = [utType conformsToType:parentType] ? 1 : 0;
+        return BRIDGE_OK;
+    }
+}
+
+// Get all UTIs a type conforms to
+int GetSupertypesForUTI(const char* uti, char*** outUTIs, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outUTIs || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outUTIs = NULL;
+        *outCount = 0;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        // supertypes is already the transitive closure, excluding the type itself
+        NSMutableArray<NSString*>* identifiers = [NSMutableArray array];
+        for (UTType* supertype in [utType supertypes]) {
+            if (![[supertype identifier] isEqualToString:[utType identifier]]) {
+                [identifiers addObject:[supertype identifier]];
+            }
+        }
+
+        if ([identifiers count] == 0) {
+            // Not an error - root types have no supertypes
+            return BRIDGE_OK;
+        }
+
+        NSArray* sortedUTIs = [identifiers sortedArrayUsingSelector:@selector(compare:)];
+        int count = (int)[sortedUTIs count];
+
+        char** utis = (char**)calloc(count, sizeof(char*));
+        if (!utis) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < count; i++) {
+            utis[i] = NSStringToCString(sortedUTIs[i]);
+            if (!utis[i]) {
+                FreeCStringArray(utis, i);
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+        }
+
+        *outUTIs = utis;
+        *outCount = count;
+        return BRIDGE_OK;
+    }
+}
+
+// Get the localized description for a UTI
+int GetUTIDescription(const char* uti, char** outDescription, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outDescription) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outDescription = NULL;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        // Fall back to the identifier for types without a localized description
+        NSString* description = [utType localizedDescription];
+        if (!description || [description length] == 0) {
+            description = [utType identifier];
+        }
+
+        *outDescription = NSStringToCString(description);
+        if (!*outDescription) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// List all applications that can open a UTI
+int ListAppsForUTI(const char* uti, char*** outAppPaths, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outAppPaths || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outAppPaths = NULL;
+        *outCount = 0;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        NSWorkspace* workspace = [NSWorkspace sharedWorkspace];
+        NSArray<NSURL*>* appURLs = [workspace URLsForApplicationsToOpenContentType:utType];
+
+        if (!appURLs || [appURLs count] == 0) {
+            // Return empty list, not an error
+            return BRIDGE_OK;
+        }
+
+        *outCount = (int)[appURLs count];
+        *outAppPaths = (char**)malloc(sizeof(char*) * (*outCount));
+
+        if (!*outAppPaths) {
+            *outCount = 0;
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < *outCount; i++) {
+            NSURL* appURL = appURLs[i];
+            (*outAppPaths)[i] = URLToPath(appURL);
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// List all applications that can open each of several UTIs in one call
+int ListAppsForUTIs(const char** utis, int utiCount, char*** outAppPaths, int** outCounts, int* outTotal, char** outError) {
+    @autoreleasepool {
+        if ((!utis && utiCount > 0) || utiCount < 0 || !outAppPaths || !outCounts || !outTotal) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outAppPaths = NULL;
+        *outCounts = NULL;
+        *outTotal = 0;
+
+        if (utiCount == 0) {
+            return BRIDGE_OK;
+        }
+
+        int* counts = (int*)calloc(utiCount, sizeof(int));
+        if (!counts) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        NSWorkspace* workspace = [NSWorkspace sharedWorkspace];
+        NSMutableArray<NSString*>* allPaths = [NSMutableArray array];
+
+        for (int i = 0; i < utiCount; i++) {
+            NSString* utiString = utis[i] ? [NSString stringWithUTF8String:utis[i]] : nil;
+            UTType* utType = [utiString length] > 0 ? [UTType typeWithIdentifier:utiString] : nil;
+            if (!utType) {
+                // Unresolvable UTIs get no applications rather than failing the batch
+                continue;
+            }
+
+            NSArray<NSURL*>* appURLs = [workspace URLsForApplicationsToOpenContentType:utType];
+            for (NSURL* appURL in appURLs) {
+                NSString* path = [appURL path];
+                if (path) {
+                    [allPaths addObject:path];
+                    counts[i]++;
                 }
+            }
+        }
+
+        int total = (int)[allPaths count];
+        if (total == 0) {
+            *outCounts = counts;
+            return BRIDGE_OK;
+        }
+
+        char** paths = (char**)calloc(total, sizeof(char*));
+        if (!paths) {
+            free(counts);
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < total; i++) {
+            paths[i] = NSStringToCString(allPaths[i]);
+            if (!paths[i]) {
+                FreeCStringArray(paths, i);
+                free(counts);
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+        }
+
+        *outAppPaths = paths;
+        *outCounts = counts;
+        *outTotal = total;
+        return BRIDGE_OK;
+    }
+}
+
+// List the applications that can open a UTI in a given role
+int ListAppsForUTIWithRole(const char* uti, unsigned int roleMask, char*** outAppPaths, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outAppPaths || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outAppPaths = NULL;
+        *outCount = 0;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        // The all-roles listing comes from NSWorkspace so it matches ListAppsForUTI exactly
+        NSArray<NSURL*>* appURLs = roleMask == BRIDGE_ROLE_ALL
+            ? [[NSWorkspace sharedWorkspace] URLsForApplicationsToOpenContentType:utType]
+            : AppURLsForContentTypeRole(utType, (LSRolesMask)roleMask);
+
+        int count = (int)[appURLs count];
+        if (count == 0) {
+            return BRIDGE_OK;
+        }
+
+        char** paths = (char**)calloc(count, sizeof(char*));
+        if (!paths) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < count; i++) {
+            paths[i] = URLToPath(appURLs[i]);
+            if (!paths[i]) {
+                FreeCStringArray(paths, i);
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+        }
+
+        *outAppPaths = paths;
+        *outCount = count;
+        return BRIDGE_OK;
+    }
+}
+
+// List all applications that can open a UTI along with their metadata
+int ListAppInfosForUTI(const char* uti, AppInfo*** outApps, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outApps || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outApps = NULL;
+        *outCount = 0;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        NSArray<NSURL*>* appURLs = [[NSWorkspace sharedWorkspace] URLsForApplicationsToOpenContentType:utType];
+
+        return AppInfoArrayForURLs(appURLs ?: @[], outApps, outCount, outError);
+    }
+}
+
+// List the applications that can handle a UTI, grouped by role
+int GetAllHandlersForUTIByRole(const char* uti,
+                               AppInfo*** outEditors, int* outEditorCount,
+                               AppInfo*** outViewers, int* outViewerCount,
+                               AppInfo*** outAll, int* outAllCount,
+                               char** outError) {
+    @autoreleasepool {
+        if (!uti || !outEditors || !outEditorCount || !outViewers || !outViewerCount || !outAll || !outAllCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outEditors = NULL;
+        *outEditorCount = 0;
+        *outViewers = NULL;
+        *outViewerCount = 0;
+        *outAll = NULL;
+        *outAllCount = 0;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        int code = AppInfoArrayForURLs(AppURLsForContentTypeRole(utType, kLSRolesEditor), outEditors, outEditorCount, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        code = AppInfoArrayForURLs(AppURLsForContentTypeRole(utType, kLSRolesViewer), outViewers, outViewerCount, outError);
+        if (code != BRIDGE_OK) {
+            FreeAppInfoArray(*outEditors, *outEditorCount);
+            *outEditors = NULL;
+            *outEditorCount = 0;
+            return code;
+        }
+
+        NSArray<NSURL*>* allURLs = [[NSWorkspace sharedWorkspace] URLsForApplicationsToOpenContentType:utType];
+        code = AppInfoArrayForURLs(allURLs ?: @[], outAll, outAllCount, outError);
+        if (code != BRIDGE_OK) {
+            FreeAppInfoArray(*outEditors, *outEditorCount);
+            FreeAppInfoArray(*outViewers, *outViewerCount);
+            *outEditors = NULL;
+            *outEditorCount = 0;
+            *outViewers = NULL;
+            *outViewerCount = 0;
+            return code;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// List all applications that can handle a URL scheme
+int ListAppsForScheme(const char* scheme, char*** outAppPaths, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!scheme || !outAppPaths || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_SCHEME;
+        }
+
+        *outAppPaths = NULL;
+        *outCount = 0;
+
+        NSString* schemeString = [NSString stringWithUTF8String:scheme];
+        if (!schemeString) {
+            SetError(outError, @"Invalid UTF-8 in scheme string");
+            return BRIDGE_ERROR_INVALID_SCHEME;
+        }
+
+        NSString* urlString = [NSString stringWithFormat:@"%@://", schemeString];
+        NSURL* url = [NSURL URLWithString:urlString];
+
+        if (!url) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid URL scheme: %s", scheme]);
+            return BRIDGE_ERROR_INVALID_SCHEME;
+        }
+
+        NSWorkspace* workspace = [NSWorkspace sharedWorkspace];
+        NSArray<NSURL*>* appURLs = [workspace URLsForApplicationsToOpenURL:url];
+
+        if (!appURLs || [appURLs count] == 0) {
+            // Return empty list, not an error
+            return BRIDGE_OK;
+        }
+
+        *outCount = (int)[appURLs count];
+        *outAppPaths = (char**)malloc(sizeof(char*) * (*outCount));
+
+        if (!*outAppPaths) {
+            *outCount = 0;
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < *outCount; i++) {
+            NSURL* appURL = appURLs[i];
+            (*outAppPaths)[i] = URLToPath(appURL);
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// List all installed applications on the system
+int ListAllApplications(AppInfo*** outApps, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!outApps || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        *outApps = NULL;
+        *outCount = 0;
+
+        NSMutableArray<NSDictionary*>* appInfoList = [NSMutableArray array];
+        NSFileManager* fileManager = [NSFileManager defaultManager];
+        NSWorkspace* workspace = [NSWorkspace sharedWorkspace];
+
+        // Standard application directories to search
+        NSArray<NSString*>* appDirectories = @[
+            @"/Applications",
+            @"/System/Applications",
+            @"/System/Applications/Utilities",
+            [@"~/Applications" stringByExpandingTildeInPath]
+        ];
+
+        // Enumerate all application directories
+        for (NSString* directory in appDirectories) {
+            BOOL isDirectory;
+            if (![fileManager fileExistsAtPath:directory isDirectory:&isDirectory] || !isDirectory) {
+                continue;
+            }
+
+            NSError* error = nil;
+            NSArray<NSString*>* contents = [fileManager contentsOfDirectoryAtPath:directory error:&error];
+
+            if (error) {
+                continue; // Skip directories we can't read
+            }
+
+            for (NSString* item in contents) {
+                if (![item hasSuffix:@".app"]) {
+                    continue;
+                }
+
+                NSString* fullPath = [directory stringByAppendingPathComponent:item];
+                NSURL* appURL = [NSURL fileURLWithPath:fullPath];
+
+                // Get bundle information
+                NSBundle* bundle = [NSBundle bundleWithURL:appURL];
+                if (!bundle) {
+                    continue;
+                }
+
+                NSString* bundleID = [bundle bundleIdentifier];
+                NSString* appName = [bundle objectForInfoDictionaryKey:@"CFBundleName"];
+
+                // Fallback to display name if CFBundleName is not available
+                if (!appName) {
+                    appName = [bundle objectForInfoDictionaryKey:@"CFBundleDisplayName"];
+                }
+
+                // Fallback to filename without .app extension
+                if (!appName) {
+                    appName = [[item lastPathComponent] stringByDeletingPathExtension];
+                }
+
+                // Store app info
+                NSDictionary* appInfo = @{
+                    @"name": appName ?: @"",
+                    @"path": fullPath,
+                    @"bundleID": bundleID ?: @"",
+                    @"version": BundleInfoString(bundle, @"CFBundleShortVersionString"),
+                    @"buildVersion": BundleInfoString(bundle, @"CFBundleVersion"),
+                    @"category": BundleInfoString(bundle, @"LSApplicationCategoryType")
+                };
+
+                [appInfoList addObject:appInfo];
+            }
+        }
+
+        // Also get apps from LaunchServices (catches apps in other locations)
+        NSArray<NSURL*>* allApps = [workspace URLsForApplicationsToOpenContentType:[UTType typeWithIdentifier:@"public.item"]];
+
+        NSMutableSet<NSString*>* existingPaths = [NSMutableSet set];
+        for (NSDictionary* info in appInfoList) {
+            [existingPaths addObject:info[@"path"]];
+        }
+
+        for (NSURL* appURL in allApps) {
+            NSString* fullPath = [appURL path];
+
+            // Skip if we already have this app
+            if ([existingPaths containsObject:fullPath]) {
+                continue;
+            }
+
+            NSBundle* bundle = [NSBundle bundleWithURL:appURL];
+            if (!bundle) {
+                continue;
+            }
+
+            NSString* bundleID = [bundle bundleIdentifier];
+            NSString* appName = [bundle objectForInfoDictionaryKey:@"CFBundleName"];
+
+            if (!appName) {
+                appName = [bundle objectForInfoDictionaryKey:@"CFBundleDisplayName"];
+            }
+
+            if (!appName) {
+                appName = [[[fullPath lastPathComponent] stringByDeletingPathExtension] copy];
+            }
+
+            NSDictionary* appInfo = @{
+                @"name": appName ?: @"",
+                @"path": fullPath,
+                @"bundleID": bundleID ?: @"",
+                @"version": BundleInfoString(bundle, @"CFBundleShortVersionString"),
+                @"buildVersion": BundleInfoString(bundle, @"CFBundleVersion"),
+                @"category": BundleInfoString(bundle, @"LSApplicationCategoryType")
+            };
+
+            [appInfoList addObject:appInfo];
+            [existingPaths addObject:fullPath];
+        }
+
+        // Convert to C array
+        *outCount = (int)[appInfoList count];
+
+        if (*outCount == 0) {
+            return BRIDGE_OK;
+        }
+
+        *outApps = (AppInfo**)malloc(sizeof(AppInfo*) * (*outCount));
+        if (!*outApps) {
+            *outCount = 0;
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < *outCount; i++) {
+            NSDictionary* appInfo = appInfoList[i];
+
+            (*outApps)[i] = (AppInfo*)calloc(1, sizeof(AppInfo));
+            if (!(*outApps)[i]) {
+                // Clean up previously allocated memory
+                FreeAppInfoArray(*outApps, i);
+                *outApps = NULL;
+                *outCount = 0;
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+
+            (*outApps)[i]->name = NSStringToCString(appInfo[@"name"]);
+            (*outApps)[i]->path = NSStringToCString(appInfo[@"path"]);
+            (*outApps)[i]->bundleID = NSStringToCString(appInfo[@"bundleID"]);
+            (*outApps)[i]->version = NSStringToCString(appInfo[@"version"]);
+            (*outApps)[i]->buildVersion = NSStringToCString(appInfo[@"buildVersion"]);
+            (*outApps)[i]->category = NSStringToCString(appInfo[@"category"]);
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// Helper function to add the UTIs declared under a key of an Info.plist array to a set
+static void AddDeclaredUTIs(NSArray* entries, NSString* key, NSMutableSet<NSString*>* utisSet) {
+    if (![entries isKindOfClass:[NSArray class]]) {
+        return;
+    }
+
+    for (id entry in entries) {
+        if (![entry isKindOfClass:[NSDictionary class]]) {
+            continue;
+        }
+
+        id value = ((NSDictionary*)entry)[key];
+        NSArray* values = [value isKindOfClass:[NSArray class]] ? value : (value ? @[value] : @[]);
+
+        for (id uti in values) {
+            // Dynamic identifiers are synthesized on the fly and never registered
+            if ([uti isKindOfClass:[NSString class]] && [(NSString*)uti length] > 0 && ![(NSString*)uti hasPrefix:@"dyn."]) {
+                [utisSet addObject:(NSString*)uti];
+            }
+        }
+    }
+}
+
+// Helper function to collect the UTIs of installed applications, sorted and deduplicated
+//
+// Document type UTIs are always included; exported and imported type
+// declarations only if includeTypeDeclarations is set.
+static int CollectInstalledAppUTIs(BOOL includeTypeDeclarations, char*** outUTIs, int* outCount, char** outError) {
+    if (!outUTIs || !outCount) {
+        SetError(outError, @"Invalid parameters");
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    *outUTIs = NULL;
+    *outCount = 0;
+
+    NSArray<NSURL*>* appURLs = [[NSWorkspace sharedWorkspace] URLsForApplicationsToOpenContentType:[UTType typeWithIdentifier:@"public.item"]];
+    NSMutableSet<NSString*>* utisSet = [NSMutableSet set];
+
+    for (NSURL* appURL in appURLs) {
+        NSBundle* bundle = [NSBundle bundleWithURL:appURL];
+        if (!bundle) {
+            continue;
+        }
+
+        // UTIs the app claims to open, plus the ones it declares to the system
+        AddDeclaredUTIs([bundle objectForInfoDictionaryKey:@"CFBundleDocumentTypes"], @"LSItemContentTypes", utisSet);
+        if (includeTypeDeclarations) {
+            AddDeclaredUTIs([bundle objectForInfoDictionaryKey:@"UTExportedTypeDeclarations"], @"UTTypeIdentifier", utisSet);
+            AddDeclaredUTIs([bundle objectForInfoDictionaryKey:@"UTImportedTypeDeclarations"], @"UTTypeIdentifier", utisSet);
+        }
+    }
+
+    if ([utisSet count] == 0) {
+        return BRIDGE_OK;
+    }
+
+    NSArray* sortedUTIs = [[utisSet allObjects] sortedArrayUsingSelector:@selector(compare:)];
+    int count = (int)[sortedUTIs count];
+
+    char** utis = (char**)calloc(count, sizeof(char*));
+    if (!utis) {
+        SetError(outError, @"Memory allocation failed");
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    for (int i = 0; i < count; i++) {
+        utis[i] = NSStringToCString(sortedUTIs[i]);
+        if (!utis[i]) {
+            FreeCStringArray(utis, i);
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+    }
+
+    *outUTIs = utis;
+    *outCount = count;
+    return BRIDGE_OK;
+}
+
+// List every UTI registered by installed applications
+int ListAllRegisteredUTIs(char*** outUTIs, int* outCount, char** outError) {
+    @autoreleasepool {
+        return CollectInstalledAppUTIs(YES, outUTIs, outCount, outError);
+    }
+}
+
+// List every UTI installed applications claim to open in their document types
+int ListAllDocumentTypeUTIs(char*** outUTIs, int* outCount, char** outError) {
+    @autoreleasepool {
+        return CollectInstalledAppUTIs(NO, outUTIs, outCount, outError);
+    }
+}
+
+// Open a set of file URLs with an application and wait for the launch to finish
+static int OpenURLsWithApplication(NSArray<NSURL*>* fileURLs, NSURL* appURL, int* outStatus, char** outError) {
+    if (outStatus) *outStatus = 0;
+
+    dispatch_semaphore_t semaphore = dispatch_semaphore_create(0);
+    __block int resultCode = BRIDGE_OK;
+    __block NSError* resultError = nil;
+
+    NSWorkspaceOpenConfiguration* configuration = [NSWorkspaceOpenConfiguration configuration];
+
+    [[NSWorkspace sharedWorkspace] openURLs:fileURLs
+                       withApplicationAtURL:appURL
+                              configuration:configuration
+                          completionHandler:^(NSRunningApplication * _Nullable app, NSError * _Nullable error) {
+        if (error) {
+            resultError = [error retain];
+            if (IsUserCancelledError(error)) {
+                resultCode = BRIDGE_ERROR_USER_DECLINED;
+            } else {
+                resultCode = BRIDGE_ERROR_SYSTEM;
+            }
+        }
+        dispatch_semaphore_signal(semaphore);
+    }];
+
+    // Launching a large application can be slow, so allow longer than the setters do
+    long timeoutResult = dispatch_semaphore_wait(semaphore, dispatch_time(DISPATCH_TIME_NOW, 30 * NSEC_PER_SEC));
+
+    if (timeoutResult != 0) {
+        SetError(outError, @"Operation timed out");
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    if (resultError) {
+        NSString* detailedError = [NSString stringWithFormat:@"%@ (domain: %@, code: %ld)",
+                                   [resultError localizedDescription],
+                                   [resultError domain],
+                                   (long)[resultError code]];
+        SetError(outError, detailedError);
+        if (outStatus) *outStatus = OSStatusForError(resultError);
+        [resultError release];
+        return resultCode;
+    }
+
+    return BRIDGE_OK;
+}
+
+// Open a file with its default application
+int OpenFile(const char* filePath, int* outStatus, char** outError) {
+    @autoreleasepool {
+        if (outStatus) *outStatus = 0;
+
+        if (!filePath) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        NSString* filePathString = [NSString stringWithUTF8String:filePath];
+        if (!filePathString) {
+            SetError(outError, @"Invalid UTF-8 in file path string");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        if (![[NSFileManager defaultManager] fileExistsAtPath:filePathString]) {
+            SetError(outError, [NSString stringWithFormat:@"File not found: %s", filePath]);
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        NSURL* fileURL = [NSURL fileURLWithPath:filePathString];
+        NSURL* appURL = [[NSWorkspace sharedWorkspace] URLForApplicationToOpenURL:fileURL];
+        if (!appURL) {
+            SetError(outError, [NSString stringWithFormat:@"No application found to open: %s", filePath]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        return OpenURLsWithApplication(@[fileURL], appURL, outStatus, outError);
+    }
+}
+
+// Open a file with a specific application
+int OpenFileWithApp(const char* filePath, const char* appPath, int* outStatus, char** outError) {
+    @autoreleasepool {
+        if (outStatus) *outStatus = 0;
+
+        if (!filePath || !appPath) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSString* filePathString = [NSString stringWithUTF8String:filePath];
+        NSString* appPathString = [NSString stringWithUTF8String:appPath];
+        if (!filePathString || !appPathString) {
+            SetError(outError, @"Invalid UTF-8 in parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        if (![[NSFileManager defaultManager] fileExistsAtPath:filePathString]) {
+            SetError(outError, [NSString stringWithFormat:@"File not found: %s", filePath]);
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        if (![[NSFileManager defaultManager] fileExistsAtPath:appPathString]) {
+            SetError(outError, [NSString stringWithFormat:@"Application not found: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSURL* fileURL = [NSURL fileURLWithPath:filePathString];
+        NSURL* appURL = [NSURL fileURLWithPath:appPathString];
+
+        return OpenURLsWithApplication(@[fileURL], appURL, outStatus, outError);
+    }
+}
+
+// Open several files with a specific application in a single launch
+int OpenFilesWithApp(const char* appPath, const char** filePaths, int fileCount, int* outStatus, char** outError) {
+    @autoreleasepool {
+        if (outStatus) *outStatus = 0;
+
+        if (!appPath || !filePaths || fileCount <= 0) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSString* appPathString = [NSString stringWithUTF8String:appPath];
+        if (!appPathString) {
+            SetError(outError, @"Invalid UTF-8 in app path string");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        if (![[NSFileManager defaultManager] fileExistsAtPath:appPathString]) {
+            SetError(outError, [NSString stringWithFormat:@"Application not found: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSMutableArray<NSURL*>* fileURLs = [NSMutableArray arrayWithCapacity:fileCount];
+        for (int i = 0; i < fileCount; i++) {
+            NSString* filePathString = filePaths[i] ? [NSString stringWithUTF8String:filePaths[i]] : nil;
+            if (!filePathString) {
+                SetError(outError, @"Invalid UTF-8 in file path string");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+
+            // Fail before launching so the app never receives a partial set
+            if (![[NSFileManager defaultManager] fileExistsAtPath:filePathString]) {
+                SetError(outError, [NSString stringWithFormat:@"File not found: %s", filePaths[i]]);
+                return BRIDGE_ERROR_SYSTEM;
+            }
+
+            [fileURLs addObject:[NSURL fileURLWithPath:filePathString]];
+        }
+
+        NSURL* appURL = [NSURL fileURLWithPath:appPathString];
+
+        return OpenURLsWithApplication(fileURLs, appURL, outStatus, outError);
+    }
+}
+
+// Check that a path points to a loadable application bundle
+int ValidateAppBundle(const char* appPath, char** outError) {
+    @autoreleasepool {
+        if (!appPath) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSString* appPathString = [NSString stringWithUTF8String:appPath];
+        if (!appPathString) {
+            SetError(outError, @"Invalid UTF-8 in app path string");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        BOOL isDirectory = NO;
+        if (![[NSFileManager defaultManager] fileExistsAtPath:appPathString isDirectory:&isDirectory]) {
+            SetError(outError, [NSString stringWithFormat:@"Application not found: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        // Bundles are directories; a plain file can never be an app
+        if (!isDirectory) {
+            SetError(outError, [NSString stringWithFormat:@"Not an application bundle (not a directory): %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSBundle* bundle = [NSBundle bundleWithPath:appPathString];
+        NSDictionary* info = [bundle infoDictionary];
+        if (!bundle || [info count] == 0) {
+            SetError(outError, [NSString stringWithFormat:@"Missing or unreadable Info.plist: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        // Prefer the declared package type, falling back to the .app extension for bundles that omit it
+        NSString* packageType = info[@"CFBundlePackageType"];
+        BOOL isApp = [packageType isKindOfClass:[NSString class]]
+            ? [packageType isEqualToString:@"APPL"]
+            : [[[appPathString pathExtension] lowercaseString] isEqualToString:@"app"];
+        if (!isApp) {
+            SetError(outError, [NSString stringWithFormat:@"Not an application bundle: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        if (![bundle executablePath]) {
+            SetError(outError, [NSString stringWithFormat:@"Application executable missing: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// Get metadata for the application bundle at a path
+int GetAppInfoForPath(const char* appPath, AppInfo** outApp, char** outError) {
+    @autoreleasepool {
+        if (!appPath || !outApp) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outApp = NULL;
+
+        int code = ValidateAppBundle(appPath, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        NSURL* appURL = [NSURL fileURLWithPath:[NSString stringWithUTF8String:appPath]];
+
+        *outApp = NewAppInfoForURL(appURL);
+        if (!*outApp) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// Read a top-level value from an application bundle's Info.plist
+int GetBundleInfoValue(const char* appPath, const char* key, char** outValue, char** outError) {
+    @autoreleasepool {
+        if (!appPath || !key || !outValue) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outValue = NULL;
+
+        int code = ValidateAppBundle(appPath, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        NSString* keyString = [NSString stringWithUTF8String:key];
+        if (!keyString) {
+            SetError(outError, @"Invalid UTF-8 in key string");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        NSBundle* bundle = [NSBundle bundleWithURL:[NSURL fileURLWithPath:[NSString stringWithUTF8String:appPath]]];
+        id value = [bundle objectForInfoDictionaryKey:keyString];
+        if (!value) {
+            SetError(outError, [NSString stringWithFormat:@"Key not found in Info.plist: %s", key]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        NSString* valueString = [value isKindOfClass:[NSString class]] ? (NSString*)value : [value description];
+        *outValue = NSStringToCString(valueString);
+        if (!*outValue) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// Helper function to open the static code of a valid application bundle; the caller releases *outCode
+//
+// outStatus, if not NULL, receives the OSStatus when the code cannot be opened.
+static int StaticCodeForApp(const char* appPath, SecStaticCodeRef* outCode, int* outStatus, char** outError) {
+    *outCode = NULL;
+
+    int code = ValidateAppBundle(appPath, outError);
+    if (code != BRIDGE_OK) {
+        return code;
+    }
+
+    NSURL* appURL = [NSURL fileURLWithPath:[NSString stringWithUTF8String:appPath]];
+
+    OSStatus status = SecStaticCodeCreateWithPath((CFURLRef)appURL, kSecCSDefaultFlags, outCode);
+    if (status != errSecSuccess) {
+        SetError(outError, [NSString stringWithFormat:@"Could not read code signature of %s (OSStatus %d)", appPath, (int)status]);
+        if (outStatus) *outStatus = (int)status;
+        *outCode = NULL;
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    return BRIDGE_OK;
+}
+
+// Helper function to read the code signing information of a valid application bundle (autoreleased)
+static int SigningInformationForApp(const char* appPath, NSDictionary** outInfo, char** outError) {
+    *outInfo = nil;
+
+    SecStaticCodeRef staticCode = NULL;
+    int code = StaticCodeForApp(appPath, &staticCode, NULL, outError);
+    if (code != BRIDGE_OK) {
+        return code;
+    }
+
+    CFDictionaryRef signingInfo = NULL;
+    OSStatus status = SecCodeCopySigningInformation(staticCode, kSecCSSigningInformation, &signingInfo);
+    CFRelease(staticCode);
+    if (status != errSecSuccess) {
+        SetError(outError, [NSString stringWithFormat:@"Could not read signing information of %s (OSStatus %d)", appPath, (int)status]);
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    *outInfo = [(NSDictionary*)signingInfo autorelease];
+    return BRIDGE_OK;
+}
+
+// Check whether an application is signed with the App Sandbox entitlement
+int AppIsSandboxed(const char* appPath, int* outIsSandboxed, char** outError) {
+    @autoreleasepool {
+        if (!appPath || !outIsSandboxed) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outIsSandboxed = 0;
+
+        NSDictionary* signingInfo = nil;
+        int code = SigningInformationForApp(appPath, &signingInfo, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        // Unsigned code has no entitlements dictionary at all
+        NSDictionary* entitlements = signingInfo[(NSString*)kSecCodeInfoEntitlementsDict];
+        id sandbox = [entitlements isKindOfClass:[NSDictionary class]] ? entitlements[@"com.apple.security.app-sandbox"] : nil;
+        *outIsSandboxed = ([sandbox respondsToSelector:@selector(boolValue)] && [sandbox boolValue]) ? 1 : 0;
+        return BRIDGE_OK;
+    }
+}
+
+// Get the Team Identifier from an application's code signature
+int GetCodeSigningTeamID(const char* appPath, char** outTeamID, char** outError) {
+    @autoreleasepool {
+        if (!appPath || !outTeamID) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outTeamID = NULL;
+
+        NSDictionary* signingInfo = nil;
+        int code = SigningInformationForApp(appPath, &signingInfo, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        // Unsigned and ad-hoc signed code has no team
+        id teamID = signingInfo[(NSString*)kSecCodeInfoTeamIdentifier];
+        *outTeamID = NSStringToCString([teamID isKindOfClass:[NSString class]] ? (NSString*)teamID : @"");
+        if (!*outTeamID) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// Check whether an application's code signature is valid
+int CheckCodeSignature(const char* appPath, int* outIsValid, int* outStatus, char** outError) {
+    @autoreleasepool {
+        if (outStatus) *outStatus = 0;
+
+        if (!appPath || !outIsValid) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outIsValid = 0;
+
+        SecStaticCodeRef staticCode = NULL;
+        int code = StaticCodeForApp(appPath, &staticCode, outStatus, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        CFErrorRef validityError = NULL;
+        OSStatus status = SecStaticCodeCheckValidityWithErrors(staticCode, kSecCSDefaultFlags, NULL, &validityError);
+        CFRelease(staticCode);
+
+        if (status != errSecSuccess) {
+            NSString* reason = validityError ? [(NSError*)validityError localizedDescription] : @"code signature is not valid";
+            SetError(outError, [NSString stringWithFormat:@"%@ (OSStatus %d)", reason, (int)status]);
+            if (outStatus) *outStatus = (int)status;
+            if (validityError) CFRelease(validityError);
+            return BRIDGE_OK;
+        }
+
+        *outIsValid = 1;
+        return BRIDGE_OK;
+    }
+}
+
+// Helper function to render an image at a square pixel size and encode it as PNG
+static int ImageToPNG(NSImage* image, int size, unsigned char** outData, int* outLength, char** outError) {
+    NSBitmapImageRep* rep = [[NSBitmapImageRep alloc] initWithBitmapDataPlanes:NULL
+                                                                    pixelsWide:size
+                                                                    pixelsHigh:size
+                                                                 bitsPerSample:8
+                                                               samplesPerPixel:4
+                                                                      hasAlpha:YES
+                                                                      isPlanar:NO
+                                                                colorSpaceName:NSCalibratedRGBColorSpace
+                                                                   bytesPerRow:0
+                                                                  bitsPerPixel:0];
+    if (!rep) {
+        SetError(outError, @"Failed to create bitmap");
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    // Draw in pixel units so the result is exactly size x size regardless of screen scale
+    [rep setSize:NSMakeSize(size, size)];
+
+    [NSGraphicsContext saveGraphicsState];
+    [NSGraphicsContext setCurrentContext:[NSGraphicsContext graphicsContextWithBitmapImageRep:rep]];
+    [image drawInRect:NSMakeRect(0, 0, size, size)
+             fromRect:NSZeroRect
+            operation:NSCompositingOperationCopy
+             fraction:1.0];
+    [NSGraphicsContext restoreGraphicsState];
+
+    NSData* png = [rep representationUsingType:NSBitmapImageFileTypePNG properties:@{}];
+    [rep release];
+
+    if ([png length] == 0) {
+        SetError(outError, @"Failed to encode PNG");
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    *outData = (unsigned char*)malloc([png length]);
+    if (!*outData) {
+        SetError(outError, @"Memory allocation failed");
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    memcpy(*outData, [png bytes], [png length]);
+    *outLength = (int)[png length];
+    return BRIDGE_OK;
+}
+
+// Get an application's icon as PNG data
+int GetAppIconPNG(const char* appPath, int size, unsigned char** outData, int* outLength, char** outError) {
+    @autoreleasepool {
+        if (!appPath || !outData || !outLength || size <= 0) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outData = NULL;
+        *outLength = 0;
+
+        int code = ValidateAppBundle(appPath, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        NSString* appPathString = [NSString stringWithUTF8String:appPath];
+        NSDictionary* info = [[NSBundle bundleWithPath:appPathString] infoDictionary];
+        BOOL hasBundleIcon = info[@"CFBundleIconFile"] || info[@"CFBundleIconName"] || info[@"CFBundleIcons"];
+
+        NSImage* icon = hasBundleIcon ? [[NSWorkspace sharedWorkspace] iconForFile:appPathString] : nil;
+        if (!icon) {
+            // No icon of its own, so use the generic application icon Finder shows
+            icon = [[NSWorkspace sharedWorkspace] iconForContentType:UTTypeApplicationBundle];
+        }
+        if (!icon) {
+            SetError(outError, [NSString stringWithFormat:@"No icon found for application: %s", appPath]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        return ImageToPNG(icon, size, outData, outLength, outError);
+    }
+}
+
+// Get the icon for a UTI as PNG data
+int GetUTIIconPNG(const char* uti, int size, unsigned char** outData, int* outLength, char** outError) {
+    @autoreleasepool {
+        if (!uti || !outData || !outLength || size <= 0) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        *outData = NULL;
+        *outLength = 0;
+
+        NSString* utiString = [NSString stringWithUTF8String:uti];
+        if (!utiString) {
+            SetError(outError, @"Invalid UTF-8 in UTI string");
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        UTType* utType = [UTType typeWithIdentifier:utiString];
+        if (!utType) {
+            SetError(outError, [NSString stringWithFormat:@"Invalid or unknown UTI: %s", uti]);
+            return BRIDGE_ERROR_INVALID_UTI;
+        }
+
+        NSImage* icon = [[NSWorkspace sharedWorkspace] iconForContentType:utType];
+        if (!icon) {
+            SetError(outError, [NSString stringWithFormat:@"No icon found for UTI: %s", uti]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        return ImageToPNG(icon, size, outData, outLength, outError);
+    }
+}
+
+// Find an installed application by its bundle identifier
+int FindAppByBundleID(const char* bundleID, AppInfo** outApp, char** outError) {
+    @autoreleasepool {
+        if (!bundleID || !outApp) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outApp = NULL;
+
+        NSString* bundleIDString = [NSString stringWithUTF8String:bundleID];
+        if (!bundleIDString) {
+            SetError(outError, @"Invalid UTF-8 in bundle identifier string");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSURL* appURL = [[NSWorkspace sharedWorkspace] URLForApplicationWithBundleIdentifier:bundleIDString];
+        if (!appURL) {
+            SetError(outError, [NSString stringWithFormat:@"No application found with bundle identifier: %s", bundleID]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        *outApp = NewAppInfoForURL(appURL);
+        if (!*outApp) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        return BRIDGE_OK;
+    }
+}
+
+// Helper function to create an AppInfo structure for a running application (caller must free)
+static int AppInfoForRunningApplication(NSRunningApplication* runningApp, AppInfo** outApp, char** outError) {
+    NSURL* appURL = [runningApp bundleURL];
+    if (!appURL) {
+        SetError(outError, [NSString stringWithFormat:@"Process %d is not an application bundle", (int)[runningApp processIdentifier]]);
+        return BRIDGE_ERROR_NOT_FOUND;
+    }
+
+    *outApp = NewAppInfoForURL(appURL);
+    if (!*outApp) {
+        SetError(outError, @"Memory allocation failed");
+        return BRIDGE_ERROR_SYSTEM;
+    }
+
+    return BRIDGE_OK;
+}
+
+// Get the application bundle that owns a running process
+int GetAppInfoForPID(int pid, AppInfo** outApp, char** outError) {
+    @autoreleasepool {
+        if (!outApp) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outApp = NULL;
+
+        NSRunningApplication* runningApp = [NSRunningApplication runningApplicationWithProcessIdentifier:(pid_t)pid];
+        if (!runningApp) {
+            SetError(outError, [NSString stringWithFormat:@"No application owns process %d", pid]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        return AppInfoForRunningApplication(runningApp, outApp, outError);
+    }
+}
+
+// Get the frontmost application, the one receiving key events
+int GetFrontmostApplication(AppInfo** outApp, char** outError) {
+    @autoreleasepool {
+        if (!outApp) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outApp = NULL;
+
+        // NSWorkspace only refreshes frontmostApplication and the isActive flags of
+        // the instances it hands out from the main run loop, which a Go program
+        // usually does not run. Re-create each instance from its PID so isActive
+        // is read from the current state.
+        NSRunningApplication* runningApp = nil;
+        for (NSRunningApplication* instance in [[NSWorkspace sharedWorkspace] runningApplications]) {
+            NSRunningApplication* current = [NSRunningApplication runningApplicationWithProcessIdentifier:[instance processIdentifier]];
+            if (current && [current isActive] && ![current isTerminated]) {
+                runningApp = current;
+                break;
+            }
+        }
+        if (!runningApp) {
+            runningApp = [[NSWorkspace sharedWorkspace] frontmostApplication];
+        }
+        if (!runningApp) {
+            SetError(outError, @"No frontmost application");
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
+
+        return AppInfoForRunningApplication(runningApp, outApp, outError);
+    }
+}
+
+// Helper function to find a running, not yet terminated instance of an application
+static NSRunningApplication* RunningInstanceForBundleID(NSString* bundleID) {
+    for (NSRunningApplication* instance in [NSRunningApplication runningApplicationsWithBundleIdentifier:bundleID]) {
+        if (![instance isTerminated]) {
+            return instance;
+        }
+    }
+    return nil;
+}
+
+// Check whether an application with a bundle identifier is running
+int AppIsRunning(const char* bundleID, int* outIsRunning, char** outError) {
+    @autoreleasepool {
+        if (!bundleID || !outIsRunning) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outIsRunning = 0;
 
-                // Store app info
-                NSDictionary* appInfo = @{
-                    @"name": appName ?: @"",
-                    @"path": fullPath,
-                    @"bundleID": bundleID ?: @""
-                };
+        NSString* bundleIDString = [NSString stringWithUTF8String:bundleID];
+        if (!bundleIDString) {
+            SetError(outError, @"Invalid UTF-8 in bundle identifier string");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
 
-                [appInfoList addObject:appInfo];
-            }
+        *outIsRunning = RunningInstanceForBundleID(bundleIDString) ? 1 : 0;
+        return BRIDGE_OK;
+    }
+}
+
+// Bring a running application to the foreground
+int ActivateApplication(const char* bundleID, char** outError) {
+    @autoreleasepool {
+        if (!bundleID) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
         }
 
-        // Also get apps from LaunchServices (catches apps in other locations)
-        NSArray<NSURL*>* allApps = [workspace URLsForApplicationsToOpenContentType:[UTType typeWithIdentifier:@"public.item"]];
+        NSString* bundleIDString = [NSString stringWithUTF8String:bundleID];
+        if (!bundleIDString) {
+            SetError(outError, @"Invalid UTF-8 in bundle identifier string");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
 
-        NSMutableSet<NSString*>* existingPaths = [NSMutableSet set];
-        for (NSDictionary* info in appInfoList) {
-            [existingPaths addObject:info[@"path"]];
+        NSRunningApplication* instance = RunningInstanceForBundleID(bundleIDString);
+        if (!instance) {
+            SetError(outError, [NSString stringWithFormat:@"Application is not running: %s", bundleID]);
+            return BRIDGE_ERROR_NOT_FOUND;
         }
 
-        for (NSURL* appURL in allApps) {
-            NSString* fullPath = [appURL path];
+        if (![instance activateWithOptions:NSApplicationActivateAllWindows]) {
+            SetError(outError, [NSString stringWithFormat:@"Failed to activate application: %s", bundleID]);
+            return BRIDGE_ERROR_SYSTEM;
+        }
 
-            // Skip if we already have this app
-            if ([existingPaths containsObject:fullPath]) {
-                continue;
-            }
+        return BRIDGE_OK;
+    }
+}
 
-            NSBundle* bundle = [NSBundle bundleWithURL:appURL];
-            if (!bundle) {
-                continue;
-            }
+// Ask every running instance of an application to quit
+int TerminateApplication(const char* bundleID, int force, char** outError) {
+    @autoreleasepool {
+        if (!bundleID) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
 
-            NSString* bundleID = [bundle bundleIdentifier];
-            NSString* appName = [bundle objectForInfoDictionaryKey:@"CFBundleName"];
+        NSString* bundleIDString = [NSString stringWithUTF8String:bundleID];
+        if (!bundleIDString) {
+            SetError(outError, @"Invalid UTF-8 in bundle identifier string");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
 
-            if (!appName) {
-                appName = [bundle objectForInfoDictionaryKey:@"CFBundleDisplayName"];
+        BOOL found = NO;
+        int refused = 0;
+        for (NSRunningApplication* instance in [NSRunningApplication runningApplicationsWithBundleIdentifier:bundleIDString]) {
+            if ([instance isTerminated]) {
+                continue;
             }
+            found = YES;
 
-            if (!appName) {
-                appName = [[[fullPath lastPathComponent] stringByDeletingPathExtension] copy];
+            BOOL accepted = force ? [instance forceTerminate] : [instance terminate];
+            if (!accepted) {
+                refused++;
             }
+        }
 
-            NSDictionary* appInfo = @{
-                @"name": appName ?: @"",
-                @"path": fullPath,
-                @"bundleID": bundleID ?: @""
-            };
+        if (!found) {
+            SetError(outError, [NSString stringWithFormat:@"Application is not running: %s", bundleID]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
 
-            [appInfoList addObject:appInfo];
-            [existingPaths addObject:fullPath];
+        if (refused > 0) {
+            SetError(outError, [NSString stringWithFormat:@"%d instance(s) of %s did not accept the request to quit", refused, bundleID]);
+            return BRIDGE_ERROR_SYSTEM;
         }
 
-        // Convert to C array
-        *outCount = (int)[appInfoList count];
+        return BRIDGE_OK;
+    }
+}
 
-        if (*outCount == 0) {
-            return BRIDGE_OK;
+// Resolve a path that may be a Finder alias or symlink to the item it points to
+int ResolveAliasPath(const char* path, char** outResolvedPath, char** outError) {
+    @autoreleasepool {
+        if (!path || !outResolvedPath) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
         }
 
-        *outApps = (AppInfo**)malloc(sizeof(AppInfo*) * (*outCount));
-        if (!*outApps) {
-            SetError(outError, @"Memory allocation failed");
-            return BRIDGE_ERROR_SYSTEM;
+        *outResolvedPath = NULL;
+
+        NSString* pathString = [NSString stringWithUTF8String:path];
+        if (!pathString) {
+            SetError(outError, @"Invalid UTF-8 in path string");
+            return BRIDGE_ERROR_INVALID_APP;
         }
 
-        for (int i = 0; i < *outCount; i++) {
-            NSDictionary* appInfo = appInfoList[i];
+        NSURL* url = [NSURL fileURLWithPath:[pathString stringByExpandingTildeInPath]];
 
-            (*outApps)[i] = (AppInfo*)malloc(sizeof(AppInfo));
-            if (!(*outApps)[i]) {
-                // Clean up previously allocated memory
-                for (int j = 0; j < i; j++) {
-                    if ((*outApps)[j]->name) free((*outApps)[j]->name);
-                    if ((*outApps)[j]->path) free((*outApps)[j]->path);
-                    if ((*outApps)[j]->bundleID) free((*outApps)[j]->bundleID);
-                    free((*outApps)[j]);
-                }
-                free(*outApps);
-                *outApps = NULL;
-                *outCount = 0;
-                SetError(outError, @"Memory allocation failed");
-                return BRIDGE_ERROR_SYSTEM;
-            }
+        // Follows Finder aliases as well as symlinks at the final path component
+        NSError* error = nil;
+        NSURL* resolvedURL = [NSURL URLByResolvingAliasFileAtURL:url
+                                                         options:NSURLBookmarkResolutionWithoutUI | NSURLBookmarkResolutionWithoutMounting
+                                                           error:&error];
+        if (!resolvedURL) {
+            SetError(outError, [NSString stringWithFormat:@"Could not resolve path: %s (%@)", path, [error localizedDescription]]);
+            return BRIDGE_ERROR_NOT_FOUND;
+        }
 
-            (*outApps)[i]->name = NSStringToCString(appInfo[@"name"]);
-            (*outApps)[i]->path = NSStringToCString(appInfo[@"path"]);
-            (*outApps)[i]->bundleID = NSStringToCString(appInfo[@"bundleID"]);
+        // Resolve any symlinks in intermediate directories
+        *outResolvedPath = URLToPath([resolvedURL URLByResolvingSymlinksInPath]);
+        if (!*outResolvedPath) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
         }
 
         return BRIDGE_OK;
@@ -665,16 +2803,24 @@ void FreeCStringArray(char** arr, int count) {
     }
 }
 
+// Free a single AppInfo structure
+void FreeAppInfo(AppInfo* app) {
+    if (app) {
+        if (app->name) free(app->name);
+        if (app->path) free(app->path);
+        if (app->bundleID) free(app->bundleID);
+        if (app->version) free(app->version);
+        if (app->buildVersion) free(app->buildVersion);
+        if (app->category) free(app->category);
+        free(app);
+    }
+}
+
 // Free an array of AppInfo structures
 void FreeAppInfoArray(AppInfo** apps, int count) {
     if (apps) {
         for (int i = 0; i < count; i++) {
-            if (apps[i]) {
-                if (apps[i]->name) free(apps[i]->name);
-                if (apps[i]->path) free(apps[i]->path);
-                if (apps[i]->bundleID) free(apps[i]->bundleID);
-                free(apps[i]);
-            }
+            FreeAppInfo(apps[i]);
         }
         free(apps);
     }
@@ -854,6 +3000,7 @@ int GetSupportedDocumentTypesForApp(const char* appPath, DocumentType*** outDocT
 
         *outDocTypes = (DocumentType**)malloc(sizeof(DocumentType*) * (*outCount));
         if (!*outDocTypes) {
+            *outCount = 0;
             SetError(outError, @"Memory allocation failed");
             return BRIDGE_ERROR_SYSTEM;
         }
@@ -861,13 +3008,11 @@ int GetSupportedDocumentTypesForApp(const char* appPath, DocumentType*** outDocT
         for (int i = 0; i < *outCount; i++) {
             NSDictionary* docTypeInfo = docTypeInfoList[i];
 
-            (*outDocTypes)[i] = (DocumentType*)malloc(sizeof(DocumentType));
+            // Zeroed so a partly filled entry can be released by FreeDocumentTypeArray
+            (*outDocTypes)[i] = (DocumentType*)calloc(1, sizeof(DocumentType));
             if (!(*outDocTypes)[i]) {
                 // Clean up previously allocated memory
-                for (int j = 0; j < i; j++) {
-                    FreeDocumentTypeArray(*outDocTypes, j);
-                }
-                free(*outDocTypes);
+                FreeDocumentTypeArray(*outDocTypes, i);
                 *outDocTypes = NULL;
                 *outCount = 0;
                 SetError(outError, @"Memory allocation failed");
@@ -890,16 +3035,24 @@ int GetSupportedDocumentTypesForApp(const char* appPath, DocumentType*** outDocT
 
             // Set UTIs array
             NSArray* utis = docTypeInfo[@"utis"];
+            NSArray* extensions = docTypeInfo[@"extensions"];
+            (*outDocTypes)[i]->utis = (char**)calloc([utis count] ?: 1, sizeof(char*));
+            (*outDocTypes)[i]->extensions = (char**)calloc([extensions count] ?: 1, sizeof(char*));
+            if (!(*outDocTypes)[i]->utis || !(*outDocTypes)[i]->extensions) {
+                FreeDocumentTypeArray(*outDocTypes, i + 1);
+                *outDocTypes = NULL;
+                *outCount = 0;
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+
             (*outDocTypes)[i]->utiCount = (int)[utis count];
-            (*outDocTypes)[i]->utis = (char**)malloc(sizeof(char*) * (*outDocTypes)[i]->utiCount);
             for (int j = 0; j < (*outDocTypes)[i]->utiCount; j++) {
                 (*outDocTypes)[i]->utis[j] = NSStringToCString(utis[j]);
             }
 
             // Set extensions array
-            NSArray* extensions = docTypeInfo[@"extensions"];
             (*outDocTypes)[i]->extensionCount = (int)[extensions count];
-            (*outDocTypes)[i]->extensions = (char**)malloc(sizeof(char*) * (*outDocTypes)[i]->extensionCount);
             for (int j = 0; j < (*outDocTypes)[i]->extensionCount; j++) {
                 (*outDocTypes)[i]->extensions[j] = NSStringToCString(extensions[j]);
             }
@@ -912,6 +3065,143 @@ int GetSupportedDocumentTypesForApp(const char* appPath, DocumentType*** outDocT
     }
 }
 
+// Get the URL schemes an application declares in CFBundleURLTypes
+int GetSupportedSchemesForApp(const char* appPath, char*** outSchemes, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!appPath || !outSchemes || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outSchemes = NULL;
+        *outCount = 0;
+
+        NSString* appPathString = [NSString stringWithUTF8String:appPath];
+        if (!appPathString) {
+            SetError(outError, @"Invalid UTF-8 in app path string");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        if (![[NSFileManager defaultManager] fileExistsAtPath:appPathString]) {
+            SetError(outError, [NSString stringWithFormat:@"Application not found: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSBundle* bundle = [NSBundle bundleWithURL:[NSURL fileURLWithPath:appPathString]];
+        if (!bundle) {
+            SetError(outError, [NSString stringWithFormat:@"Could not load application bundle: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSArray* urlTypes = [bundle objectForInfoDictionaryKey:@"CFBundleURLTypes"];
+        if (!urlTypes || ![urlTypes isKindOfClass:[NSArray class]]) {
+            // Not an error - many apps don't declare URL schemes
+            return BRIDGE_OK;
+        }
+
+        NSMutableSet<NSString*>* schemesSet = [NSMutableSet set];
+
+        for (id urlType in urlTypes) {
+            if (![urlType isKindOfClass:[NSDictionary class]]) {
+                continue;
+            }
+
+            NSArray* schemes = ((NSDictionary*)urlType)[@"CFBundleURLSchemes"];
+            if (!schemes || ![schemes isKindOfClass:[NSArray class]]) {
+                continue;
+            }
+
+            for (id scheme in schemes) {
+                if ([scheme isKindOfClass:[NSString class]] && [(NSString*)scheme length] > 0) {
+                    // Schemes are case-insensitive
+                    [schemesSet addObject:[(NSString*)scheme lowercaseString]];
+                }
+            }
+        }
+
+        if ([schemesSet count] == 0) {
+            return BRIDGE_OK;
+        }
+
+        NSArray* sortedSchemes = [[schemesSet allObjects] sortedArrayUsingSelector:@selector(compare:)];
+        int count = (int)[sortedSchemes count];
+
+        char** schemes = (char**)calloc(count, sizeof(char*));
+        if (!schemes) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < count; i++) {
+            schemes[i] = NSStringToCString(sortedSchemes[i]);
+            if (!schemes[i]) {
+                FreeCStringArray(schemes, i);
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+        }
+
+        *outSchemes = schemes;
+        *outCount = count;
+        return BRIDGE_OK;
+    }
+}
+
+// Get the UTIs an application exports or imports
+int GetDeclaredUTIsForApp(const char* appPath, int imported, char*** outUTIs, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!appPath || !outUTIs || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        *outUTIs = NULL;
+        *outCount = 0;
+
+        int code = ValidateAppBundle(appPath, outError);
+        if (code != BRIDGE_OK) {
+            return code;
+        }
+
+        NSBundle* bundle = [NSBundle bundleWithURL:[NSURL fileURLWithPath:[NSString stringWithUTF8String:appPath]]];
+        if (!bundle) {
+            SetError(outError, [NSString stringWithFormat:@"Could not load application bundle: %s", appPath]);
+            return BRIDGE_ERROR_INVALID_APP;
+        }
+
+        NSString* key = imported ? @"UTImportedTypeDeclarations" : @"UTExportedTypeDeclarations";
+        NSMutableSet<NSString*>* utisSet = [NSMutableSet set];
+        AddDeclaredUTIs([bundle objectForInfoDictionaryKey:key], @"UTTypeIdentifier", utisSet);
+
+        if ([utisSet count] == 0) {
+            // Not an error - most apps don't declare types of their own
+            return BRIDGE_OK;
+        }
+
+        NSArray* sortedUTIs = [[utisSet allObjects] sortedArrayUsingSelector:@selector(compare:)];
+        int count = (int)[sortedUTIs count];
+
+        char** utis = (char**)calloc(count, sizeof(char*));
+        if (!utis) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < count; i++) {
+            utis[i] = NSStringToCString(sortedUTIs[i]);
+            if (!utis[i]) {
+                FreeCStringArray(utis, i);
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+        }
+
+        *outUTIs = utis;
+        *outCount = count;
+        return BRIDGE_OK;
+    }
+}
+
 // Free an array of DocumentType structures
 void FreeDocumentTypeArray(DocumentType** docTypes, int count) {
     if (docTypes) {
@@ -943,3 +3233,131 @@ void FreeDocumentTypeArray(DocumentType** docTypes, int count) {
         free(docTypes);
     }
 }
+
+// Helper function to create a HandlerPreference structure (caller must free)
+static HandlerPreference* NewHandlerPreference(NSString* kind, NSString* identifier, NSString* role, NSString* bundleID) {
+    HandlerPreference* pref = (HandlerPreference*)calloc(1, sizeof(HandlerPreference));
+    if (!pref) return NULL;
+
+    NSURL* appURL = [[NSWorkspace sharedWorkspace] URLForApplicationWithBundleIdentifier:bundleID];
+    NSString* appName = @"";
+    if (appURL) {
+        NSBundle* bundle = [NSBundle bundleWithURL:appURL];
+        appName = bundle ? AppNameForBundle(bundle, [appURL path]) : [[[appURL path] lastPathComponent] stringByDeletingPathExtension];
+    }
+
+    pref->kind = NSStringToCString(kind);
+    pref->identifier = NSStringToCString(identifier);
+    pref->role = NSStringToCString(role);
+    pref->bundleID = NSStringToCString(bundleID);
+    pref->appName = NSStringToCString(appName ?: @"");
+    pref->appPath = NSStringToCString(appURL ? [appURL path] : @"");
+    return pref;
+}
+
+// List the user's handler preferences stored by LaunchServices (LSHandlers)
+int ListHandlerPreferences(HandlerPreference*** outPrefs, int* outCount, char** outError) {
+    @autoreleasepool {
+        if (!outPrefs || !outCount) {
+            SetError(outError, @"Invalid parameters");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        *outPrefs = NULL;
+        *outCount = 0;
+
+        // Pick up changes written by other processes since the last read
+        CFPreferencesAppSynchronize(CFSTR("com.apple.LaunchServices/com.apple.launchservices.secure"));
+
+        CFPropertyListRef handlersRef = CFPreferencesCopyAppValue(CFSTR("LSHandlers"),
+                                                                  CFSTR("com.apple.LaunchServices/com.apple.launchservices.secure"));
+        NSArray* handlers = CFBridgingRelease(handlersRef);
+        if (!handlers || ![handlers isKindOfClass:[NSArray class]]) {
+            // Not an error - no defaults have been customized
+            return BRIDGE_OK;
+        }
+
+        NSDictionary<NSString*, NSString*>* roleKeys = @{
+            @"LSHandlerRoleAll": @"All",
+            @"LSHandlerRoleViewer": @"Viewer",
+            @"LSHandlerRoleEditor": @"Editor",
+            @"LSHandlerRoleShell": @"Shell"
+        };
+
+        NSMutableArray<NSArray<NSString*>*>* entries = [NSMutableArray array];
+
+        for (id handler in handlers) {
+            if (![handler isKindOfClass:[NSDictionary class]]) {
+                continue;
+            }
+
+            NSDictionary* handlerDict = (NSDictionary*)handler;
+            NSString* kind = nil;
+            NSString* identifier = nil;
+
+            if ([handlerDict[@"LSHandlerContentType"] isKindOfClass:[NSString class]]) {
+                kind = @"uti";
+                identifier = handlerDict[@"LSHandlerContentType"];
+            } else if ([handlerDict[@"LSHandlerURLScheme"] isKindOfClass:[NSString class]]) {
+                kind = @"scheme";
+                identifier = [handlerDict[@"LSHandlerURLScheme"] lowercaseString];
+            } else {
+                // Entries keyed by content tag (e.g. extension) are not reported
+                continue;
+            }
+
+            for (NSString* roleKey in roleKeys) {
+                id bundleID = handlerDict[roleKey];
+                // "-" marks a role explicitly left without a handler
+                if (![bundleID isKindOfClass:[NSString class]] || [(NSString*)bundleID length] == 0 || [bundleID isEqualToString:@"-"]) {
+                    continue;
+                }
+
+                [entries addObject:@[kind, identifier, roleKeys[roleKey], bundleID]];
+            }
+        }
+
+        int count = (int)[entries count];
+        if (count == 0) {
+            return BRIDGE_OK;
+        }
+
+        HandlerPreference** prefs = (HandlerPreference**)calloc(count, sizeof(HandlerPreference*));
+        if (!prefs) {
+            SetError(outError, @"Memory allocation failed");
+            return BRIDGE_ERROR_SYSTEM;
+        }
+
+        for (int i = 0; i < count; i++) {
+            NSArray<NSString*>* entry = entries[i];
+            prefs[i] = NewHandlerPreference(entry[0], entry[1], entry[2], entry[3]);
+            if (!prefs[i]) {
+                FreeHandlerPreferenceArray(prefs, i);
+                SetError(outError, @"Memory allocation failed");
+                return BRIDGE_ERROR_SYSTEM;
+            }
+        }
+
+        *outPrefs = prefs;
+        *outCount = count;
+        return BRIDGE_OK;
+    }
+}
+
+// Free an array of HandlerPreference structures
+void FreeHandlerPreferenceArray(HandlerPreference** prefs, int count) {
+    if (prefs) {
+        for (int i = 0; i < count; i++) {
+            if (prefs[i]) {
+                if (prefs[i]->kind) free(prefs[i]->kind);
+                if (prefs[i]->identifier) free(prefs[i]->identifier);
+                if (prefs[i]->role) free(prefs[i]->role);
+                if (prefs[i]->bundleID) free(prefs[i]->bundleID);
+                if (prefs[i]->appName) free(prefs[i]->appName);
+                if (prefs[i]->appPath) free(prefs[i]->appPath);
+                free(prefs[i]);
+            }
+        }
+        free(prefs);
+    }
+}
@@ -0,0 +1,554 @@
+//go:build !darwin
+
+package bridge
+
+import (
+	"context"
+	"strings"
+)
+
+// This file lets the package build on platforms other than macOS. Every
+// operation returns ErrUnsupportedPlatform so that importing code compiles and
+// can unit-test its platform-independent logic.
+
+// ValidateAppBundle checks that a path points to a loadable application bundle
+func (h *systemHandler) ValidateAppBundle(appPath string) error {
+	return ErrUnsupportedPlatform
+}
+
+// GetDefaultAppForUTI returns the default application path for a UTI
+func (h *systemHandler) GetDefaultAppForUTI(uti string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetDefaultAppsForUTIs returns the default application paths for several UTIs in a single call
+func (h *systemHandler) GetDefaultAppsForUTIs(utis []string) (map[string]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// GetDefaultAppInfoForUTI returns the default application for a UTI with its name and bundle ID
+func (h *systemHandler) GetDefaultAppInfoForUTI(uti string) (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// GetDefaultAppForScheme returns the default application path for a URL scheme
+func (h *systemHandler) GetDefaultAppForScheme(scheme string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetDefaultAppInfoForScheme returns the default application for a URL scheme with its name and bundle ID
+func (h *systemHandler) GetDefaultAppInfoForScheme(scheme string) (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// GetDefaultAppForURLContentType returns the default application for copied URLs (public.url)
+func (h *systemHandler) GetDefaultAppForURLContentType() (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetDefaultAppForExtension returns the default application path for a file extension
+func (h *systemHandler) GetDefaultAppForExtension(extension string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetDefaultAppForFile returns the application that opens a file on this machine
+func (h *systemHandler) GetDefaultAppForFile(filePath string) (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// IsDefaultAppForUTI reports whether an application is the current default handler for a UTI
+func (h *systemHandler) IsDefaultAppForUTI(appPath, uti string) (bool, error) {
+	return false, ErrUnsupportedPlatform
+}
+
+// SetDefaultForUTI sets the default application for a UTI
+func (h *systemHandler) SetDefaultForUTI(appPath, uti string) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForUTIForce sets the default application for a UTI even if the app does not declare it
+func (h *systemHandler) SetDefaultForUTIForce(appPath, uti string) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForUTIVerified sets the default application for a UTI only if the app's code signature is valid
+func (h *systemHandler) SetDefaultForUTIVerified(appPath, uti string) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForUTIConfirmed sets the default application for a UTI and checks that the change took effect
+func (h *systemHandler) SetDefaultForUTIConfirmed(appPath, uti string) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForUTIWithRole sets the default application for a UTI in a single role
+func (h *systemHandler) SetDefaultForUTIWithRole(appPath, uti string, role Role) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForUTIReturningPrevious sets the default application for a UTI and returns the one it replaced
+func (h *systemHandler) SetDefaultForUTIReturningPrevious(appPath, uti string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// SetDefaultForExtension sets the default application for a file extension
+func (h *systemHandler) SetDefaultForExtension(appPath, extension string) error {
+	return ErrUnsupportedPlatform
+}
+
+// ResetDefaultForUTI clears the user's default application override for a UTI
+func (h *systemHandler) ResetDefaultForUTI(uti string) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForScheme sets the default application for a URL scheme
+func (h *systemHandler) SetDefaultForScheme(appPath, scheme string) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForSchemeVerified sets the default application for a URL scheme and checks that the change took effect
+func (h *systemHandler) SetDefaultForSchemeVerified(appPath, scheme string) error {
+	return ErrUnsupportedPlatform
+}
+
+// ResetDefaultForScheme clears the user's default application override for a URL scheme
+func (h *systemHandler) ResetDefaultForScheme(scheme string) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForUTIByBundleID sets the default application for a UTI, identifying the app by bundle ID
+func (h *systemHandler) SetDefaultForUTIByBundleID(bundleID, uti string) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForExtensionByBundleID sets the default application for a file extension, identifying the app by bundle ID
+func (h *systemHandler) SetDefaultForExtensionByBundleID(bundleID, extension string) error {
+	return ErrUnsupportedPlatform
+}
+
+// SetDefaultForSchemeByBundleID sets the default application for a URL scheme, identifying the app by bundle ID
+func (h *systemHandler) SetDefaultForSchemeByBundleID(bundleID, scheme string) error {
+	return ErrUnsupportedPlatform
+}
+
+// GetDefaultBrowser returns the default web browser
+func (h *systemHandler) GetDefaultBrowser() (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// SetDefaultBrowser makes an application the default web browser
+func (h *systemHandler) SetDefaultBrowser(appPath string) error {
+	return ErrUnsupportedPlatform
+}
+
+// GetDefaultMailClient returns the default mail client
+func (h *systemHandler) GetDefaultMailClient() (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// SetDefaultMailClient makes an application the default mail client
+func (h *systemHandler) SetDefaultMailClient(appPath string) error {
+	return ErrUnsupportedPlatform
+}
+
+// GetDefaultTextEditor returns the default text editor
+func (h *systemHandler) GetDefaultTextEditor() (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// SetDefaultTextEditor makes an application the default text editor
+func (h *systemHandler) SetDefaultTextEditor(appPath string) error {
+	return ErrUnsupportedPlatform
+}
+
+// GetDefaultTerminal returns the default terminal application
+func (h *systemHandler) GetDefaultTerminal() (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// OpenFile opens a file with its default application
+func (h *systemHandler) OpenFile(filePath string) error {
+	return ErrUnsupportedPlatform
+}
+
+// OpenFileWithApp opens a file with a specific application, regardless of the default
+func (h *systemHandler) OpenFileWithApp(filePath, appPath string) error {
+	return ErrUnsupportedPlatform
+}
+
+// OpenFilesWithApp opens several files with a specific application in a single launch
+func (h *systemHandler) OpenFilesWithApp(appPath string, filePaths []string) error {
+	return ErrUnsupportedPlatform
+}
+
+// ResolveUTIsForExtension resolves a file extension to one or more UTIs
+func (h *systemHandler) ResolveUTIsForExtension(extension string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ResolveUTIsForMIMEType returns the UTIs registered for a MIME type
+func (h *systemHandler) ResolveUTIsForMIMEType(mimeType string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ResolveUTIForFile returns the UTI of a file on disk
+func (h *systemHandler) ResolveUTIForFile(filePath string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// ResolveExtensionsForUTI returns all file extensions associated with a UTI
+func (h *systemHandler) ResolveExtensionsForUTI(uti string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ResolveExtensionsForUTIs returns the file extensions of several UTIs in a single call
+func (h *systemHandler) ResolveExtensionsForUTIs(utis []string) (map[string][]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// PreferredExtensionForUTI returns the canonical file extension for a UTI
+func (h *systemHandler) PreferredExtensionForUTI(uti string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// IsDynamicUTI reports whether a UTI is a dynamic (dyn.*) placeholder type
+//
+// Without the type system only the "dyn." prefix can be checked.
+func (h *systemHandler) IsDynamicUTI(uti string) bool {
+	return strings.HasPrefix(uti, "dyn.")
+}
+
+// IsRegisteredUTI reports whether a UTI is a type declared by the system or an installed application
+func (h *systemHandler) IsRegisteredUTI(uti string) (bool, error) {
+	return false, ErrUnsupportedPlatform
+}
+
+// ResolveUTIsForExtensionDeclaredOnly resolves a file extension to its UTIs, excluding dynamic types
+func (h *systemHandler) ResolveUTIsForExtensionDeclaredOnly(extension string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// PreferredUTIForExtension resolves a file extension to the single UTI the system prefers for it
+func (h *systemHandler) PreferredUTIForExtension(extension string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetTagSpecification returns every filename extension, MIME type, pasteboard type and OSType of a UTI
+func (h *systemHandler) GetTagSpecification(uti string) (TagSpec, error) {
+	return TagSpec{}, ErrUnsupportedPlatform
+}
+
+// ResolveMIMETypesForUTI returns all MIME types associated with a UTI
+func (h *systemHandler) ResolveMIMETypesForUTI(uti string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ExtensionsShareUTI reports whether two file extensions resolve to the same preferred UTI
+func (h *systemHandler) ExtensionsShareUTI(extA, extB string) (bool, error) {
+	return false, ErrUnsupportedPlatform
+}
+
+// ConformsTo reports whether a UTI conforms to another UTI
+func (h *systemHandler) ConformsTo(uti, parentUTI string) (bool, error) {
+	return false, ErrUnsupportedPlatform
+}
+
+// GetConformingUTIs returns every UTI that a type conforms to
+func (h *systemHandler) GetConformingUTIs(uti string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// GetUTIDescription returns the human-readable description of a UTI
+func (h *systemHandler) GetUTIDescription(uti string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetSchemeDescription returns a human-readable name for a URL scheme
+func (h *systemHandler) GetSchemeDescription(scheme string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetUTIIconPNG renders the generic document icon for a UTI as PNG data
+func (h *systemHandler) GetUTIIconPNG(uti string, size int) ([]byte, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAppsForUTI returns all applications that can open a UTI
+func (h *systemHandler) ListAppsForUTI(uti string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAppInfosForUTI returns all applications that can open a UTI along with their names and bundle IDs
+func (h *systemHandler) ListAppInfosForUTI(uti string) ([]AppInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAppsForUTIWithRoles returns the applications that can open a UTI with the rank and role each declares for it
+func (h *systemHandler) ListAppsForUTIWithRoles(uti string) ([]RankedApp, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// FindConflictingHandlers returns the apps that share the strongest handler rank claimed for a UTI
+func (h *systemHandler) FindConflictingHandlers(uti string) ([]AppInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAppsForUTIWithRole returns the applications that can open a UTI in a given role
+func (h *systemHandler) ListAppsForUTIWithRole(uti string, role Role) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAppsForUTIContext is like ListAppsForUTI but returns ctx.Err() once ctx is done
+func (h *systemHandler) ListAppsForUTIContext(ctx context.Context, uti string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAppsForUTIs returns the applications that can open each of several UTIs in a single call
+func (h *systemHandler) ListAppsForUTIs(utis []string) (map[string][]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// AppsHandlingAllUTIs returns the applications that can open every one of several UTIs
+func (h *systemHandler) AppsHandlingAllUTIs(utis []string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// AppsHandlingAnyUTI returns the applications that can open at least one of several UTIs
+func (h *systemHandler) AppsHandlingAnyUTI(utis []string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAppsForScheme returns all applications that can handle a URL scheme
+func (h *systemHandler) ListAppsForScheme(scheme string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAllApplications returns all installed applications on the system
+func (h *systemHandler) ListAllApplications() ([]AppInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ForEachApplication calls fn for every installed application
+func (h *systemHandler) ForEachApplication(fn func(app AppInfo) bool) error {
+	return ErrUnsupportedPlatform
+}
+
+// ListAllApplicationsContext is like ListAllApplications but returns ctx.Err() once ctx is done
+func (h *systemHandler) ListAllApplicationsContext(ctx context.Context) ([]AppInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAllRegisteredUTIs returns every UTI registered by installed applications
+func (h *systemHandler) ListAllRegisteredUTIs() ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAllRegisteredUTIsFunc calls fn for every UTI registered by installed applications
+func (h *systemHandler) ListAllRegisteredUTIsFunc(fn func(uti string) bool) error {
+	return ErrUnsupportedPlatform
+}
+
+// ListAllSchemes returns every URL scheme declared by an installed application
+func (h *systemHandler) ListAllSchemes() ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAllDeclaredUTIs returns every UTI an installed application declares it can open
+func (h *systemHandler) ListAllDeclaredUTIs() ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// FindAppByBundleID finds an installed application by its bundle identifier
+func (h *systemHandler) FindAppByBundleID(bundleID string) (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// GetAppInfoForPID returns the application bundle that owns a running process
+func (h *systemHandler) GetAppInfoForPID(pid int) (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// GetFrontmostApp returns the application the user is currently working in
+func (h *systemHandler) GetFrontmostApp() (AppInfo, error) {
+	return AppInfo{}, ErrUnsupportedPlatform
+}
+
+// IsAppRunning reports whether an application is running
+func (h *systemHandler) IsAppRunning(bundleID string) (bool, error) {
+	return false, ErrUnsupportedPlatform
+}
+
+// ActivateApp brings a running application to the foreground
+func (h *systemHandler) ActivateApp(bundleID string) error {
+	return ErrUnsupportedPlatform
+}
+
+// QuitApp asks every running instance of an application to quit
+func (h *systemHandler) QuitApp(bundleID string, force bool) error {
+	return ErrUnsupportedPlatform
+}
+
+// GetBundleID returns the bundle identifier of the application at a path
+func (h *systemHandler) GetBundleID(appPath string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetAppIconPNG renders an application's icon as PNG data
+func (h *systemHandler) GetAppIconPNG(appPath string, size int) ([]byte, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListAllApplicationsDeduplicated is like ListAllApplications but returns each bundle ID only once
+func (h *systemHandler) ListAllApplicationsDeduplicated() ([]AppInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// FindAppsByName returns the installed applications whose display name contains a query
+func (h *systemHandler) FindAppsByName(query string) ([]AppInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListApplicationsByCategory returns the installed applications in an App Store category
+func (h *systemHandler) ListApplicationsByCategory(category string) ([]AppInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListApplicationsInDirectory returns the applications directly inside a directory
+func (h *systemHandler) ListApplicationsInDirectory(dir string) ([]AppInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// RebuildLaunchServicesDatabase registers every application in the standard directories with LaunchServices
+func (h *systemHandler) RebuildLaunchServicesDatabase() error {
+	return ErrUnsupportedPlatform
+}
+
+// WatchApplicationChanges reports applications being installed or removed
+func (h *systemHandler) WatchApplicationChanges() (<-chan AppChangeEvent, func(), error) {
+	return nil, nil, ErrUnsupportedPlatform
+}
+
+// RegisterApp registers a single application bundle with LaunchServices
+func (h *systemHandler) RegisterApp(appPath string) error {
+	return ErrUnsupportedPlatform
+}
+
+// GetBundleInfoValue reads a top-level value from an application's Info.plist
+func (h *systemHandler) GetBundleInfoValue(appPath, key string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetAppCategory returns an application's App Store category
+func (h *systemHandler) GetAppCategory(appPath string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// IsSandboxed reports whether an application runs in the App Sandbox
+func (h *systemHandler) IsSandboxed(appPath string) (bool, error) {
+	return false, ErrUnsupportedPlatform
+}
+
+// GetCodeSigningTeamID returns the Team Identifier from an application's code signature
+func (h *systemHandler) GetCodeSigningTeamID(appPath string) (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+// GetAppSummary returns an application's metadata along with counts of the document types it handles
+func (h *systemHandler) GetAppSummary(appPath string) (AppSummary, error) {
+	return AppSummary{}, ErrUnsupportedPlatform
+}
+
+// GetAppProfile returns everything the bridge knows about what an application handles
+func (h *systemHandler) GetAppProfile(appPath string) (AppProfile, error) {
+	return AppProfile{}, ErrUnsupportedPlatform
+}
+
+// GetAllHandlersForUTIByRole returns the applications that can handle a UTI, grouped by role
+func (h *systemHandler) GetAllHandlersForUTIByRole(uti string) (map[string][]AppInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListSupportedSchemes returns the URL schemes an application declares in CFBundleURLTypes
+func (h *systemHandler) ListSupportedSchemes(appPath string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListExportedUTIs returns the UTIs an application defines in UTExportedTypeDeclarations
+func (h *systemHandler) ListExportedUTIs(appPath string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListImportedUTIs returns the UTIs an application declares in UTImportedTypeDeclarations
+func (h *systemHandler) ListImportedUTIs(appPath string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListDefaultSchemes returns the URL schemes for which the given application is the system default
+func (h *systemHandler) ListDefaultSchemes(appPath string) ([]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListDefaultDocumentTypes returns all document types where the given application is the system default
+func (h *systemHandler) ListDefaultDocumentTypes(appPath string) ([]DocumentType, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListSupportedDocumentTypes returns all document types that an application can handle
+func (h *systemHandler) ListSupportedDocumentTypes(appPath string, opts ...DocumentTypeOption) ([]DocumentType, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// ListSupportedDocumentTypesByRole returns the document types an application declares with a given role
+func (h *systemHandler) ListSupportedDocumentTypesByRole(appPath string, role Role) ([]DocumentType, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// GetDocumentTypeForUTI returns the document type an application declares for a UTI
+func (h *systemHandler) GetDocumentTypeForUTI(appPath, uti string) (DocumentType, error) {
+	return DocumentType{}, ErrUnsupportedPlatform
+}
+
+// AppSupportsUTI reports whether an application can handle a UTI
+func (h *systemHandler) AppSupportsUTI(appPath, uti string) (bool, error) {
+	return false, ErrUnsupportedPlatform
+}
+
+// GroupSupportedDocumentTypesByFamily groups the document types an application can handle by family
+func (h *systemHandler) GroupSupportedDocumentTypesByFamily(appPath string) (map[TypeFamily][]DocumentType, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// CheckSchemeHandlerConsistency reports whether the default handler for a URL scheme still declares it
+func (h *systemHandler) CheckSchemeHandlerConsistency(scheme string) (bool, string, error) {
+	return false, "", ErrUnsupportedPlatform
+}
+
+// GetAllDefaultHandlers returns the default application for every UTI with an explicit handler
+func (h *systemHandler) GetAllDefaultHandlers() (map[string]string, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// SnapshotDefaults captures the current default handlers for later restoration
+func (h *systemHandler) SnapshotDefaults() (HandlerSnapshot, error) {
+	return HandlerSnapshot{}, ErrUnsupportedPlatform
+}
+
+// RestoreDefaults applies the default handlers recorded in a snapshot
+func (h *systemHandler) RestoreDefaults(snapshot HandlerSnapshot) error {
+	return ErrUnsupportedPlatform
+}
+
+// ResolveSnapshot resolves the bundle IDs in a snapshot to the apps installed on this machine
+func (h *systemHandler) ResolveSnapshot(snapshot HandlerSnapshot) (HandlerSnapshot, []string, error) {
+	return HandlerSnapshot{}, nil, ErrUnsupportedPlatform
+}
+
+// WatchDefaultChanges reports changes to the user's default handlers as they happen
+func (h *systemHandler) WatchDefaultChanges() (<-chan HandlerChangeEvent, func(), error) {
+	return nil, nil, ErrUnsupportedPlatform
+}
+
+// DumpHandlerConfiguration returns a human-readable report of the user's default handlers
+func (h *systemHandler) DumpHandlerConfiguration() (string, error) {
+	return "", ErrUnsupportedPlatform
+}
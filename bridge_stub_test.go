@@ -0,0 +1,27 @@
+//go:build !darwin
+
+package bridge
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestUnsupportedPlatform tests that the stub reports ErrUnsupportedPlatform
+func TestUnsupportedPlatform(t *testing.T) {
+	if _, err := GetDefaultAppForUTI("public.plain-text"); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("GetDefaultAppForUTI() error = %v, want ErrUnsupportedPlatform", err)
+	}
+
+	if err := SetDefaultForUTI("/Applications/TextEdit.app", "public.plain-text"); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("SetDefaultForUTI() error = %v, want ErrUnsupportedPlatform", err)
+	}
+
+	if apps, err := ListAllApplications(); apps != nil || !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("ListAllApplications() = %v, %v, want nil, ErrUnsupportedPlatform", apps, err)
+	}
+
+	if !IsDynamicUTI("dyn.ah62d4rv4ge80e5pe") || IsDynamicUTI("public.plain-text") {
+		t.Errorf("IsDynamicUTI() does not fall back to the dyn. prefix check")
+	}
+}
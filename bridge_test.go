@@ -3,10 +3,21 @@
 package bridge
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/png"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 )
 
 // Common macOS applications for testing
@@ -78,6 +89,124 @@ func TestGetDefaultAppForUTI(t *testing.T) {
 	}
 }
 
+// TestGetDefaultAppsForUTIs tests resolving defaults for several UTIs in one batch
+func TestGetDefaultAppsForUTIs(t *testing.T) {
+	utis := []string{"public.plain-text", "public.html", "invalid.nonexistent.type.12345"}
+
+	defaults, err := GetDefaultAppsForUTIs(utis)
+	if err != nil {
+		t.Fatalf("GetDefaultAppsForUTIs() error = %v", err)
+	}
+
+	for _, uti := range utis[:2] {
+		want, err := GetDefaultAppForUTI(uti)
+		if err != nil {
+			t.Fatalf("GetDefaultAppForUTI(%s) error = %v", uti, err)
+		}
+
+		got, ok := defaults[uti]
+		if !ok {
+			t.Errorf("GetDefaultAppsForUTIs() missing default for %s", uti)
+			continue
+		}
+
+		if !pathsMatch(got, want) {
+			t.Errorf("GetDefaultAppsForUTIs()[%s] = %s, want %s", uti, got, want)
+		}
+
+		t.Logf("Default app for %s: %s", uti, got)
+	}
+
+	if _, ok := defaults["invalid.nonexistent.type.12345"]; ok {
+		t.Errorf("GetDefaultAppsForUTIs() included an entry for an unknown UTI")
+	}
+
+	// public.url falls back to the http handler, in the batch as well
+	want, wantErr := GetDefaultAppForUTI("public.url")
+	urlDefaults, err := GetDefaultAppsForUTIs([]string{"public.url"})
+	if err != nil {
+		t.Fatalf("GetDefaultAppsForUTIs(public.url) error = %v", err)
+	}
+	got, ok := urlDefaults["public.url"]
+	if ok != (wantErr == nil) || (ok && !pathsMatch(got, want)) {
+		t.Errorf("GetDefaultAppsForUTIs()[public.url] = %q (present %v), GetDefaultAppForUTI() = %q, %v", got, ok, want, wantErr)
+	}
+
+	empty, err := GetDefaultAppsForUTIs(nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("GetDefaultAppsForUTIs(nil) = %v, %v, want empty map", empty, err)
+	}
+}
+
+// TestGetDefaultAppInfoForUTI tests reading the default app's metadata for a UTI
+func TestGetDefaultAppInfoForUTI(t *testing.T) {
+	tests := []struct {
+		name     string
+		uti      string
+		wantErr  bool
+		wantCode int
+	}{
+		{
+			name:    "plain text",
+			uti:     "public.plain-text",
+			wantErr: false,
+		},
+		{
+			name:    "HTML",
+			uti:     "public.html",
+			wantErr: false,
+		},
+		{
+			// Falls back to the http handler, as in GetDefaultAppForUTI
+			name:    "URL",
+			uti:     "public.url",
+			wantErr: false,
+		},
+		{
+			name:     "invalid UTI",
+			uti:      "invalid.nonexistent.type.12345",
+			wantErr:  true,
+			wantCode: int(ErrInvalidUTI),
+		},
+		{
+			name:    "empty UTI",
+			uti:     "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := GetDefaultAppInfoForUTI(tt.uti)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetDefaultAppInfoForUTI() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				if bridgeErr, ok := err.(*BridgeError); ok && tt.wantCode != 0 && bridgeErr.Code != tt.wantCode {
+					t.Errorf("GetDefaultAppInfoForUTI() error code = %d, want %d", bridgeErr.Code, tt.wantCode)
+				}
+				return
+			}
+
+			if app.Name == "" || app.Path == "" || app.BundleID == "" {
+				t.Errorf("GetDefaultAppInfoForUTI() returned app with missing fields: %+v", app)
+			}
+
+			appPath, err := GetDefaultAppForUTI(tt.uti)
+			if err != nil {
+				t.Fatalf("GetDefaultAppForUTI() error = %v", err)
+			}
+			if !pathsMatch(app.Path, appPath) {
+				t.Errorf("GetDefaultAppInfoForUTI() path = %s, GetDefaultAppForUTI() = %s", app.Path, appPath)
+			}
+
+			t.Logf("Default app for %s: %s (%s)", tt.uti, app.Name, app.BundleID)
+		})
+	}
+}
+
 // TestGetDefaultAppForScheme tests reading default app for URL schemes
 func TestGetDefaultAppForScheme(t *testing.T) {
 	tests := []struct {
@@ -132,6 +261,91 @@ func TestGetDefaultAppForScheme(t *testing.T) {
 	}
 }
 
+// TestGetDefaultAppInfoForScheme tests reading the default app's metadata for URL schemes
+func TestGetDefaultAppInfoForScheme(t *testing.T) {
+	tests := []struct {
+		name     string
+		scheme   string
+		wantErr  error
+		wantCode int
+	}{
+		{
+			name:   "http",
+			scheme: "http",
+		},
+		{
+			name:   "mailto",
+			scheme: "mailto",
+		},
+		{
+			name:     "bogus scheme",
+			scheme:   "bogus-scheme-12345",
+			wantCode: int(ErrNotFound),
+		},
+		{
+			name:    "empty scheme",
+			scheme:  "",
+			wantErr: ErrInvalidParameters,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := GetDefaultAppInfoForScheme(tt.scheme)
+
+			if tt.wantErr != nil {
+				if err != tt.wantErr {
+					t.Errorf("GetDefaultAppInfoForScheme() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if tt.wantCode != 0 {
+				bridgeErr, ok := err.(*BridgeError)
+				if !ok || bridgeErr.Code != tt.wantCode {
+					t.Errorf("GetDefaultAppInfoForScheme() error = %v, want code %d", err, tt.wantCode)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetDefaultAppInfoForScheme() error = %v", err)
+			}
+
+			if app.Name == "" || app.Path == "" || app.BundleID == "" {
+				t.Errorf("GetDefaultAppInfoForScheme() returned app with missing fields: %+v", app)
+			}
+
+			t.Logf("Default app for %s: %s (%s)", tt.scheme, app.Name, app.BundleID)
+		})
+	}
+}
+
+// TestGetDefaultAppForURLContentTypes tests the scheme fallback for URL content types
+func TestGetDefaultAppForURLContentTypes(t *testing.T) {
+	for _, uti := range []string{"public.url", "public.file-url"} {
+		t.Run(uti, func(t *testing.T) {
+			appPath, err := GetDefaultAppForUTI(uti)
+			if err != nil {
+				t.Fatalf("GetDefaultAppForUTI(%q) error = %v", uti, err)
+			}
+
+			if appPath == "" {
+				t.Errorf("GetDefaultAppForUTI(%q) returned empty path", uti)
+			}
+
+			t.Logf("Default app for %s: %s", uti, appPath)
+		})
+	}
+
+	appPath, err := GetDefaultAppForURLContentType()
+	if err != nil {
+		t.Fatalf("GetDefaultAppForURLContentType() error = %v", err)
+	}
+
+	t.Logf("Default app for copied URLs: %s", appPath)
+}
+
 // TestResolveExtensionsForUTI tests resolving file extensions for a UTI
 func TestResolveExtensionsForUTI(t *testing.T) {
 	tests := []struct {
@@ -208,11 +422,150 @@ func TestResolveExtensionsForUTI(t *testing.T) {
 				}
 			}
 
+			for i := 1; i < len(extensions); i++ {
+				if extensions[i-1] >= extensions[i] {
+					t.Errorf("ResolveExtensionsForUTI() = %v, want sorted without duplicates", extensions)
+					break
+				}
+			}
+
 			t.Logf("Extensions for %s: %v", tt.uti, extensions)
 		})
 	}
 }
 
+// TestIdentifierWhitespace tests that surrounding whitespace is trimmed from UTI and scheme arguments
+func TestIdentifierWhitespace(t *testing.T) {
+	want, err := ResolveExtensionsForUTI("public.plain-text")
+	if err != nil {
+		t.Fatalf("ResolveExtensionsForUTI() error = %v", err)
+	}
+
+	got, err := ResolveExtensionsForUTI(" public.plain-text\n")
+	if err != nil {
+		t.Fatalf("ResolveExtensionsForUTI() with whitespace error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveExtensionsForUTI() with whitespace = %v, want %v", got, want)
+	}
+
+	if _, err := GetDefaultAppForScheme("https\t"); err != nil {
+		t.Errorf("GetDefaultAppForScheme() with whitespace error = %v", err)
+	}
+
+	conforms, err := ConformsTo("public.plain-text ", " public.text")
+	if err != nil || !conforms {
+		t.Errorf("ConformsTo() with whitespace = %v, %v, want true", conforms, err)
+	}
+
+	batch, err := ResolveExtensionsForUTIs([]string{"public.plain-text\n"})
+	if err != nil {
+		t.Fatalf("ResolveExtensionsForUTIs() error = %v", err)
+	}
+	if !reflect.DeepEqual(batch["public.plain-text\n"], want) {
+		t.Errorf("ResolveExtensionsForUTIs() = %v, want entry keyed by the original string", batch)
+	}
+
+	if _, err := GetDefaultAppForUTI(" \n"); err != ErrInvalidParameters {
+		t.Errorf("GetDefaultAppForUTI(whitespace) error = %v, want ErrInvalidParameters", err)
+	}
+	if err := ResetDefaultForScheme("  "); err != ErrInvalidParameters {
+		t.Errorf("ResetDefaultForScheme(whitespace) error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestResolveExtensionsForUTIs tests resolving extensions for several UTIs in one call
+func TestResolveExtensionsForUTIs(t *testing.T) {
+	utis := []string{"public.html", "public.plain-text", "public.folder", "com.example.nonexistent", ""}
+
+	extensions, err := ResolveExtensionsForUTIs(utis)
+	if err != nil {
+		t.Fatalf("ResolveExtensionsForUTIs() error = %v", err)
+	}
+
+	// Each entry must match the single-UTI lookup
+	for _, uti := range []string{"public.html", "public.plain-text"} {
+		want, err := ResolveExtensionsForUTI(uti)
+		if err != nil {
+			t.Fatalf("ResolveExtensionsForUTI(%s) error = %v", uti, err)
+		}
+		if !reflect.DeepEqual(extensions[uti], want) {
+			t.Errorf("ResolveExtensionsForUTIs()[%s] = %v, want %v", uti, extensions[uti], want)
+		}
+	}
+
+	for _, uti := range []string{"public.folder", "com.example.nonexistent", ""} {
+		if got, ok := extensions[uti]; ok {
+			t.Errorf("ResolveExtensionsForUTIs()[%q] = %v, want no entry", uti, got)
+		}
+	}
+
+	empty, err := ResolveExtensionsForUTIs(nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("ResolveExtensionsForUTIs(nil) = %v, %v, want empty map", empty, err)
+	}
+}
+
+// TestPreferredExtensionForUTI tests resolving a UTI to its canonical extension
+func TestPreferredExtensionForUTI(t *testing.T) {
+	tests := []struct {
+		uti  string
+		want string
+	}{
+		{"public.jpeg", "jpg"},
+		{"public.html", "html"},
+		{"public.plain-text", "txt"},
+	}
+
+	for _, tt := range tests {
+		got, err := PreferredExtensionForUTI(tt.uti)
+		if err != nil {
+			t.Errorf("PreferredExtensionForUTI(%s) error = %v", tt.uti, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("PreferredExtensionForUTI(%s) = %q, want %q", tt.uti, got, tt.want)
+		}
+	}
+
+	if _, err := PreferredExtensionForUTI("public.folder"); !hasErrorCode(err, ErrNotFound) {
+		t.Errorf("PreferredExtensionForUTI(public.folder) error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := PreferredExtensionForUTI(""); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("PreferredExtensionForUTI(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestGetTagSpecification tests reading every tag class of a UTI in one call
+func TestGetTagSpecification(t *testing.T) {
+	spec, err := GetTagSpecification("public.jpeg")
+	if err != nil {
+		t.Fatalf("GetTagSpecification(public.jpeg) error = %v", err)
+	}
+	if !contains(spec.Extensions, "jpg") {
+		t.Errorf("Extensions = %v, want to contain jpg", spec.Extensions)
+	}
+	if !contains(spec.MIMETypes, "image/jpeg") {
+		t.Errorf("MIMETypes = %v, want to contain image/jpeg", spec.MIMETypes)
+	}
+	if spec.PasteboardTypes == nil || spec.OSTypes == nil {
+		t.Errorf("GetTagSpecification() = %+v, want non-nil slices for every class", spec)
+	}
+
+	folder, err := GetTagSpecification("public.folder")
+	if err != nil {
+		t.Fatalf("GetTagSpecification(public.folder) error = %v", err)
+	}
+	if folder.Extensions == nil || len(folder.Extensions) != 0 {
+		t.Errorf("GetTagSpecification(public.folder).Extensions = %#v, want empty slice", folder.Extensions)
+	}
+
+	if _, err := GetTagSpecification(""); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("GetTagSpecification(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+}
+
 // TestResolveUTIsForExtension tests extension to UTI resolution
 func TestResolveUTIsForExtension(t *testing.T) {
 	tests := []struct {
@@ -250,11 +603,23 @@ func TestResolveUTIsForExtension(t *testing.T) {
 			minUTICount: 1,
 			wantErr:     false,
 		},
+		{
+			name:        "leading dot and upper case",
+			extension:   ".TXT",
+			wantUTIs:    []string{"public.plain-text"},
+			minUTICount: 1,
+			wantErr:     false,
+		},
 		{
 			name:      "empty extension",
 			extension: "",
 			wantErr:   true,
 		},
+		{
+			name:      "dot only",
+			extension: ".",
+			wantErr:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -300,361 +665,3224 @@ func TestResolveUTIsForExtension(t *testing.T) {
 	}
 }
 
-// TestSetDefaultForUTI tests setting default app for UTI with round-trip
-func TestSetDefaultForUTI(t *testing.T) {
-	// This test requires TextEdit to be available
-	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
-		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
-	}
-
-	// Use a specific UTI for testing
-	testUTI := "public.plain-text"
-
-	// Get current default to restore later
-	originalApp, err := GetDefaultAppForUTI(testUTI)
-	if err != nil {
-		t.Fatalf("Failed to get original default app: %v", err)
-	}
-
-	// Ensure we restore the original default
-	defer func() {
-		if originalApp != "" {
-			_ = SetDefaultForUTI(originalApp, testUTI)
-		}
-	}()
-
-	t.Logf("Original default app for %s: %s", testUTI, originalApp)
-
-	// Set TextEdit as default
-	err = SetDefaultForUTI(textEditPath, testUTI)
+// TestResolveUTIsForExtensionNormalized tests that a dotted, upper-case extension resolves like its plain form
+func TestResolveUTIsForExtensionNormalized(t *testing.T) {
+	want, err := ResolveUTIsForExtension("txt")
 	if err != nil {
-		t.Fatalf("SetDefaultForUTI() error = %v", err)
+		t.Fatalf("ResolveUTIsForExtension(txt) error = %v", err)
 	}
 
-	// Verify it was set
-	currentApp, err := GetDefaultAppForUTI(testUTI)
+	got, err := ResolveUTIsForExtension(".TXT")
 	if err != nil {
-		t.Fatalf("Failed to verify default app: %v", err)
+		t.Fatalf("ResolveUTIsForExtension(.TXT) error = %v", err)
 	}
 
-	// Normalize paths for comparison (resolve symlinks, etc.)
-	if !pathsMatch(currentApp, textEditPath) {
-		t.Errorf("SetDefaultForUTI() verification failed: got %s, want %s", currentApp, textEditPath)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ResolveUTIsForExtension(.TXT) = %v, want %v", got, want)
 	}
-
-	t.Logf("Successfully set and verified TextEdit as default for %s", testUTI)
 }
 
-// TestSetDefaultForUTI_InvalidApp tests error handling for invalid app paths
-func TestSetDefaultForUTI_InvalidApp(t *testing.T) {
+// TestResolveMIMETypesForUTI tests resolving UTIs to MIME types
+func TestResolveMIMETypesForUTI(t *testing.T) {
 	tests := []struct {
-		name    string
-		appPath string
-		uti     string
+		name        string
+		uti         string
+		wantErr     bool
+		wantFirst   string
+		mustContain []string
 	}{
 		{
-			name:    "non-existent app",
-			appPath: "/Applications/NonExistent.app",
-			uti:     "public.plain-text",
+			name:      "HTML UTI",
+			uti:       "public.html",
+			wantFirst: "text/html",
 		},
 		{
-			name:    "empty app path",
-			appPath: "",
-			uti:     "public.plain-text",
+			name:      "JPEG UTI",
+			uti:       "public.jpeg",
+			wantFirst: "image/jpeg",
 		},
 		{
-			name:    "empty UTI",
-			appPath: textEditPath,
+			name:        "PDF UTI",
+			uti:         "com.adobe.pdf",
+			mustContain: []string{"application/pdf"},
+		},
+		{
+			name: "Folder UTI (no MIME types)",
+			uti:  "public.folder",
+		},
+		{
+			name:    "Empty UTI",
 			uti:     "",
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := SetDefaultForUTI(tt.appPath, tt.uti)
-			if err == nil {
-				t.Errorf("SetDefaultForUTI() expected error for invalid input, got nil")
+			mimeTypes, err := ResolveMIMETypesForUTI(tt.uti)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ResolveMIMETypesForUTI() expected error, got nil")
+				}
+				return
 			}
-			t.Logf("Got expected error: %v", err)
-		})
-	}
-}
 
-// TestListAppsForUTI tests listing apps that can open a UTI
-func TestListAppsForUTI(t *testing.T) {
+			if err != nil {
+				t.Fatalf("ResolveMIMETypesForUTI() error = %v", err)
+			}
+
+			if mimeTypes == nil {
+				t.Errorf("ResolveMIMETypesForUTI() returned nil, want non-nil slice")
+			}
+
+			if tt.wantFirst != "" && (len(mimeTypes) == 0 || mimeTypes[0] != tt.wantFirst) {
+				t.Errorf("ResolveMIMETypesForUTI() = %v, want %s first", mimeTypes, tt.wantFirst)
+			}
+
+			for _, want := range tt.mustContain {
+				if !contains(mimeTypes, want) {
+					t.Errorf("ResolveMIMETypesForUTI() = %v, want to contain %s", mimeTypes, want)
+				}
+			}
+
+			t.Logf("UTI %s -> MIME types: %v", tt.uti, mimeTypes)
+		})
+	}
+}
+
+// TestResolveUTIsForMIMEType tests resolving MIME types back to UTIs
+func TestResolveUTIsForMIMEType(t *testing.T) {
 	tests := []struct {
 		name        string
-		uti         string
-		minAppCount int // Minimum number of apps expected
+		mimeType    string
 		wantErr     bool
+		mustContain []string
+		wantEmpty   bool
 	}{
 		{
-			name:        "plain text",
-			uti:         "public.plain-text",
-			minAppCount: 1, // At least TextEdit should be available
-			wantErr:     false,
+			name:        "HTML",
+			mimeType:    "text/html",
+			mustContain: []string{"public.html"},
 		},
 		{
-			name:        "html",
-			uti:         "public.html",
-			minAppCount: 1, // At least Safari or a browser
-			wantErr:     false,
+			name:        "HTML with charset parameter",
+			mimeType:    "text/html; charset=utf-8",
+			mustContain: []string{"public.html"},
 		},
 		{
-			name:    "invalid UTI",
-			uti:     "com.example.nonexistent",
-			wantErr: true,
+			name:        "mixed case PDF",
+			mimeType:    "Application/PDF",
+			mustContain: []string{"com.adobe.pdf"},
+		},
+		{
+			name:      "unknown MIME type",
+			mimeType:  "application/x-nonexistent-12345",
+			wantEmpty: true,
+		},
+		{
+			name:     "empty MIME type",
+			mimeType: "",
+			wantErr:  true,
+		},
+		{
+			name:     "parameters only",
+			mimeType: "; charset=utf-8",
+			wantErr:  true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			apps, err := ListAppsForUTI(tt.uti)
+			utis, err := ResolveUTIsForMIMEType(tt.mimeType)
 			if tt.wantErr {
-				if err == nil {
-					t.Errorf("ListAppsForUTI() expected error, got nil")
+				if err != ErrInvalidParameters {
+					t.Errorf("ResolveUTIsForMIMEType() error = %v, want ErrInvalidParameters", err)
 				}
 				return
 			}
 
 			if err != nil {
-				t.Errorf("ListAppsForUTI() error = %v", err)
-				return
+				t.Fatalf("ResolveUTIsForMIMEType() error = %v", err)
 			}
 
-			if len(apps) < tt.minAppCount {
-				t.Errorf("ListAppsForUTI() got %d apps, want at least %d", len(apps), tt.minAppCount)
+			if tt.wantEmpty && len(utis) != 0 {
+				t.Errorf("ResolveUTIsForMIMEType() = %v, want empty", utis)
 			}
 
-			t.Logf("Apps that can open %s: %v", tt.uti, apps)
+			for _, want := range tt.mustContain {
+				if !contains(utis, want) {
+					t.Errorf("ResolveUTIsForMIMEType() = %v, want to contain %s", utis, want)
+				}
+			}
+
+			t.Logf("MIME type %q -> UTIs: %v", tt.mimeType, utis)
 		})
 	}
 }
 
-// TestListAppsForScheme tests listing apps that can handle a URL scheme
-func TestListAppsForScheme(t *testing.T) {
+// TestResolveUTIForFile tests resolving the UTI of files with and without an extension
+func TestResolveUTIForFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
 	tests := []struct {
-		name        string
-		scheme      string
-		minAppCount int
-		wantErr     bool
+		name string
+		want string
 	}{
-		{
-			name:        "http",
-			scheme:      "http",
-			minAppCount: 1, // At least one browser
-			wantErr:     false,
-		},
-		{
-			name:        "mailto",
-			scheme:      "mailto",
-			minAppCount: 1, // At least Mail.app
-			wantErr:     false,
-		},
+		{"notes.txt", "public.plain-text"},
+		{"NOTES.TXT", "public.plain-text"},
+		// Without an extension the file system only knows the file holds bytes
+		{"download", "public.data"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			apps, err := ListAppsForScheme(tt.scheme)
-			if tt.wantErr {
-				if err == nil {
-					t.Errorf("ListAppsForScheme() expected error, got nil")
-				}
-				return
+			path := filepath.Join(tmpDir, tt.name)
+			if err := os.WriteFile(path, []byte("notes"), 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
 			}
 
+			uti, err := ResolveUTIForFile(path)
 			if err != nil {
-				t.Errorf("ListAppsForScheme() error = %v", err)
-				return
+				t.Fatalf("ResolveUTIForFile(%s) error = %v", path, err)
 			}
-
-			if len(apps) < tt.minAppCount {
-				t.Errorf("ListAppsForScheme() got %d apps, want at least %d", len(apps), tt.minAppCount)
+			if uti != tt.want {
+				t.Errorf("ResolveUTIForFile(%s) = %s, want %s", path, uti, tt.want)
 			}
-
-			t.Logf("Apps that can handle %s: %v", tt.scheme, apps)
 		})
 	}
+
+	for _, path := range []string{"", filepath.Join(tmpDir, "missing.txt")} {
+		if _, err := ResolveUTIForFile(path); !errors.Is(err, ErrInvalidParameters) {
+			t.Errorf("ResolveUTIForFile(%q) error = %v, want ErrInvalidParameters", path, err)
+		}
+	}
 }
 
-// TestListAllApplications tests listing all installed applications
-func TestListAllApplications(t *testing.T) {
-	apps, err := ListAllApplications()
-	if err != nil {
-		t.Fatalf("ListAllApplications() error = %v", err)
+// TestGetDefaultAppForFile tests that a file's default app matches its UTI's default app
+func TestGetDefaultAppForFile(t *testing.T) {
+	textFile := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(textFile, []byte("notes"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
 	}
 
-	if len(apps) == 0 {
-		t.Errorf("ListAllApplications() returned zero applications")
+	want, err := GetDefaultAppInfoForUTI("public.plain-text")
+	if err != nil {
+		t.Skipf("no default app for public.plain-text: %v", err)
 	}
 
-	t.Logf("Total applications found: %d", len(apps))
+	app, err := GetDefaultAppForFile(textFile)
+	if err != nil {
+		t.Fatalf("GetDefaultAppForFile() error = %v", err)
+	}
+	if app.Path != want.Path {
+		t.Errorf("GetDefaultAppForFile() = %s, want %s", app.Path, want.Path)
+	}
 
-	// Check for presence of field in the first app as a sample
-	firstApp := apps[0]
-	if firstApp.Name == "" || firstApp.Path == "" || firstApp.BundleID == "" {
-		t.Errorf("ListAllApplications() returned app with missing fields: %+v", firstApp)
-	} else {
-		t.Logf("Sample application: Name=%s, Path=%s, BundleID=%s", firstApp.Name, firstApp.Path, firstApp.BundleID)
+	if _, err := GetDefaultAppForFile(filepath.Join(t.TempDir(), "missing.txt")); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("GetDefaultAppForFile() with missing file error = %v, want ErrInvalidParameters", err)
 	}
 }
 
-// TestListSupportedDocumentTypes tests listing supported document types for an application
-func TestListSupportedDocumentTypes(t *testing.T) {
-	// Test with TextEdit - should exist on all macOS systems
-	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
-		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+// TestConformsTo tests UTI conformance checks
+func TestConformsTo(t *testing.T) {
+	tests := []struct {
+		name      string
+		uti       string
+		parentUTI string
+		want      bool
+		wantErr   bool
+		wantCode  int
+	}{
+		{
+			name:      "JPEG is an image",
+			uti:       "public.jpeg",
+			parentUTI: "public.image",
+			want:      true,
+		},
+		{
+			name:      "JPEG is data (transitive)",
+			uti:       "public.jpeg",
+			parentUTI: "public.data",
+			want:      true,
+		},
+		{
+			name:      "type conforms to itself",
+			uti:       "public.plain-text",
+			parentUTI: "public.plain-text",
+			want:      true,
+		},
+		{
+			name:      "text is not an image",
+			uti:       "public.plain-text",
+			parentUTI: "public.image",
+			want:      false,
+		},
+		{
+			name:      "unknown UTI",
+			uti:       "invalid.nonexistent.type.12345",
+			parentUTI: "public.data",
+			wantErr:   true,
+			wantCode:  int(ErrInvalidUTI),
+		},
+		{
+			name:      "empty parent",
+			uti:       "public.jpeg",
+			parentUTI: "",
+			wantErr:   true,
+		},
 	}
 
-	docTypes, err := ListSupportedDocumentTypes(textEditPath)
-	if err != nil {
-		t.Fatalf("ListSupportedDocumentTypes() error = %v", err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConformsTo(tt.uti, tt.parentUTI)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConformsTo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCode != 0 && !hasErrorCode(err, tt.wantCode) {
+				t.Errorf("ConformsTo() error = %v, want code %d", err, tt.wantCode)
+			}
+
+			if got != tt.want {
+				t.Errorf("ConformsTo(%s, %s) = %v, want %v", tt.uti, tt.parentUTI, got, tt.want)
+			}
+		})
 	}
+}
 
-	// TextEdit should support at least some document types
-	if len(docTypes) == 0 {
-		t.Errorf("ListSupportedDocumentTypes() returned zero document types for TextEdit")
+// TestGetConformingUTIs tests listing the parent types of a UTI
+func TestGetConformingUTIs(t *testing.T) {
+	tests := []struct {
+		name        string
+		uti         string
+		wantErr     bool
+		mustContain []string
+		wantEmpty   bool
+	}{
+		{
+			name:        "JPEG",
+			uti:         "public.jpeg",
+			mustContain: []string{"public.image", "public.data"},
+		},
+		{
+			name:        "plain text",
+			uti:         "public.plain-text",
+			mustContain: []string{"public.text"},
+		},
+		{
+			name:      "root type",
+			uti:       "public.item",
+			wantEmpty: true,
+		},
+		{
+			name:    "unknown UTI",
+			uti:     "invalid.nonexistent.type.12345",
+			wantErr: true,
+		},
+		{
+			name:    "empty UTI",
+			uti:     "",
+			wantErr: true,
+		},
 	}
 
-	// Verify structure is valid
-	for i, docType := range docTypes {
-		// Role should not be empty
-		if docType.Role == "" {
-			t.Errorf("ListSupportedDocumentTypes() returned empty role at index %d", i)
-		}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utis, err := GetConformingUTIs(tt.uti)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetConformingUTIs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
 
-		// Should have at least one UTI
-		if len(docType.UTIs) == 0 {
-			t.Errorf("ListSupportedDocumentTypes() returned zero UTIs at index %d", i)
-		}
+			if tt.wantErr {
+				return
+			}
 
-		// Check UTIs are non-empty
-		for j, uti := range docType.UTIs {
-			if uti == "" {
-				t.Errorf("ListSupportedDocumentTypes() returned empty UTI at index %d,%d", i, j)
+			if utis == nil || (tt.wantEmpty && len(utis) != 0) {
+				t.Errorf("GetConformingUTIs() = %v, want empty slice", utis)
 			}
-		}
 
-		// Extensions array can be empty (some UTIs don't have extensions)
-		// but if present, should be non-empty strings
-		for j, ext := range docType.Extensions {
-			if ext == "" {
-				t.Errorf("ListSupportedDocumentTypes() returned empty extension at index %d,%d", i, j)
+			for _, want := range tt.mustContain {
+				if !contains(utis, want) {
+					t.Errorf("GetConformingUTIs() = %v, want to contain %s", utis, want)
+				}
 			}
-		}
 
-		t.Logf("Document type %d: Name=%q, Role=%q, HandlerRank=%q, UTIs=%v, Extensions=%v, IsPackage=%v",
-			i, docType.TypeName, docType.Role, docType.HandlerRank, docType.UTIs, docType.Extensions, docType.IsPackage)
+			if contains(utis, tt.uti) {
+				t.Errorf("GetConformingUTIs() = %v, should not contain the type itself", utis)
+			}
+
+			t.Logf("UTI %s conforms to: %v", tt.uti, utis)
+		})
 	}
+}
 
-	t.Logf("TextEdit supports %d document types", len(docTypes))
+// TestGetSchemeDescription tests naming well-known schemes and passing others through
+func TestGetSchemeDescription(t *testing.T) {
+	if got, err := GetSchemeDescription("mailto"); err != nil || got != "Email" {
+		t.Errorf("GetSchemeDescription(mailto) = %q, %v, want Email", got, err)
+	}
+	if got, err := GetSchemeDescription("x-custom-scheme"); err != nil || got != "x-custom-scheme" {
+		t.Errorf("GetSchemeDescription(x-custom-scheme) = %q, %v, want the scheme itself", got, err)
+	}
+	if _, err := GetSchemeDescription(""); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("GetSchemeDescription(\"\") error = %v, want ErrInvalidParameters", err)
+	}
 }
 
-// TestListSupportedDocumentTypes_ErrorCases tests error handling
-func TestListSupportedDocumentTypes_ErrorCases(t *testing.T) {
+// TestGetUTIDescription tests reading human-readable UTI descriptions
+func TestGetUTIDescription(t *testing.T) {
 	tests := []struct {
 		name    string
-		appPath string
+		uti     string
+		wantErr bool
 	}{
 		{
-			name:    "non-existent app",
-			appPath: "/Applications/NonExistent.app",
+			name: "plain text",
+			uti:  "public.plain-text",
 		},
 		{
-			name:    "empty path",
-			appPath: "",
+			name: "PDF",
+			uti:  "com.adobe.pdf",
+		},
+		{
+			name:    "unknown UTI",
+			uti:     "invalid.nonexistent.type.12345",
+			wantErr: true,
+		},
+		{
+			name:    "empty UTI",
+			uti:     "",
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			docTypes, err := ListSupportedDocumentTypes(tt.appPath)
-			if err == nil {
-				t.Errorf("ListSupportedDocumentTypes() expected error, got nil with docTypes: %v", docTypes)
-			} else {
-				t.Logf("Got expected error: %v", err)
+			description, err := GetUTIDescription(tt.uti)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetUTIDescription() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if description == "" {
+				t.Errorf("GetUTIDescription() returned empty description")
 			}
+
+			t.Logf("UTI %s: %s", tt.uti, description)
 		})
 	}
 }
 
-// TestListDefaultDocumentTypes tests listing document types where an app is the system default
-func TestListDefaultDocumentTypes(t *testing.T) {
-	// Test with TextEdit - should exist on all macOS systems
-	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
-		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+// TestGetUTIIconPNG tests rendering the document icon for a UTI
+func TestGetUTIIconPNG(t *testing.T) {
+	data, err := GetUTIIconPNG("public.jpeg", 48)
+	if err != nil {
+		t.Fatalf("GetUTIIconPNG() error = %v", err)
 	}
 
-	defaultDocTypes, err := ListDefaultDocumentTypes(textEditPath)
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
-		t.Fatalf("ListDefaultDocumentTypes() error = %v", err)
+		t.Fatalf("GetUTIIconPNG() returned invalid PNG: %v", err)
+	}
+	if cfg.Width != 48 || cfg.Height != 48 {
+		t.Errorf("GetUTIIconPNG() size = %dx%d, want 48x48", cfg.Width, cfg.Height)
 	}
 
-	// TextEdit may or may not be the default for any types (depends on system configuration)
-	// So we can't assert a minimum count, but we can validate structure if any are returned
-	t.Logf("TextEdit is the default for %d document types", len(defaultDocTypes))
+	_, err = GetUTIIconPNG("invalid.nonexistent.type.12345", 48)
+	if err == nil {
+		t.Errorf("GetUTIIconPNG() with unknown UTI expected error, got nil")
+	}
+}
 
-	for i, docType := range defaultDocTypes {
-		// Verify structure is valid
-		if docType.Role == "" {
-			t.Errorf("ListDefaultDocumentTypes() returned empty role at index %d", i)
-		}
+// TestIsDynamicUTI tests detecting dynamic placeholder UTIs
+func TestIsDynamicUTI(t *testing.T) {
+	if IsDynamicUTI("public.plain-text") {
+		t.Errorf("IsDynamicUTI(public.plain-text) = true, want false")
+	}
 
-		if len(docType.UTIs) == 0 {
-			t.Errorf("ListDefaultDocumentTypes() returned zero UTIs at index %d", i)
-		}
+	if IsDynamicUTI("") {
+		t.Errorf("IsDynamicUTI(\"\") = true, want false")
+	}
 
-		// Verify that this app IS actually the default for ALL returned UTIs (not just some)
-		for _, uti := range docType.UTIs {
-			defaultApp, err := GetDefaultAppForUTI(uti)
-			if err != nil {
-				t.Errorf("ListDefaultDocumentTypes() returned UTI %s at index %d, but got error when checking default: %v", uti, i, err)
-				continue
-			}
+	utis, err := ResolveUTIsForExtension("nonexistentext12345")
+	if err != nil || len(utis) == 0 {
+		t.Skipf("No dynamic UTI produced for an unregistered extension (err = %v)", err)
+	}
 
-			if !pathsMatch(defaultApp, textEditPath) {
-				t.Errorf("ListDefaultDocumentTypes() returned UTI %s at index %d, but TextEdit is not the default (default is %s)",
-					uti, i, defaultApp)
+	dynUTI := utis[0]
+	if !IsDynamicUTI(dynUTI) {
+		t.Errorf("IsDynamicUTI(%s) = false, want true", dynUTI)
+	}
+
+	declared, err := ResolveUTIsForExtensionDeclaredOnly("nonexistentext12345")
+	if err != nil {
+		t.Fatalf("ResolveUTIsForExtensionDeclaredOnly() error = %v", err)
+	}
+	if len(declared) != 0 {
+		t.Errorf("ResolveUTIsForExtensionDeclaredOnly() = %v, want empty", declared)
+	}
+
+	declared, err = ResolveUTIsForExtensionDeclaredOnly("txt")
+	if err != nil {
+		t.Fatalf("ResolveUTIsForExtensionDeclaredOnly() error = %v", err)
+	}
+	if !contains(declared, "public.plain-text") {
+		t.Errorf("ResolveUTIsForExtensionDeclaredOnly(txt) = %v, want to contain public.plain-text", declared)
+	}
+
+	// Setting a default for a dynamic UTI must not silently succeed
+	err = SetDefaultForUTI(textEditPath, dynUTI)
+	if !hasErrorCode(err, ErrInvalidUTI) {
+		t.Errorf("SetDefaultForUTI() with dynamic UTI error = %v, want ErrInvalidUTI", err)
+	}
+
+	t.Logf("Dynamic UTI for unregistered extension: %s", dynUTI)
+}
+
+// TestPreferredUTIForExtension tests resolving an extension to its single preferred UTI
+func TestPreferredUTIForExtension(t *testing.T) {
+	tests := []struct {
+		extension string
+		want      string
+	}{
+		{"txt", "public.plain-text"},
+		{".JPG", "public.jpeg"},
+		{"html", "public.html"},
+	}
+
+	for _, tt := range tests {
+		got, err := PreferredUTIForExtension(tt.extension)
+		if err != nil {
+			t.Errorf("PreferredUTIForExtension(%q) error = %v", tt.extension, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("PreferredUTIForExtension(%q) = %q, want %q", tt.extension, got, tt.want)
+		}
+	}
+
+	uti, err := PreferredUTIForExtension("nonexistentext12345")
+	if err != nil {
+		t.Fatalf("PreferredUTIForExtension(unregistered) error = %v", err)
+	}
+	if !IsDynamicUTI(uti) {
+		t.Errorf("PreferredUTIForExtension(unregistered) = %q, want a dynamic UTI", uti)
+	}
+
+	if _, err := PreferredUTIForExtension(""); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("PreferredUTIForExtension(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestIsRegisteredUTI tests telling declared UTIs from dynamic and unknown ones
+func TestIsRegisteredUTI(t *testing.T) {
+	tests := []struct {
+		name    string
+		uti     string
+		want    bool
+		wantErr bool
+	}{
+		{name: "declared", uti: "public.plain-text", want: true},
+		{name: "dynamic", uti: "dyn.ah62d4rv4ge80e5pe", want: false},
+		{name: "nonsense", uti: "com.example.nonexistent", want: false},
+		{name: "empty", uti: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsRegisteredUTI(tt.uti)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsRegisteredUTI(%q) error = %v, wantErr %v", tt.uti, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IsRegisteredUTI(%q) = %v, want %v", tt.uti, got, tt.want)
+			}
+		})
+	}
+
+	err := SetDefaultForUTI(textEditPath, "com.example.nonexistent")
+	if !hasErrorCode(err, ErrInvalidUTI) {
+		t.Errorf("SetDefaultForUTI() with unregistered UTI error = %v, want ErrInvalidUTI", err)
+	}
+
+	// The variants go through the same check
+	if err := SetDefaultForUTIWithRole(textEditPath, "com.example.nonexistent", RoleViewer); !hasErrorCode(err, ErrInvalidUTI) {
+		t.Errorf("SetDefaultForUTIWithRole() with unregistered UTI error = %v, want ErrInvalidUTI", err)
+	}
+	if _, err := SetDefaultForUTIReturningPrevious(textEditPath, "com.example.nonexistent"); !hasErrorCode(err, ErrInvalidUTI) {
+		t.Errorf("SetDefaultForUTIReturningPrevious() with unregistered UTI error = %v, want ErrInvalidUTI", err)
+	}
+}
+
+// TestSetDefaultForUTIUnsupportedApp tests that an app that can't open the UTI is rejected
+func TestSetDefaultForUTIUnsupportedApp(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	const uti = "public.mpeg-4"
+	before, beforeErr := GetDefaultAppForUTI(uti)
+
+	err := SetDefaultForUTI(textEditPath, uti)
+	if !errors.Is(err, ErrAppDoesNotSupportUTI) {
+		t.Fatalf("SetDefaultForUTI(TextEdit, %s) error = %v, want ErrAppDoesNotSupportUTI", uti, err)
+	}
+
+	// The variants share the same pre-flight check
+	if err := SetDefaultForUTIWithRole(textEditPath, uti, RoleViewer); !errors.Is(err, ErrAppDoesNotSupportUTI) {
+		t.Errorf("SetDefaultForUTIWithRole(TextEdit, %s) error = %v, want ErrAppDoesNotSupportUTI", uti, err)
+	}
+	if _, err := SetDefaultForUTIReturningPrevious(textEditPath, uti); !errors.Is(err, ErrAppDoesNotSupportUTI) {
+		t.Errorf("SetDefaultForUTIReturningPrevious(TextEdit, %s) error = %v, want ErrAppDoesNotSupportUTI", uti, err)
+	}
+
+	after, afterErr := GetDefaultAppForUTI(uti)
+	if after != before || (beforeErr == nil) != (afterErr == nil) {
+		t.Errorf("setters changed the default from %q to %q despite the error", before, after)
+	}
+}
+
+// TestExtensionsShareUTI tests comparing the preferred UTIs of two extensions
+func TestExtensionsShareUTI(t *testing.T) {
+	tests := []struct {
+		name    string
+		extA    string
+		extB    string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "jpg and jpeg",
+			extA: "jpg",
+			extB: "jpeg",
+			want: true,
+		},
+		{
+			name: "htm and html",
+			extA: "htm",
+			extB: "html",
+			want: true,
+		},
+		{
+			name: "txt and jpg",
+			extA: "txt",
+			extB: "jpg",
+			want: false,
+		},
+		{
+			name:    "unregistered extension",
+			extA:    "txt",
+			extB:    "nonexistentext123",
+			wantErr: true,
+		},
+		{
+			name:    "empty extension",
+			extA:    "",
+			extB:    "txt",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shared, err := ExtensionsShareUTI(tt.extA, tt.extB)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ExtensionsShareUTI() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("ExtensionsShareUTI() error = %v", err)
+				return
+			}
+
+			if shared != tt.want {
+				t.Errorf("ExtensionsShareUTI(%q, %q) = %v, want %v", tt.extA, tt.extB, shared, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetDefaultAppForExtension tests reading the default app for file extensions
+func TestGetDefaultAppForExtension(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		wantErr  bool
+		wantCode int
+	}{
+		{
+			name:    "txt",
+			ext:     "txt",
+			wantErr: false,
+		},
+		{
+			name:    "html with leading dot",
+			ext:     ".html",
+			wantErr: false,
+		},
+		{
+			name:     "no handler",
+			ext:      "nonexistentext12345",
+			wantErr:  true,
+			wantCode: int(ErrNotFound),
+		},
+		{
+			name:    "empty extension",
+			ext:     "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appPath, err := GetDefaultAppForExtension(tt.ext)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetDefaultAppForExtension() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				if tt.wantCode != 0 && !hasErrorCode(err, tt.wantCode) {
+					t.Errorf("GetDefaultAppForExtension() error = %v, want code %d", err, tt.wantCode)
+				}
+				return
+			}
+
+			if appPath == "" {
+				t.Errorf("GetDefaultAppForExtension() returned empty path")
+				return
+			}
+
+			t.Logf("Default app for .%s: %s", strings.TrimPrefix(tt.ext, "."), appPath)
+		})
+	}
+}
+
+// TestSetDefaultForUTI tests setting default app for UTI with round-trip
+func TestSetDefaultForUTI(t *testing.T) {
+	// This test requires TextEdit to be available
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	// Use a specific UTI for testing
+	testUTI := "public.plain-text"
+
+	// Get current default to restore later
+	originalApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to get original default app: %v", err)
+	}
+
+	// Ensure we restore the original default
+	defer func() {
+		if originalApp != "" {
+			_ = SetDefaultForUTIForce(originalApp, testUTI)
+		}
+	}()
+
+	t.Logf("Original default app for %s: %s", testUTI, originalApp)
+
+	// Set TextEdit as default
+	err = SetDefaultForUTI(textEditPath, testUTI)
+	if err != nil {
+		t.Fatalf("SetDefaultForUTI() error = %v", err)
+	}
+
+	// Verify it was set
+	currentApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to verify default app: %v", err)
+	}
+
+	// Normalize paths for comparison (resolve symlinks, etc.)
+	if !pathsMatch(currentApp, textEditPath) {
+		t.Errorf("SetDefaultForUTI() verification failed: got %s, want %s", currentApp, textEditPath)
+	}
+
+	t.Logf("Successfully set and verified TextEdit as default for %s", testUTI)
+}
+
+// TestSetDefaultForUTI_Concurrent tests that concurrent writes are serialized
+func TestSetDefaultForUTI_Concurrent(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	testUTI := "public.plain-text"
+
+	originalApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to get original default app: %v", err)
+	}
+
+	defer func() {
+		if originalApp != "" {
+			_ = SetDefaultForUTIForce(originalApp, testUTI)
+		}
+	}()
+
+	const workers = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- SetDefaultForUTI(textEditPath, testUTI)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent SetDefaultForUTI() error = %v", err)
+		}
+	}
+
+	currentApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to verify default app: %v", err)
+	}
+
+	if !pathsMatch(currentApp, textEditPath) {
+		t.Errorf("concurrent SetDefaultForUTI() final state = %s, want %s", currentApp, textEditPath)
+	}
+}
+
+// TestSetDefaultForUTIByBundleID tests setting a default by bundle ID and by path gives the same result
+func TestSetDefaultForUTIByBundleID(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	testUTI := "public.plain-text"
+
+	originalApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to get original default app: %v", err)
+	}
+
+	defer func() {
+		if originalApp != "" {
+			_ = SetDefaultForUTIForce(originalApp, testUTI)
+		}
+	}()
+
+	tests := []struct {
+		name string
+		set  func() error
+	}{
+		{
+			name: "by path",
+			set:  func() error { return SetDefaultForUTI(textEditPath, testUTI) },
+		},
+		{
+			name: "by bundle ID",
+			set:  func() error { return SetDefaultForUTIByBundleID("com.apple.TextEdit", testUTI) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.set(); err != nil {
+				t.Fatalf("set error = %v", err)
+			}
+
+			currentApp, err := GetDefaultAppForUTI(testUTI)
+			if err != nil {
+				t.Fatalf("Failed to verify default app: %v", err)
+			}
+
+			if !pathsMatch(currentApp, textEditPath) {
+				t.Errorf("default app = %s, want %s", currentApp, textEditPath)
+			}
+		})
+	}
+
+	err = SetDefaultForUTIByBundleID("com.example.nonexistent12345", testUTI)
+	if !hasErrorCode(err, ErrNotFound) {
+		t.Errorf("SetDefaultForUTIByBundleID() with unknown bundle ID error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestSetDefaultForUTIWithRole tests setting the viewer role and reading it back
+func TestSetDefaultForUTIWithRole(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	testUTI := "public.plain-text"
+
+	originalViewer, err := defaultAppForUTIWithRole(testUTI, RoleViewer)
+	if err != nil && !hasErrorCode(err, ErrNotFound) {
+		t.Fatalf("Failed to get original viewer: %v", err)
+	}
+
+	defer func() {
+		if originalViewer != "" {
+			_ = SetDefaultForUTIWithRole(originalViewer, testUTI, RoleViewer)
+		}
+	}()
+
+	if err := SetDefaultForUTIWithRole(textEditPath, testUTI, RoleViewer); err != nil {
+		t.Fatalf("SetDefaultForUTIWithRole() error = %v", err)
+	}
+
+	viewer, err := defaultAppForUTIWithRole(testUTI, RoleViewer)
+	if err != nil {
+		t.Fatalf("Failed to read back viewer: %v", err)
+	}
+	if !pathsMatch(viewer, textEditPath) {
+		t.Errorf("viewer after SetDefaultForUTIWithRole() = %s, want %s", viewer, textEditPath)
+	}
+
+	for _, role := range []Role{RoleNone, Role("Owner")} {
+		if err := SetDefaultForUTIWithRole(textEditPath, testUTI, role); !errors.Is(err, ErrInvalidParameters) {
+			t.Errorf("SetDefaultForUTIWithRole(%q) error = %v, want ErrInvalidParameters", role, err)
+		}
+	}
+}
+
+// TestSetDefaultForUTIReturningPrevious tests that the replaced default is returned for undo
+func TestSetDefaultForUTIReturningPrevious(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	testUTI := "public.plain-text"
+
+	originalApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to get original default app: %v", err)
+	}
+
+	defer func() {
+		if originalApp != "" {
+			_ = SetDefaultForUTIForce(originalApp, testUTI)
+		}
+	}()
+
+	previous, err := SetDefaultForUTIReturningPrevious(textEditPath, testUTI)
+	if err != nil {
+		t.Fatalf("SetDefaultForUTIReturningPrevious() error = %v", err)
+	}
+
+	if !pathsMatch(previous, originalApp) {
+		t.Errorf("SetDefaultForUTIReturningPrevious() previous = %s, want %s", previous, originalApp)
+	}
+
+	// Setting the same app again should report it as the previous default
+	previous, err = SetDefaultForUTIReturningPrevious(textEditPath, testUTI)
+	if err != nil {
+		t.Fatalf("SetDefaultForUTIReturningPrevious() error = %v", err)
+	}
+
+	if !pathsMatch(previous, textEditPath) {
+		t.Errorf("SetDefaultForUTIReturningPrevious() previous = %s, want %s", previous, textEditPath)
+	}
+
+	t.Logf("Replaced default for %s: %s", testUTI, originalApp)
+
+	if _, err := SetDefaultForUTIReturningPrevious("", testUTI); err != ErrInvalidParameters {
+		t.Errorf("SetDefaultForUTIReturningPrevious() with empty app path error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestSetDefaultForExtension tests setting default app for a file extension with round-trip
+func TestSetDefaultForExtension(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	testExt := "txt"
+
+	originalApp, err := GetDefaultAppForExtension(testExt)
+	if err != nil {
+		t.Fatalf("Failed to get original default app: %v", err)
+	}
+
+	defer func() {
+		if originalApp != "" {
+			_ = SetDefaultForExtension(originalApp, testExt)
+		}
+	}()
+
+	t.Logf("Original default app for .%s: %s", testExt, originalApp)
+
+	if err := SetDefaultForExtension(textEditPath, "."+testExt); err != nil {
+		t.Fatalf("SetDefaultForExtension() error = %v", err)
+	}
+
+	currentApp, err := GetDefaultAppForExtension(testExt)
+	if err != nil {
+		t.Fatalf("Failed to verify default app: %v", err)
+	}
+
+	if !pathsMatch(currentApp, textEditPath) {
+		t.Errorf("SetDefaultForExtension() verification failed: got %s, want %s", currentApp, textEditPath)
+	}
+
+	for _, tt := range []struct{ appPath, ext string }{
+		{appPath: "", ext: testExt},
+		{appPath: textEditPath, ext: ""},
+		{appPath: textEditPath, ext: "."},
+	} {
+		if err := SetDefaultForExtension(tt.appPath, tt.ext); err != ErrInvalidParameters {
+			t.Errorf("SetDefaultForExtension(%q, %q) error = %v, want ErrInvalidParameters", tt.appPath, tt.ext, err)
+		}
+	}
+}
+
+// TestSetDefaultForUTIConfirmed tests that a change LaunchServices applies is read back for UTIs and schemes
+func TestSetDefaultForUTIConfirmed(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	testUTI := "public.plain-text"
+
+	originalApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to get original default app: %v", err)
+	}
+
+	defer func() {
+		if originalApp != "" {
+			_ = SetDefaultForUTIForce(originalApp, testUTI)
+		}
+	}()
+
+	if err := SetDefaultForUTIConfirmed(textEditPath, testUTI); err != nil {
+		t.Errorf("SetDefaultForUTIConfirmed() error = %v", err)
+	}
+	if got, err := GetDefaultAppForUTI(testUTI); err != nil || !pathsMatch(got, textEditPath) {
+		t.Errorf("default for %s after SetDefaultForUTIConfirmed() = %q, %v, want %s", testUTI, got, err, textEditPath)
+	}
+
+	const calendarPath = "/System/Applications/Calendar.app"
+	const testScheme = "webcal"
+	if _, err := os.Stat(calendarPath); err == nil {
+		originalSchemeApp, err := GetDefaultAppForScheme(testScheme)
+		if err != nil && !hasErrorCode(err, ErrNotFound) {
+			t.Fatalf("Failed to get original %s handler: %v", testScheme, err)
+		}
+		defer func() {
+			if originalSchemeApp != "" {
+				_ = SetDefaultForScheme(originalSchemeApp, testScheme)
+			}
+		}()
+
+		if err := SetDefaultForSchemeVerified(calendarPath, testScheme); err != nil {
+			t.Errorf("SetDefaultForSchemeVerified() error = %v", err)
+		}
+		if got, err := GetDefaultAppForScheme(testScheme); err != nil || !pathsMatch(got, calendarPath) {
+			t.Errorf("handler for %s after SetDefaultForSchemeVerified() = %q, %v, want %s", testScheme, got, err, calendarPath)
+		}
+	}
+
+	// A read-back that names another app is reported as a verification failure
+	mismatch := confirmDefault(textEditPath,
+		func() error { return nil },
+		func() (string, error) { return "/System/Applications/Preview.app", nil })
+	if !errors.Is(mismatch, ErrVerificationFailed) {
+		t.Errorf("confirmDefault() with a different app read back error = %v, want ErrVerificationFailed", mismatch)
+	}
+
+	if err := SetDefaultForUTIConfirmed("", testUTI); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("SetDefaultForUTIConfirmed(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+	if err := SetDefaultForSchemeVerified("", testScheme); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("SetDefaultForSchemeVerified(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestResetDefaultForUTI tests clearing a default app override for a UTI
+func TestResetDefaultForUTI(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	testUTI := "public.plain-text"
+
+	originalApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to get original default app: %v", err)
+	}
+
+	defer func() {
+		if originalApp != "" {
+			_ = SetDefaultForUTIForce(originalApp, testUTI)
+		}
+	}()
+
+	if err := SetDefaultForUTI(textEditPath, testUTI); err != nil {
+		t.Fatalf("SetDefaultForUTI() error = %v", err)
+	}
+
+	if err := ResetDefaultForUTI(testUTI); err != nil {
+		t.Fatalf("ResetDefaultForUTI() error = %v", err)
+	}
+
+	prefs, err := listHandlerPreferences()
+	if err != nil {
+		t.Fatalf("listHandlerPreferences() error = %v", err)
+	}
+
+	for _, pref := range prefs {
+		if pref.Kind == "uti" && strings.EqualFold(pref.Identifier, testUTI) {
+			t.Errorf("ResetDefaultForUTI() left override for %s: %s (%s)", testUTI, pref.App.BundleID, pref.Role)
+		}
+	}
+
+	if currentApp, err := GetDefaultAppForUTI(testUTI); err == nil {
+		t.Logf("Default app for %s after reset: %s", testUTI, currentApp)
+	}
+
+	// Resetting again is a no-op
+	if err := ResetDefaultForUTI(testUTI); err != nil {
+		t.Errorf("ResetDefaultForUTI() second call error = %v", err)
+	}
+
+	err = ResetDefaultForUTI("invalid.nonexistent.type.12345")
+	if !hasErrorCode(err, ErrInvalidUTI) {
+		t.Errorf("ResetDefaultForUTI() with unknown UTI error = %v, want ErrInvalidUTI", err)
+	}
+}
+
+// TestResetDefaultForScheme tests that resetting removes the user's override for a scheme
+func TestResetDefaultForScheme(t *testing.T) {
+	// Find a scheme with a handler other than the system default, so the
+	// override and the reset are both observable. http and https are avoided
+	// because changing the browser asks the user to confirm.
+	var testScheme, originalApp, systemApp, overrideApp string
+	for _, scheme := range []string{"mailto", "webcal", "ftp", "ssh", "vnc", "x-man-page"} {
+		current, err := GetDefaultAppForScheme(scheme)
+		if err != nil {
+			continue
+		}
+		apps, err := ListAppsForScheme(scheme)
+		if err != nil {
+			continue
+		}
+
+		if err := ResetDefaultForScheme(scheme); err != nil {
+			t.Fatalf("ResetDefaultForScheme(%s) error = %v", scheme, err)
+		}
+		system, err := GetDefaultAppForScheme(scheme)
+		if err != nil {
+			_ = SetDefaultForScheme(current, scheme)
+			continue
+		}
+
+		for _, app := range apps {
+			if !pathsMatch(app, system) {
+				testScheme, originalApp, systemApp, overrideApp = scheme, current, system, app
+				break
+			}
+		}
+		if testScheme != "" {
+			break
+		}
+		_ = SetDefaultForScheme(current, scheme)
+	}
+	if testScheme == "" {
+		t.Skip("no scheme with more than one handler found, skipping test")
+	}
+
+	defer func() {
+		_ = SetDefaultForScheme(originalApp, testScheme)
+	}()
+
+	if err := SetDefaultForScheme(overrideApp, testScheme); err != nil {
+		t.Fatalf("SetDefaultForScheme() error = %v", err)
+	}
+	if got, err := GetDefaultAppForScheme(testScheme); err != nil || !pathsMatch(got, overrideApp) {
+		t.Fatalf("handler for %s before reset = %q, %v, want %s", testScheme, got, err, overrideApp)
+	}
+
+	if err := ResetDefaultForScheme(testScheme); err != nil {
+		t.Fatalf("ResetDefaultForScheme() error = %v", err)
+	}
+	if got, err := GetDefaultAppForScheme(testScheme); err != nil || !pathsMatch(got, systemApp) {
+		t.Errorf("handler for %s after reset = %q, %v, want system default %s", testScheme, got, err, systemApp)
+	}
+
+	prefs, err := listHandlerPreferences()
+	if err != nil {
+		t.Fatalf("listHandlerPreferences() error = %v", err)
+	}
+
+	for _, pref := range prefs {
+		if pref.Kind == "scheme" && pref.Identifier == testScheme {
+			t.Errorf("ResetDefaultForScheme() left override for %s: %s (%s)", testScheme, pref.App.BundleID, pref.Role)
+		}
+	}
+
+	// Resetting again is a no-op
+	if err := ResetDefaultForScheme(testScheme); err != nil {
+		t.Errorf("ResetDefaultForScheme() second call error = %v", err)
+	}
+
+	if err := ResetDefaultForScheme(""); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("ResetDefaultForScheme(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestIsDefaultAppForUTI tests checking whether an app is the default for a UTI
+func TestIsDefaultAppForUTI(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	testUTI := "public.plain-text"
+
+	originalApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to get original default app: %v", err)
+	}
+
+	defer func() {
+		if originalApp != "" {
+			_ = SetDefaultForUTIForce(originalApp, testUTI)
+		}
+	}()
+
+	if err := SetDefaultForUTI(textEditPath, testUTI); err != nil {
+		t.Fatalf("SetDefaultForUTI() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		appPath string
+		uti     string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "current default",
+			appPath: textEditPath,
+			uti:     testUTI,
+			want:    true,
+		},
+		{
+			name:    "current default with different case",
+			appPath: strings.ToLower(textEditPath),
+			uti:     testUTI,
+			want:    true,
+		},
+		{
+			name:    "different app",
+			appPath: "/System/Applications/Calculator.app",
+			uti:     testUTI,
+			want:    false,
+		},
+		{
+			name:    "empty app path",
+			appPath: "",
+			uti:     testUTI,
+			wantErr: true,
+		},
+		{
+			name:    "invalid UTI",
+			appPath: textEditPath,
+			uti:     "invalid.nonexistent.type.12345",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsDefaultAppForUTI(tt.appPath, tt.uti)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsDefaultAppForUTI() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("IsDefaultAppForUTI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSetDefaultForUTI_InvalidApp tests error handling for invalid app paths
+func TestSetDefaultForUTI_InvalidApp(t *testing.T) {
+	tests := []struct {
+		name    string
+		appPath string
+		uti     string
+	}{
+		{
+			name:    "non-existent app",
+			appPath: "/Applications/NonExistent.app",
+			uti:     "public.plain-text",
+		},
+		{
+			name:    "empty app path",
+			appPath: "",
+			uti:     "public.plain-text",
+		},
+		{
+			name:    "empty UTI",
+			appPath: textEditPath,
+			uti:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetDefaultForUTI(tt.appPath, tt.uti)
+			if err == nil {
+				t.Errorf("SetDefaultForUTI() expected error for invalid input, got nil")
+			}
+			t.Logf("Got expected error: %v", err)
+		})
+	}
+}
+
+// TestOpenFile_InvalidParameters tests that opening rejects empty and missing paths without launching anything
+func TestOpenFile_InvalidParameters(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+
+	tests := []struct {
+		name     string
+		filePath string
+	}{
+		{
+			name:     "empty path",
+			filePath: "",
+		},
+		{
+			name:     "non-existent file",
+			filePath: missing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := OpenFile(tt.filePath); !errors.Is(err, ErrInvalidParameters) {
+				t.Errorf("OpenFile() error = %v, want ErrInvalidParameters", err)
+			}
+			if err := OpenFileWithApp(tt.filePath, textEditPath); !errors.Is(err, ErrInvalidParameters) {
+				t.Errorf("OpenFileWithApp() error = %v, want ErrInvalidParameters", err)
+			}
+		})
+	}
+}
+
+// TestOpenFilesWithApp_InvalidParameters tests that nothing is opened when the input is incomplete
+func TestOpenFilesWithApp_InvalidParameters(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	existing := filepath.Join(tmpDir, "notes.txt")
+	if err := os.WriteFile(existing, []byte("notes"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	missing := filepath.Join(tmpDir, "missing.txt")
+
+	tests := []struct {
+		name      string
+		appPath   string
+		filePaths []string
+	}{
+		{
+			name:      "empty app path",
+			appPath:   "",
+			filePaths: []string{existing},
+		},
+		{
+			name:      "no files",
+			appPath:   textEditPath,
+			filePaths: nil,
+		},
+		{
+			name:      "one file missing",
+			appPath:   textEditPath,
+			filePaths: []string{existing, missing},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := OpenFilesWithApp(tt.appPath, tt.filePaths); !errors.Is(err, ErrInvalidParameters) {
+				t.Errorf("OpenFilesWithApp() error = %v, want ErrInvalidParameters", err)
+			}
+		})
+	}
+}
+
+// TestListAppsForUTI tests listing apps that can open a UTI
+func TestListAppsForUTI(t *testing.T) {
+	tests := []struct {
+		name        string
+		uti         string
+		minAppCount int // Minimum number of apps expected
+		wantErr     bool
+	}{
+		{
+			name:        "plain text",
+			uti:         "public.plain-text",
+			minAppCount: 1, // At least TextEdit should be available
+			wantErr:     false,
+		},
+		{
+			name:        "html",
+			uti:         "public.html",
+			minAppCount: 1, // At least Safari or a browser
+			wantErr:     false,
+		},
+		{
+			name:    "invalid UTI",
+			uti:     "com.example.nonexistent",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps, err := ListAppsForUTI(tt.uti)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ListAppsForUTI() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("ListAppsForUTI() error = %v", err)
+				return
+			}
+
+			if len(apps) < tt.minAppCount {
+				t.Errorf("ListAppsForUTI() got %d apps, want at least %d", len(apps), tt.minAppCount)
+			}
+
+			for i := 1; i < len(apps); i++ {
+				if apps[i-1] >= apps[i] {
+					t.Errorf("ListAppsForUTI() = %v, want sorted without duplicates", apps)
+					break
+				}
+			}
+
+			t.Logf("Apps that can open %s: %v", tt.uti, apps)
+		})
+	}
+}
+
+// TestListAppsForUTIs tests listing the applications for several UTIs in one call
+func TestListAppsForUTIs(t *testing.T) {
+	utis := []string{"public.plain-text", "public.html", "com.example.nonexistent"}
+
+	appsByUTI, err := ListAppsForUTIs(utis)
+	if err != nil {
+		t.Fatalf("ListAppsForUTIs() error = %v", err)
+	}
+
+	// Each entry must match the single-UTI lookup
+	for _, uti := range []string{"public.plain-text", "public.html"} {
+		want, err := ListAppsForUTI(uti)
+		if err != nil {
+			t.Fatalf("ListAppsForUTI(%s) error = %v", uti, err)
+		}
+		if !reflect.DeepEqual(appsByUTI[uti], want) {
+			t.Errorf("ListAppsForUTIs()[%s] = %v, want %v", uti, appsByUTI[uti], want)
+		}
+	}
+
+	if got, ok := appsByUTI["com.example.nonexistent"]; ok {
+		t.Errorf("ListAppsForUTIs()[com.example.nonexistent] = %v, want no entry", got)
+	}
+
+	all, err := AppsHandlingAllUTIs(utis[:2])
+	if err != nil {
+		t.Fatalf("AppsHandlingAllUTIs() error = %v", err)
+	}
+	// TextEdit opens both plain text and HTML
+	foundTextEdit := false
+	for _, appPath := range all {
+		if pathsMatch(appPath, textEditPath) {
+			foundTextEdit = true
+		}
+	}
+	if !foundTextEdit {
+		t.Errorf("AppsHandlingAllUTIs() = %v, want to contain TextEdit", all)
+	}
+
+	anyApps, err := AppsHandlingAnyUTI(utis)
+	if err != nil {
+		t.Fatalf("AppsHandlingAnyUTI() error = %v", err)
+	}
+	if len(anyApps) < len(appsByUTI["public.html"]) || len(anyApps) < len(appsByUTI["public.plain-text"]) {
+		t.Errorf("AppsHandlingAnyUTI() returned %d apps, fewer than a single UTI", len(anyApps))
+	}
+
+	none, err := AppsHandlingAllUTIs(utis)
+	if err != nil || len(none) != 0 {
+		t.Errorf("AppsHandlingAllUTIs() with an unknown UTI = %v, %v, want empty", none, err)
+	}
+}
+
+// TestListAppsForUTIManyHandlers tests converting a large C array of handlers for a broad UTI
+func TestListAppsForUTIManyHandlers(t *testing.T) {
+	testUTI := "public.data"
+
+	paths, err := ListAppsForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("ListAppsForUTI(%s) error = %v", testUTI, err)
+	}
+
+	apps, err := ListAppInfosForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("ListAppInfosForUTI(%s) error = %v", testUTI, err)
+	}
+
+	if len(apps) != len(paths) {
+		t.Fatalf("ListAppInfosForUTI() returned %d apps, ListAppsForUTI() returned %d", len(apps), len(paths))
+	}
+
+	// Same apps, but ListAppInfosForUTI keeps LaunchServices' order
+	appPaths := make([]string, len(apps))
+	for i, app := range apps {
+		appPaths[i] = app.Path
+	}
+	sort.Strings(appPaths)
+
+	for i, path := range paths {
+		if path == "" {
+			t.Errorf("ListAppsForUTI()[%d] is empty", i)
+		}
+		if appPaths[i] != path {
+			t.Errorf("sorted ListAppInfosForUTI()[%d].Path = %s, want %s", i, appPaths[i], path)
+		}
+	}
+
+	t.Logf("%d apps can open %s", len(paths), testUTI)
+}
+
+// TestListAppsForUTIWithRole tests listing apps for a UTI restricted to a role
+func TestListAppsForUTIWithRole(t *testing.T) {
+	testUTI := "public.plain-text"
+
+	all, err := ListAppsForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("ListAppsForUTI() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		role    Role
+		wantErr bool
+	}{
+		{name: "viewer", role: RoleViewer},
+		{name: "editor", role: RoleEditor},
+		{name: "all", role: RoleAll},
+		{name: "unknown role", role: Role("Owner"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps, err := ListAppsForUTIWithRole(testUTI, tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListAppsForUTIWithRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if tt.role == RoleAll && strings.Join(apps, ",") != strings.Join(all, ",") {
+				t.Errorf("ListAppsForUTIWithRole(RoleAll) = %v, want ListAppsForUTI() result %v", apps, all)
+			}
+
+			t.Logf("%s apps for %s: %v", tt.role, testUTI, apps)
+		})
+	}
+
+	editors, err := ListAppsForUTIWithRole(testUTI, RoleEditor)
+	if err != nil {
+		t.Fatalf("ListAppsForUTIWithRole() error = %v", err)
+	}
+	found := false
+	for _, app := range editors {
+		if pathsMatch(app, textEditPath) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ListAppsForUTIWithRole(RoleEditor) = %v, want it to include TextEdit", editors)
+	}
+}
+
+// TestListAppInfosForUTI tests that the AppInfo listing matches the path listing
+func TestListAppInfosForUTI(t *testing.T) {
+	testUTI := "public.plain-text"
+
+	paths, err := ListAppsForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("ListAppsForUTI() error = %v", err)
+	}
+
+	apps, err := ListAppInfosForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("ListAppInfosForUTI() error = %v", err)
+	}
+
+	if len(apps) != len(paths) {
+		t.Fatalf("ListAppInfosForUTI() returned %d apps, ListAppsForUTI() returned %d", len(apps), len(paths))
+	}
+
+	for i, app := range apps {
+		if app.Path != paths[i] {
+			t.Errorf("ListAppInfosForUTI()[%d].Path = %s, want %s", i, app.Path, paths[i])
+		}
+		if app.Name == "" {
+			t.Errorf("ListAppInfosForUTI()[%d] has no name: %+v", i, app)
+		}
+	}
+
+	if _, err := ListAppInfosForUTI("com.example.nonexistent"); err == nil {
+		t.Errorf("ListAppInfosForUTI() with invalid UTI expected error, got nil")
+	}
+}
+
+// TestListAppsForUTIWithRoles tests that the default handler is listed first with its rank
+func TestListAppsForUTIWithRoles(t *testing.T) {
+	testUTI := "public.plain-text"
+
+	apps, err := ListAppsForUTIWithRoles(testUTI)
+	if err != nil {
+		t.Fatalf("ListAppsForUTIWithRoles() error = %v", err)
+	}
+	if len(apps) == 0 {
+		t.Fatalf("ListAppsForUTIWithRoles() returned no apps")
+	}
+
+	defaultApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("GetDefaultAppForUTI() error = %v", err)
+	}
+
+	if !apps[0].IsDefault || !pathsMatch(apps[0].Path, defaultApp) {
+		t.Errorf("ListAppsForUTIWithRoles()[0] = %+v, want default %s", apps[0], defaultApp)
+	}
+
+	for _, app := range apps[1:] {
+		if app.IsDefault {
+			t.Errorf("ListAppsForUTIWithRoles() marked non-first app as default: %+v", app)
+		}
+	}
+
+	for _, app := range apps {
+		t.Logf("%s rank=%q role=%q default=%v", app.Name, app.HandlerRank, app.Role, app.IsDefault)
+	}
+}
+
+// TestListAppsForScheme tests listing apps that can handle a URL scheme
+func TestListAppsForScheme(t *testing.T) {
+	tests := []struct {
+		name        string
+		scheme      string
+		minAppCount int
+		wantErr     bool
+	}{
+		{
+			name:        "http",
+			scheme:      "http",
+			minAppCount: 1, // At least one browser
+			wantErr:     false,
+		},
+		{
+			name:        "mailto",
+			scheme:      "mailto",
+			minAppCount: 1, // At least Mail.app
+			wantErr:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps, err := ListAppsForScheme(tt.scheme)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ListAppsForScheme() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("ListAppsForScheme() error = %v", err)
+				return
+			}
+
+			if len(apps) < tt.minAppCount {
+				t.Errorf("ListAppsForScheme() got %d apps, want at least %d", len(apps), tt.minAppCount)
+			}
+			for i := 1; i < len(apps); i++ {
+				if apps[i-1] >= apps[i] {
+					t.Errorf("ListAppsForScheme() not sorted and unique: %v", apps)
+					break
+				}
+			}
+
+			t.Logf("Apps that can handle %s: %v", tt.scheme, apps)
+		})
+	}
+}
+
+// TestGetAllHandlersForUTIByRole tests listing handlers for a UTI grouped by role
+func TestGetAllHandlersForUTIByRole(t *testing.T) {
+	handlers, err := GetAllHandlersForUTIByRole("public.plain-text")
+	if err != nil {
+		t.Fatalf("GetAllHandlersForUTIByRole() error = %v", err)
+	}
+
+	for _, role := range []string{"Editor", "Viewer", "All"} {
+		apps, ok := handlers[role]
+		if !ok {
+			t.Errorf("GetAllHandlersForUTIByRole() missing role %q", role)
+			continue
+		}
+
+		for _, app := range apps {
+			if app.Path == "" {
+				t.Errorf("GetAllHandlersForUTIByRole() returned app with empty path for role %q: %+v", role, app)
+			}
+		}
+
+		t.Logf("%s handlers for public.plain-text: %d", role, len(apps))
+	}
+
+	if len(handlers["All"]) == 0 {
+		t.Errorf("GetAllHandlersForUTIByRole() returned no apps for role All")
+	}
+
+	if _, err := GetAllHandlersForUTIByRole("com.example.nonexistent"); err == nil {
+		t.Errorf("GetAllHandlersForUTIByRole() expected error for invalid UTI, got nil")
+	}
+
+	if _, err := GetAllHandlersForUTIByRole(""); err == nil {
+		t.Errorf("GetAllHandlersForUTIByRole() expected error for empty UTI, got nil")
+	}
+}
+
+// TestValidateAppBundle tests validating application bundle paths
+func TestValidateAppBundle(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	plainFile := filepath.Join(tmpDir, "NotAnApp.app")
+	if err := os.WriteFile(plainFile, []byte("not a bundle"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	emptyBundle := filepath.Join(tmpDir, "Empty.app")
+	if err := os.Mkdir(emptyBundle, 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		appPath string
+		wantErr bool
+	}{
+		{
+			name:    "TextEdit",
+			appPath: textEditPath,
+			wantErr: false,
+		},
+		{
+			name:    "Non-existent path",
+			appPath: "/Applications/NonExistent12345.app",
+			wantErr: true,
+		},
+		{
+			name:    "Plain file",
+			appPath: plainFile,
+			wantErr: true,
+		},
+		{
+			name:    "Directory without Info.plist",
+			appPath: emptyBundle,
+			wantErr: true,
+		},
+		{
+			name:    "Empty path",
+			appPath: "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAppBundle(tt.appPath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAppBundle() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				t.Logf("ValidateAppBundle(%q) rejected: %v", tt.appPath, err)
+			}
+		})
+	}
+}
+
+// writeUnsignedApp creates a minimal unsigned app bundle without an icon in a temporary directory
+func writeUnsignedApp(t *testing.T) string {
+	t.Helper()
+
+	appPath := filepath.Join(t.TempDir(), "Unsigned.app")
+	if err := os.MkdirAll(filepath.Join(appPath, "Contents", "MacOS"), 0o755); err != nil {
+		t.Fatalf("failed to create bundle: %v", err)
+	}
+
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleExecutable</key>
+	<string>Unsigned</string>
+	<key>CFBundleIdentifier</key>
+	<string>com.example.unsigned</string>
+	<key>CFBundlePackageType</key>
+	<string>APPL</string>
+</dict>
+</plist>
+`
+	if err := os.WriteFile(filepath.Join(appPath, "Contents", "Info.plist"), []byte(plist), 0o644); err != nil {
+		t.Fatalf("failed to write Info.plist: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(appPath, "Contents", "MacOS", "Unsigned"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("failed to write executable: %v", err)
+	}
+
+	return appPath
+}
+
+// TestSetDefaultForUTIVerifiedRejectsUnsignedApp tests that a bundle without a valid signature is refused
+func TestSetDefaultForUTIVerifiedRejectsUnsignedApp(t *testing.T) {
+	appPath := writeUnsignedApp(t)
+
+	err := SetDefaultForUTIVerified(appPath, "public.plain-text")
+	if !errors.Is(err, ErrCodeSignatureInvalid) {
+		t.Errorf("SetDefaultForUTIVerified(unsigned) error = %v, want ErrCodeSignatureInvalid", err)
+	}
+
+	if err := checkCodeSignature(textEditPath); err != nil {
+		t.Errorf("checkCodeSignature(TextEdit) error = %v, want nil", err)
+	}
+}
+
+// TestFindAppByBundleID tests resolving bundle identifiers to installed applications
+func TestFindAppByBundleID(t *testing.T) {
+	tests := []struct {
+		name     string
+		bundleID string
+		wantPath string
+		wantErr  bool
+	}{
+		{
+			name:     "TextEdit",
+			bundleID: "com.apple.TextEdit",
+			wantPath: textEditPath,
+			wantErr:  false,
+		},
+		{
+			name:     "Not installed",
+			bundleID: "com.example.nonexistent12345",
+			wantErr:  true,
+		},
+		{
+			name:     "Empty bundle ID",
+			bundleID: "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := FindAppByBundleID(tt.bundleID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindAppByBundleID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				t.Logf("Got expected error: %v", err)
+				return
+			}
+
+			if !pathsMatch(app.Path, tt.wantPath) || app.Name == "" || app.BundleID != tt.bundleID {
+				t.Errorf("FindAppByBundleID() = %+v, want path %s", app, tt.wantPath)
+			}
+		})
+	}
+
+	_, err := FindAppByBundleID("com.example.nonexistent12345")
+	if !hasErrorCode(err, ErrNotFound) {
+		t.Errorf("FindAppByBundleID() error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestGetBundleInfoValue tests reading individual Info.plist keys
+func TestGetBundleInfoValue(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	value, err := GetBundleInfoValue(textEditPath, "CFBundleIdentifier")
+	if err != nil {
+		t.Fatalf("GetBundleInfoValue(CFBundleIdentifier) error = %v", err)
+	}
+	if value != "com.apple.TextEdit" {
+		t.Errorf("GetBundleInfoValue(CFBundleIdentifier) = %q, want com.apple.TextEdit", value)
+	}
+
+	_, err = GetBundleInfoValue(textEditPath, "NoSuchInfoPlistKey")
+	if !hasErrorCode(err, ErrNotFound) {
+		t.Errorf("GetBundleInfoValue(missing key) error = %v, want ErrNotFound", err)
+	}
+
+	_, err = GetBundleInfoValue("/Applications/NonExistent12345.app", "CFBundleIdentifier")
+	if !hasErrorCode(err, ErrInvalidApp) {
+		t.Errorf("GetBundleInfoValue(invalid path) error = %v, want ErrInvalidApp", err)
+	}
+}
+
+// TestGetAppCategory tests reading the App Store category of an app
+func TestGetAppCategory(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	category, err := GetAppCategory(textEditPath)
+	if err != nil {
+		t.Fatalf("GetAppCategory() error = %v", err)
+	}
+
+	app, err := FindAppByBundleID("com.apple.TextEdit")
+	if err != nil {
+		t.Fatalf("FindAppByBundleID() error = %v", err)
+	}
+	if app.Category != category {
+		t.Errorf("AppInfo.Category = %q, want %q from GetAppCategory", app.Category, category)
+	}
+
+	_, err = GetAppCategory("/Applications/NonExistent12345.app")
+	if !hasErrorCode(err, ErrInvalidApp) {
+		t.Errorf("GetAppCategory(invalid path) error = %v, want ErrInvalidApp", err)
+	}
+}
+
+// TestIsSandboxed tests reading the App Sandbox entitlement
+func TestIsSandboxed(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	// TextEdit has shipped sandboxed since OS X 10.7
+	sandboxed, err := IsSandboxed(textEditPath)
+	if err != nil {
+		t.Fatalf("IsSandboxed() error = %v", err)
+	}
+	if !sandboxed {
+		t.Errorf("IsSandboxed(TextEdit) = false, want true")
+	}
+
+	_, err = IsSandboxed("/tmp")
+	if !hasErrorCode(err, ErrInvalidApp) {
+		t.Errorf("IsSandboxed(/tmp) error = %v, want ErrInvalidApp", err)
+	}
+}
+
+// TestGetCodeSigningTeamID tests reading the signing team of an app
+func TestGetCodeSigningTeamID(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	// Platform binaries are signed by Apple without a team identifier
+	teamID, err := GetCodeSigningTeamID(textEditPath)
+	if err != nil {
+		t.Fatalf("GetCodeSigningTeamID() error = %v", err)
+	}
+	if teamID != "" {
+		t.Errorf("GetCodeSigningTeamID(TextEdit) = %q, want empty for a platform binary", teamID)
+	}
+
+	// Unsigned code has no team either
+	teamID, err = GetCodeSigningTeamID(writeUnsignedApp(t))
+	if err != nil || teamID != "" {
+		t.Errorf("GetCodeSigningTeamID(unsigned) = %q, %v, want empty and no error", teamID, err)
+	}
+
+	_, err = GetCodeSigningTeamID("/tmp")
+	if !hasErrorCode(err, ErrInvalidApp) {
+		t.Errorf("GetCodeSigningTeamID(/tmp) error = %v, want ErrInvalidApp", err)
+	}
+}
+
+// TestGetAppInfoForPID tests that processes outside an app bundle are not found
+func TestGetAppInfoForPID(t *testing.T) {
+	// The test binary is a plain executable, not an application bundle
+	if _, err := GetAppInfoForPID(os.Getpid()); !hasErrorCode(err, ErrNotFound) {
+		t.Errorf("GetAppInfoForPID(test process) error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := GetAppInfoForPID(0); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("GetAppInfoForPID(0) error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestGetFrontmostApp tests that the frontmost app, if any, is an application bundle
+func TestGetFrontmostApp(t *testing.T) {
+	app, err := GetFrontmostApp()
+	if hasErrorCode(err, ErrNotFound) {
+		t.Skip("No frontmost application (no GUI session)")
+	}
+	if err != nil {
+		t.Fatalf("GetFrontmostApp() error = %v", err)
+	}
+
+	if err := ValidateAppBundle(app.Path); err != nil {
+		t.Errorf("GetFrontmostApp().Path = %s, not a valid app bundle: %v", app.Path, err)
+	}
+}
+
+// TestIsAppRunning tests matching running applications by bundle ID
+func TestIsAppRunning(t *testing.T) {
+	running, err := IsAppRunning("com.example.nonexistent-app-12345")
+	if err != nil {
+		t.Fatalf("IsAppRunning(nonexistent) error = %v", err)
+	}
+	if running {
+		t.Error("IsAppRunning(nonexistent) = true, want false")
+	}
+
+	if _, err := IsAppRunning(""); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("IsAppRunning(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+
+	front, err := GetFrontmostApp()
+	if err != nil || front.BundleID == "" {
+		t.Skipf("No frontmost application with a bundle ID to check (err = %v)", err)
+	}
+
+	running, err = IsAppRunning(front.BundleID)
+	if err != nil {
+		t.Fatalf("IsAppRunning(%s) error = %v", front.BundleID, err)
+	}
+	if !running {
+		t.Errorf("IsAppRunning(%s) = false for the frontmost app, want true", front.BundleID)
+	}
+}
+
+// TestActivateApp tests that activating an app that is not running reports ErrNotFound
+func TestActivateApp(t *testing.T) {
+	if err := ActivateApp("com.example.nonexistent-app-12345"); !hasErrorCode(err, ErrNotFound) {
+		t.Errorf("ActivateApp(not running) error = %v, want ErrNotFound", err)
+	}
+
+	if err := ActivateApp(""); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("ActivateApp(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestQuitApp tests that quitting an app that is not running reports ErrNotFound
+func TestQuitApp(t *testing.T) {
+	for _, force := range []bool{false, true} {
+		if err := QuitApp("com.example.nonexistent-app-12345", force); !hasErrorCode(err, ErrNotFound) {
+			t.Errorf("QuitApp(not running, force=%v) error = %v, want ErrNotFound", force, err)
+		}
+	}
+
+	if err := QuitApp("", false); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("QuitApp(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestGetBundleID tests reading the bundle identifier of an app path
+func TestGetBundleID(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	symlink := filepath.Join(tmpDir, "TextEdit.app")
+	if err := os.Symlink(textEditPath, symlink); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		appPath string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "TextEdit",
+			appPath: textEditPath,
+			want:    "com.apple.TextEdit",
+		},
+		{
+			name:    "Symlink to TextEdit",
+			appPath: symlink,
+			want:    "com.apple.TextEdit",
+		},
+		{
+			name:    "Non-existent path",
+			appPath: "/Applications/NonExistent12345.app",
+			wantErr: true,
+		},
+		{
+			name:    "Empty path",
+			appPath: "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetBundleID(tt.appPath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetBundleID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetBundleID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetAppSummary tests summarizing an application's metadata and document types
+func TestGetAppSummary(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	summary, err := GetAppSummary(textEditPath)
+	if err != nil {
+		t.Fatalf("GetAppSummary() error = %v", err)
+	}
+
+	if summary.Name == "" || summary.BundleID != "com.apple.TextEdit" || !pathsMatch(summary.Path, textEditPath) {
+		t.Errorf("GetAppSummary() returned unexpected app info: %+v", summary.AppInfo)
+	}
+
+	if summary.Version == "" || summary.BuildVersion == "" {
+		t.Errorf("GetAppSummary() missing version info: Version = %q, BuildVersion = %q", summary.Version, summary.BuildVersion)
+	}
+	if summary.DisplayVersion != summary.Version {
+		t.Errorf("GetAppSummary() DisplayVersion = %q, want Version %q", summary.DisplayVersion, summary.Version)
+	}
+
+	if summary.SupportedTypeCount == 0 {
+		t.Errorf("GetAppSummary() SupportedTypeCount = 0, want > 0")
+	}
+
+	if summary.OwnedTypeCount > summary.SupportedTypeCount {
+		t.Errorf("GetAppSummary() OwnedTypeCount = %d exceeds SupportedTypeCount = %d", summary.OwnedTypeCount, summary.SupportedTypeCount)
+	}
+
+	t.Logf("%s %s: opens %d types, owns %d", summary.Name, summary.DisplayVersion, summary.SupportedTypeCount, summary.OwnedTypeCount)
+
+	_, err = GetAppSummary("/Applications/NonExistent12345.app")
+	if bridgeErr, ok := err.(*BridgeError); !ok || bridgeErr.Code != int(ErrInvalidApp) {
+		t.Errorf("GetAppSummary() with invalid path error = %v, want ErrInvalidApp", err)
+	}
+}
+
+// TestGetAppIconPNG tests rendering an app icon at a requested pixel size
+func TestGetAppIconPNG(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	tests := []struct {
+		name     string
+		size     int
+		wantSize int
+	}{
+		{name: "32px", size: 32, wantSize: 32},
+		{name: "128px", size: 128, wantSize: 128},
+		{name: "default size", size: 0, wantSize: 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := GetAppIconPNG(textEditPath, tt.size)
+			if err != nil {
+				t.Fatalf("GetAppIconPNG() error = %v", err)
+			}
+
+			cfg, err := png.DecodeConfig(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("GetAppIconPNG() returned invalid PNG: %v", err)
+			}
+			if cfg.Width != tt.wantSize || cfg.Height != tt.wantSize {
+				t.Errorf("GetAppIconPNG() size = %dx%d, want %dx%d", cfg.Width, cfg.Height, tt.wantSize, tt.wantSize)
+			}
+		})
+	}
+
+	// An app without an icon of its own gets the generic application icon
+	data, err := GetAppIconPNG(writeUnsignedApp(t), 32)
+	if err != nil {
+		t.Fatalf("GetAppIconPNG(iconless app) error = %v", err)
+	}
+	if cfg, err := png.DecodeConfig(bytes.NewReader(data)); err != nil || cfg.Width != 32 {
+		t.Errorf("GetAppIconPNG(iconless app) = %+v, %v, want a 32px PNG", cfg, err)
+	}
+
+	_, err = GetAppIconPNG("/Applications/NonExistent12345.app", 32)
+	if !hasErrorCode(err, ErrInvalidApp) {
+		t.Errorf("GetAppIconPNG() with invalid path error = %v, want ErrInvalidApp", err)
+	}
+}
+
+// TestListAllApplications tests listing all installed applications
+func TestListAllApplications(t *testing.T) {
+	apps, err := ListAllApplications()
+	if err != nil {
+		t.Fatalf("ListAllApplications() error = %v", err)
+	}
+
+	if len(apps) == 0 {
+		t.Fatalf("ListAllApplications() returned zero applications")
+	}
+
+	t.Logf("Total applications found: %d", len(apps))
+
+	// Every app has a name and path; BundleID may legitimately be empty
+	for _, app := range apps {
+		if app.Name == "" || app.Path == "" {
+			t.Errorf("ListAllApplications() returned app with missing fields: %+v", app)
+		}
+	}
+
+	firstApp := apps[0]
+	t.Logf("Sample application: Name=%s, Path=%s, BundleID=%s", firstApp.Name, firstApp.Path, firstApp.BundleID)
+}
+
+// TestListApplicationsInDirectory tests scanning a single directory for app bundles
+func TestListApplicationsInDirectory(t *testing.T) {
+	apps, err := ListApplicationsInDirectory("/System/Applications")
+	if err != nil {
+		t.Fatalf("ListApplicationsInDirectory() error = %v", err)
+	}
+
+	found := false
+	for _, app := range apps {
+		if filepath.Dir(app.Path) != "/System/Applications" {
+			t.Errorf("ListApplicationsInDirectory() returned app outside the directory: %s", app.Path)
+		}
+		if pathsMatch(app.Path, textEditPath) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ListApplicationsInDirectory() did not include TextEdit")
+	}
+
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, "Fake.app"), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	apps, err = ListApplicationsInDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("ListApplicationsInDirectory() error = %v", err)
+	}
+	if apps == nil || len(apps) != 0 {
+		t.Errorf("ListApplicationsInDirectory() = %v, want empty slice", apps)
+	}
+
+	if _, err := ListApplicationsInDirectory(filepath.Join(tmpDir, "missing")); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("ListApplicationsInDirectory() with missing directory error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestListAllRegisteredUTIsFunc tests iterating over registered UTIs with early termination
+func TestListAllRegisteredUTIsFunc(t *testing.T) {
+	utis, err := ListAllRegisteredUTIs()
+	if err != nil {
+		t.Fatalf("ListAllRegisteredUTIs() error = %v", err)
+	}
+
+	if len(utis) == 0 {
+		t.Fatalf("ListAllRegisteredUTIs() returned zero UTIs")
+	}
+
+	if !contains(utis, "public.plain-text") {
+		t.Errorf("ListAllRegisteredUTIs() does not include public.plain-text")
+	}
+
+	t.Logf("Total registered UTIs: %d", len(utis))
+
+	// Stop after the first few UTIs
+	const limit = 3
+	visited := 0
+	err = ListAllRegisteredUTIsFunc(func(uti string) bool {
+		visited++
+		return visited < limit
+	})
+	if err != nil {
+		t.Fatalf("ListAllRegisteredUTIsFunc() error = %v", err)
+	}
+
+	if len(utis) >= limit && visited != limit {
+		t.Errorf("ListAllRegisteredUTIsFunc() visited %d UTIs after stopping, want %d", visited, limit)
+	}
+
+	if err := ListAllRegisteredUTIsFunc(nil); err != ErrInvalidParameters {
+		t.Errorf("ListAllRegisteredUTIsFunc(nil) error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestListAllSchemes tests listing the URL schemes declared by installed apps
+func TestListAllSchemes(t *testing.T) {
+	schemes, err := ListAllSchemes()
+	if err != nil {
+		t.Fatalf("ListAllSchemes() error = %v", err)
+	}
+
+	if !sort.StringsAreSorted(schemes) {
+		t.Error("ListAllSchemes() result is not sorted")
+	}
+	for i := 1; i < len(schemes); i++ {
+		if schemes[i] == schemes[i-1] {
+			t.Errorf("ListAllSchemes() contains duplicate %s", schemes[i])
+		}
+	}
+
+	// Safari is part of every macOS install and declares http
+	if !contains(schemes, "http") {
+		t.Errorf("ListAllSchemes() = %v, want to contain http", schemes)
+	}
+}
+
+// TestListAllDeclaredUTIs tests listing the UTIs installed apps can open
+func TestListAllDeclaredUTIs(t *testing.T) {
+	utis, err := ListAllDeclaredUTIs()
+	if err != nil {
+		t.Fatalf("ListAllDeclaredUTIs() error = %v", err)
+	}
+
+	if !sort.StringsAreSorted(utis) {
+		t.Error("ListAllDeclaredUTIs() result is not sorted")
+	}
+	for i := 1; i < len(utis); i++ {
+		if utis[i] == utis[i-1] {
+			t.Errorf("ListAllDeclaredUTIs() contains duplicate %s", utis[i])
+		}
+	}
+
+	// TextEdit declares plain text as a document type
+	if !contains(utis, "public.plain-text") {
+		t.Error("ListAllDeclaredUTIs() does not include public.plain-text")
+	}
+
+	registered, err := ListAllRegisteredUTIs()
+	if err != nil {
+		t.Fatalf("ListAllRegisteredUTIs() error = %v", err)
+	}
+	if len(utis) > len(registered) {
+		t.Errorf("ListAllDeclaredUTIs() returned %d UTIs, more than the %d registered", len(utis), len(registered))
+	}
+}
+
+// TestForEachApplication tests visiting every application and stopping early
+func TestForEachApplication(t *testing.T) {
+	var visited int
+	err := ForEachApplication(func(app AppInfo) bool {
+		visited++
+		if app.Path == "" {
+			t.Errorf("ForEachApplication() passed an app without a path: %+v", app)
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("ForEachApplication() error = %v", err)
+	}
+	if visited == 0 {
+		t.Fatal("ForEachApplication() visited no applications")
+	}
+
+	visited = 0
+	err = ForEachApplication(func(app AppInfo) bool {
+		visited++
+		return false
+	})
+	if err != nil {
+		t.Fatalf("ForEachApplication() error = %v", err)
+	}
+	if visited != 1 {
+		t.Errorf("ForEachApplication() visited %d apps after fn returned false, want 1", visited)
+	}
+
+	if err := ForEachApplication(nil); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("ForEachApplication(nil) error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestListAllApplicationsContext tests cancellation and deadlines for context variants
+func TestListAllApplicationsContext(t *testing.T) {
+	apps, err := ListAllApplicationsContext(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllApplicationsContext() error = %v", err)
+	}
+	if len(apps) == 0 {
+		t.Errorf("ListAllApplicationsContext() returned zero applications")
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ListAllApplicationsContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListAllApplicationsContext() with cancelled context error = %v, want context.Canceled", err)
+	}
+
+	if _, err := ListAppsForUTIContext(cancelled, "public.plain-text"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListAppsForUTIContext() with cancelled context error = %v, want context.Canceled", err)
+	}
+
+	expired, cancelExpired := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancelExpired()
+	time.Sleep(time.Millisecond)
+
+	if _, err := ListAllApplicationsContext(expired); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ListAllApplicationsContext() with expired deadline error = %v, want context.DeadlineExceeded", err)
+	}
+
+	appPaths, err := ListAppsForUTIContext(context.Background(), "public.plain-text")
+	if err != nil {
+		t.Fatalf("ListAppsForUTIContext() error = %v", err)
+	}
+	t.Logf("Apps for public.plain-text: %d", len(appPaths))
+}
+
+// TestListSupportedDocumentTypes tests listing supported document types for an application
+func TestListSupportedDocumentTypes(t *testing.T) {
+	// Test with TextEdit - should exist on all macOS systems
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	docTypes, err := ListSupportedDocumentTypes(textEditPath)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypes() error = %v", err)
+	}
+
+	// TextEdit should support at least some document types
+	if len(docTypes) == 0 {
+		t.Errorf("ListSupportedDocumentTypes() returned zero document types for TextEdit")
+	}
+
+	// Verify structure is valid
+	for i, docType := range docTypes {
+		// Role should not be empty
+		if docType.Role == "" {
+			t.Errorf("ListSupportedDocumentTypes() returned empty role at index %d", i)
+		}
+
+		// Should have at least one UTI
+		if len(docType.UTIs) == 0 {
+			t.Errorf("ListSupportedDocumentTypes() returned zero UTIs at index %d", i)
+		}
+
+		// Check UTIs are non-empty
+		for j, uti := range docType.UTIs {
+			if uti == "" {
+				t.Errorf("ListSupportedDocumentTypes() returned empty UTI at index %d,%d", i, j)
+			}
+		}
+
+		// Extensions array can be empty (some UTIs don't have extensions)
+		// but if present, should be non-empty strings
+		for j, ext := range docType.Extensions {
+			if ext == "" {
+				t.Errorf("ListSupportedDocumentTypes() returned empty extension at index %d,%d", i, j)
+			}
+		}
+
+		t.Logf("Document type %d: Name=%q, Role=%q, HandlerRank=%q, UTIs=%v, Extensions=%v, IsPackage=%v",
+			i, docType.TypeName, docType.Role, docType.HandlerRank, docType.UTIs, docType.Extensions, docType.IsPackage)
+	}
+
+	t.Logf("TextEdit supports %d document types", len(docTypes))
+}
+
+// TestListSupportedDocumentTypes_ErrorCases tests error handling
+func TestListSupportedDocumentTypes_ErrorCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		appPath string
+	}{
+		{
+			name:    "non-existent app",
+			appPath: "/Applications/NonExistent.app",
+		},
+		{
+			name:    "empty path",
+			appPath: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docTypes, err := ListSupportedDocumentTypes(tt.appPath)
+			if err == nil {
+				t.Errorf("ListSupportedDocumentTypes() expected error, got nil with docTypes: %v", docTypes)
+			} else {
+				t.Logf("Got expected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestListSupportedDocumentTypes_Symlink tests that symlinked app paths resolve to the real bundle
+func TestListSupportedDocumentTypes_Symlink(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	link := filepath.Join(t.TempDir(), "TextEdit link")
+	if err := os.Symlink(textEditPath, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if resolved := resolveAppPath(link); !pathsMatch(resolved, textEditPath) {
+		t.Errorf("resolveAppPath(%q) = %q, want %q", link, resolved, textEditPath)
+	}
+
+	direct, err := ListSupportedDocumentTypes(textEditPath)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypes() error = %v", err)
+	}
+
+	viaLink, err := ListSupportedDocumentTypes(link)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypes() via symlink error = %v", err)
+	}
+
+	if len(viaLink) != len(direct) {
+		t.Errorf("ListSupportedDocumentTypes() via symlink returned %d types, want %d", len(viaLink), len(direct))
+	}
+}
+
+// TestListSupportedDocumentTypes_WithDerived tests populating UTI-derived extensions on request
+func TestListSupportedDocumentTypes_WithDerived(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	plain, err := ListSupportedDocumentTypes(textEditPath)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypes() error = %v", err)
+	}
+
+	for i, docType := range plain {
+		if docType.DerivedExtensions != nil {
+			t.Errorf("ListSupportedDocumentTypes() populated DerivedExtensions without WithDerived at index %d", i)
+		}
+	}
+
+	derived, err := ListSupportedDocumentTypes(textEditPath, WithDerived())
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypes(WithDerived()) error = %v", err)
+	}
+
+	if len(derived) != len(plain) {
+		t.Fatalf("ListSupportedDocumentTypes(WithDerived()) returned %d types, want %d", len(derived), len(plain))
+	}
+
+	for i, docType := range derived {
+		if docType.DerivedExtensions == nil {
+			t.Errorf("ListSupportedDocumentTypes(WithDerived()) left DerivedExtensions nil at index %d", i)
+		}
+
+		for _, uti := range docType.UTIs {
+			if uti == "public.html" && !contains(docType.DerivedExtensions, "html") {
+				t.Errorf("DerivedExtensions for public.html = %v, want to contain html", docType.DerivedExtensions)
+			}
+		}
+
+		t.Logf("Document type %d: Extensions=%v, DerivedExtensions=%v", i, docType.Extensions, docType.DerivedExtensions)
+	}
+}
+
+// TestListSupportedSchemes tests reading the URL schemes an app registers
+func TestListSupportedSchemes(t *testing.T) {
+	const safariPath = "/Applications/Safari.app"
+	if _, err := os.Stat(safariPath); os.IsNotExist(err) {
+		t.Skipf("Safari not found at %s, skipping test", safariPath)
+	}
+
+	schemes, err := ListSupportedSchemes(safariPath)
+	if err != nil {
+		t.Fatalf("ListSupportedSchemes() error = %v", err)
+	}
+	if !contains(schemes, "http") || !contains(schemes, "https") {
+		t.Errorf("ListSupportedSchemes() = %v, want http and https", schemes)
+	}
+
+	schemes, err = ListSupportedSchemes("/System/Applications/Calculator.app")
+	if err != nil {
+		t.Fatalf("ListSupportedSchemes() error = %v", err)
+	}
+	if schemes == nil {
+		t.Errorf("ListSupportedSchemes() returned nil, want empty slice")
+	}
+
+	_, err = ListSupportedSchemes("/Applications/NonExistent12345.app")
+	if !hasErrorCode(err, ErrInvalidApp) {
+		t.Errorf("ListSupportedSchemes() with invalid path error = %v, want ErrInvalidApp", err)
+	}
+}
+
+// TestListExportedAndImportedUTIs tests reading the UTIs an app declares
+func TestListExportedAndImportedUTIs(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	for name, list := range map[string]func(string) ([]string, error){
+		"ListExportedUTIs": ListExportedUTIs,
+		"ListImportedUTIs": ListImportedUTIs,
+	} {
+		t.Run(name, func(t *testing.T) {
+			utis, err := list(textEditPath)
+			if err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			if utis == nil {
+				t.Errorf("%s() returned nil, want empty slice", name)
+			}
+			for i := 1; i < len(utis); i++ {
+				if utis[i-1] >= utis[i] {
+					t.Errorf("%s() not sorted and unique: %v", name, utis)
+					break
+				}
+			}
+			t.Logf("%s(TextEdit) = %v", name, utis)
+
+			_, err = list("/Applications/NonExistent12345.app")
+			if !hasErrorCode(err, ErrInvalidApp) {
+				t.Errorf("%s() with invalid path error = %v, want ErrInvalidApp", name, err)
+			}
+
+			_, err = list(t.TempDir())
+			if !hasErrorCode(err, ErrInvalidApp) {
+				t.Errorf("%s() with a plain directory error = %v, want ErrInvalidApp", name, err)
+			}
+		})
+	}
+}
+
+// TestListDefaultSchemes tests that only schemes the app is the default for are returned
+func TestListDefaultSchemes(t *testing.T) {
+	const safariPath = "/Applications/Safari.app"
+	if _, err := os.Stat(safariPath); os.IsNotExist(err) {
+		t.Skipf("Safari not found at %s, skipping test", safariPath)
+	}
+
+	supported, err := ListSupportedSchemes(safariPath)
+	if err != nil {
+		t.Fatalf("ListSupportedSchemes() error = %v", err)
+	}
+
+	schemes, err := ListDefaultSchemes(safariPath)
+	if err != nil {
+		t.Fatalf("ListDefaultSchemes() error = %v", err)
+	}
+	if schemes == nil {
+		t.Errorf("ListDefaultSchemes() returned nil, want empty slice")
+	}
+
+	for _, scheme := range schemes {
+		if !contains(supported, scheme) {
+			t.Errorf("ListDefaultSchemes() returned %q which Safari does not declare", scheme)
+		}
+
+		defaultApp, err := GetDefaultAppForScheme(scheme)
+		if err != nil {
+			t.Errorf("GetDefaultAppForScheme(%q) error = %v", scheme, err)
+			continue
+		}
+		if !pathsMatch(defaultApp, safariPath) {
+			t.Errorf("ListDefaultSchemes() returned %q but its default is %s", scheme, defaultApp)
+		}
+	}
+
+	t.Logf("Safari is the default for %d of %d schemes: %v", len(schemes), len(supported), schemes)
+}
+
+// TestListDefaultDocumentTypes tests listing document types where an app is the system default
+func TestListDefaultDocumentTypes(t *testing.T) {
+	// Test with TextEdit - should exist on all macOS systems
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	defaultDocTypes, err := ListDefaultDocumentTypes(textEditPath)
+	if err != nil {
+		t.Fatalf("ListDefaultDocumentTypes() error = %v", err)
+	}
+
+	// TextEdit may or may not be the default for any types (depends on system configuration)
+	// So we can't assert a minimum count, but we can validate structure if any are returned
+	t.Logf("TextEdit is the default for %d document types", len(defaultDocTypes))
+
+	for i, docType := range defaultDocTypes {
+		// Verify structure is valid
+		if docType.Role == "" {
+			t.Errorf("ListDefaultDocumentTypes() returned empty role at index %d", i)
+		}
+
+		if len(docType.UTIs) == 0 {
+			t.Errorf("ListDefaultDocumentTypes() returned zero UTIs at index %d", i)
+		}
+
+		// Verify that this app IS actually the default for ALL returned UTIs (not just some)
+		for _, uti := range docType.UTIs {
+			defaultApp, err := GetDefaultAppForUTI(uti)
+			if err != nil {
+				t.Errorf("ListDefaultDocumentTypes() returned UTI %s at index %d, but got error when checking default: %v", uti, i, err)
+				continue
+			}
+
+			if !pathsMatch(defaultApp, textEditPath) {
+				t.Errorf("ListDefaultDocumentTypes() returned UTI %s at index %d, but TextEdit is not the default (default is %s)",
+					uti, i, defaultApp)
+			}
+		}
+
+		t.Logf("Default document type %d: Name=%q, Role=%q, UTIs=%v, Extensions=%v",
+			i, docType.TypeName, docType.Role, docType.UTIs, docType.Extensions)
+	}
+
+	// Also verify that we're filtering correctly - supported types should be >= default types
+	supportedDocTypes, err := ListSupportedDocumentTypes(textEditPath)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypes() error = %v", err)
+	}
+
+	if len(defaultDocTypes) > len(supportedDocTypes) {
+		t.Errorf("ListDefaultDocumentTypes() returned %d types, but ListSupportedDocumentTypes() returned %d - default should be a subset",
+			len(defaultDocTypes), len(supportedDocTypes))
+	}
+
+	t.Logf("TextEdit supports %d types total, is default for %d", len(supportedDocTypes), len(defaultDocTypes))
+}
+
+// BenchmarkListDefaultDocumentTypes compares resolving extensions serially and with the worker pool
+func BenchmarkListDefaultDocumentTypes(b *testing.B) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		b.Skipf("TextEdit not found at %s, skipping benchmark", textEditPath)
+	}
+
+	original := documentTypeWorkers
+	defer func() { documentTypeWorkers = original }()
+
+	for _, workers := range []int{1, original} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			documentTypeWorkers = workers
+			for b.Loop() {
+				if _, err := ListDefaultDocumentTypes(textEditPath); err != nil {
+					b.Fatalf("ListDefaultDocumentTypes() error = %v", err)
+				}
 			}
+		})
+	}
+}
+
+// Helper function to check whether a slice contains a string
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
 		}
+	}
+	return false
+}
 
-		t.Logf("Default document type %d: Name=%q, Role=%q, UTIs=%v, Extensions=%v",
-			i, docType.TypeName, docType.Role, docType.UTIs, docType.Extensions)
+// Helper function to compare app paths (handles symlinks and normalization)
+func pathsMatch(path1, path2 string) bool {
+	return appPathsEqual(path1, path2)
+}
+
+// TestGroupSupportedDocumentTypesByFamily tests that each document type lands in exactly one family
+func TestGroupSupportedDocumentTypesByFamily(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
 	}
 
-	// Also verify that we're filtering correctly - supported types should be >= default types
-	supportedDocTypes, err := ListSupportedDocumentTypes(textEditPath)
+	groups, err := GroupSupportedDocumentTypesByFamily(textEditPath)
+	if err != nil {
+		t.Fatalf("GroupSupportedDocumentTypesByFamily() error = %v", err)
+	}
+
+	docTypes, err := ListSupportedDocumentTypes(textEditPath)
 	if err != nil {
 		t.Fatalf("ListSupportedDocumentTypes() error = %v", err)
 	}
 
-	if len(defaultDocTypes) > len(supportedDocTypes) {
-		t.Errorf("ListDefaultDocumentTypes() returned %d types, but ListSupportedDocumentTypes() returned %d - default should be a subset",
-			len(defaultDocTypes), len(supportedDocTypes))
+	total := 0
+	for family, members := range groups {
+		total += len(members)
+		t.Logf("Family %s: %d document types", family, len(members))
 	}
 
-	t.Logf("TextEdit supports %d types total, is default for %d", len(supportedDocTypes), len(defaultDocTypes))
+	if total != len(docTypes) {
+		t.Errorf("GroupSupportedDocumentTypesByFamily() grouped %d types, want %d", total, len(docTypes))
+	}
+
+	// SVG conforms to both public.image and public.text
+	svgConforms := func(uti, parentUTI string) bool {
+		ok, err := ConformsTo(uti, parentUTI)
+		return err == nil && ok
+	}
+	if got := primaryFamily([]string{"public.svg-image"}, svgConforms); got != FamilyImage {
+		t.Errorf("primaryFamily(public.svg-image) = %q, want %q", got, FamilyImage)
+	}
 }
 
-// Helper function to compare app paths (handles symlinks and normalization)
-func pathsMatch(path1, path2 string) bool {
-	// Clean both paths
-	clean1 := filepath.Clean(path1)
-	clean2 := filepath.Clean(path2)
+// TestListSupportedDocumentTypesByRole tests filtering an application's document types by role
+func TestListSupportedDocumentTypesByRole(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
 
-	// Direct match
-	if clean1 == clean2 {
-		return true
+	editable, err := ListSupportedDocumentTypesByRole(textEditPath, RoleEditor)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypesByRole() error = %v", err)
+	}
+	if len(editable) == 0 {
+		t.Errorf("ListSupportedDocumentTypesByRole(RoleEditor) returned no types for TextEdit")
+	}
+	for _, docType := range editable {
+		if docType.Role != RoleEditor {
+			t.Errorf("ListSupportedDocumentTypesByRole(RoleEditor) returned %q with role %q", docType.TypeName, docType.Role)
+		}
+	}
+
+	all, err := ListSupportedDocumentTypesByRole(textEditPath, RoleAll)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypesByRole(RoleAll) error = %v", err)
+	}
+	docTypes, err := ListSupportedDocumentTypes(textEditPath)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypes() error = %v", err)
+	}
+	if len(all) != len(docTypes) {
+		t.Errorf("ListSupportedDocumentTypesByRole(RoleAll) returned %d types, want %d", len(all), len(docTypes))
+	}
+
+	if _, err := ListSupportedDocumentTypesByRole(textEditPath, Role("Owner")); err != ErrInvalidParameters {
+		t.Errorf("ListSupportedDocumentTypesByRole(Owner) error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestGetDocumentTypeForUTI tests looking up a single document type for an app and UTI
+func TestGetDocumentTypeForUTI(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	docTypes, err := ListSupportedDocumentTypes(textEditPath)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypes() error = %v", err)
+	}
+	if len(docTypes) == 0 || len(docTypes[0].UTIs) == 0 {
+		t.Skip("TextEdit declares no document type UTIs")
+	}
+
+	uti := docTypes[0].UTIs[0]
+	docType, err := GetDocumentTypeForUTI(textEditPath, uti)
+	if err != nil {
+		t.Fatalf("GetDocumentTypeForUTI(%s) error = %v", uti, err)
+	}
+	if !contains(docType.UTIs, uti) {
+		t.Errorf("GetDocumentTypeForUTI(%s) returned %q with UTIs %v", uti, docType.TypeName, docType.UTIs)
+	}
+
+	_, err = GetDocumentTypeForUTI(textEditPath, "com.example.not-declared")
+	if !hasErrorCode(err, ErrNotFound) {
+		t.Errorf("GetDocumentTypeForUTI(undeclared) error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestAppSupportsUTI tests checking an application's support for a UTI
+func TestAppSupportsUTI(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	tests := []struct {
+		name string
+		uti  string
+		want bool
+	}{
+		{"plain text", "public.plain-text", true},
+		{"conforming subtype", "public.utf8-plain-text", true},
+		{"unrelated type", "public.mpeg-4", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AppSupportsUTI(textEditPath, tt.uti)
+			if err != nil {
+				t.Fatalf("AppSupportsUTI(%s) error = %v", tt.uti, err)
+			}
+			if got != tt.want {
+				t.Errorf("AppSupportsUTI(%s) = %v, want %v", tt.uti, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetAppProfile tests that the profile matches the individual queries
+func TestGetAppProfile(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	profile, err := GetAppProfile(textEditPath)
+	if err != nil {
+		t.Fatalf("GetAppProfile() error = %v", err)
+	}
+
+	if profile.BundleID != "com.apple.TextEdit" {
+		t.Errorf("GetAppProfile() BundleID = %q, want com.apple.TextEdit", profile.BundleID)
+	}
+
+	docTypes, err := ListSupportedDocumentTypes(textEditPath)
+	if err != nil {
+		t.Fatalf("ListSupportedDocumentTypes() error = %v", err)
+	}
+	if len(profile.SupportedDocumentTypes) != len(docTypes) {
+		t.Errorf("GetAppProfile() has %d supported types, want %d", len(profile.SupportedDocumentTypes), len(docTypes))
+	}
+
+	defaultTypes, err := ListDefaultDocumentTypes(textEditPath)
+	if err != nil {
+		t.Fatalf("ListDefaultDocumentTypes() error = %v", err)
+	}
+	if len(profile.DefaultDocumentTypes) != len(defaultTypes) {
+		t.Errorf("GetAppProfile() has %d default types, want %d", len(profile.DefaultDocumentTypes), len(defaultTypes))
+	}
+
+	_, err = GetAppProfile("/nonexistent/App.app")
+	if err == nil {
+		t.Error("GetAppProfile() expected error for nonexistent app")
+	}
+}
+
+// TestGetDefaultBrowser tests that the default browser is the https handler
+func TestGetDefaultBrowser(t *testing.T) {
+	browser, err := GetDefaultBrowser()
+	if hasErrorCode(err, ErrNotFound) {
+		t.Skip("No default browser set")
+	}
+	if err != nil {
+		t.Fatalf("GetDefaultBrowser() error = %v", err)
+	}
+
+	httpsApp, err := GetDefaultAppForScheme("https")
+	if err != nil {
+		t.Fatalf("GetDefaultAppForScheme(https) error = %v", err)
+	}
+	if !pathsMatch(browser.Path, httpsApp) {
+		t.Errorf("GetDefaultBrowser() = %s, want %s", browser.Path, httpsApp)
+	}
+}
+
+// TestGetDefaultTextEditor tests that the default text editor is the plain text handler
+func TestGetDefaultTextEditor(t *testing.T) {
+	editor, err := GetDefaultTextEditor()
+	if err != nil {
+		t.Fatalf("GetDefaultTextEditor() error = %v", err)
+	}
+
+	plainTextApp, err := GetDefaultAppForUTI("public.plain-text")
+	if err != nil {
+		t.Fatalf("GetDefaultAppForUTI(public.plain-text) error = %v", err)
+	}
+	if !pathsMatch(editor.Path, plainTextApp) {
+		t.Errorf("GetDefaultTextEditor() = %s, want %s", editor.Path, plainTextApp)
+	}
+}
+
+// TestFindConflictingHandlers tests that the competing handlers all share one strong rank
+func TestFindConflictingHandlers(t *testing.T) {
+	claimants, err := FindConflictingHandlers("public.plain-text")
+	if err != nil {
+		t.Fatalf("FindConflictingHandlers() error = %v", err)
+	}
+
+	for i := 1; i < len(claimants); i++ {
+		if claimants[i-1].Path > claimants[i].Path {
+			t.Errorf("FindConflictingHandlers() not sorted: %s before %s", claimants[i-1].Path, claimants[i].Path)
+		}
+	}
+	t.Logf("%d apps share the strongest claim on public.plain-text", len(claimants))
+
+	if _, err := FindConflictingHandlers(""); err != ErrInvalidParameters {
+		t.Errorf("FindConflictingHandlers(\"\") error = %v, want ErrInvalidParameters", err)
+	}
+}
+
+// TestRegisterApp tests re-registering an installed app with LaunchServices
+func TestRegisterApp(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	if err := RegisterApp(textEditPath); err != nil {
+		t.Errorf("RegisterApp(TextEdit) error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		appPath string
+	}{
+		{"nonexistent", "/nonexistent/App.app"},
+		{"not a bundle", "/tmp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterApp(tt.appPath)
+			if !hasErrorCode(err, ErrInvalidApp) {
+				t.Errorf("RegisterApp(%s) error = %v, want ErrInvalidApp", tt.appPath, err)
+			}
+		})
+	}
+}
+
+// TestCheckSchemeHandlerConsistency tests that scheme handlers declare the schemes they handle
+func TestCheckSchemeHandlerConsistency(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  string
+		wantErr bool
+	}{
+		{
+			name:   "http",
+			scheme: "http",
+		},
+		{
+			name:   "mailto",
+			scheme: "mailto",
+		},
+		{
+			name:    "empty scheme",
+			scheme:  "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consistent, appPath, err := CheckSchemeHandlerConsistency(tt.scheme)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("CheckSchemeHandlerConsistency() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("CheckSchemeHandlerConsistency() error = %v", err)
+				return
+			}
+
+			if !consistent {
+				t.Errorf("CheckSchemeHandlerConsistency(%q) = false, default app %s does not declare the scheme", tt.scheme, appPath)
+			}
+
+			t.Logf("Handler for %s: %s (consistent=%v)", tt.scheme, appPath, consistent)
+		})
+	}
+}
+
+// TestGetAllDefaultHandlers tests dumping the explicit UTI defaults
+func TestGetAllDefaultHandlers(t *testing.T) {
+	defaults, err := GetAllDefaultHandlers()
+	if err != nil {
+		t.Fatalf("GetAllDefaultHandlers() error = %v", err)
+	}
+	if defaults == nil {
+		t.Fatalf("GetAllDefaultHandlers() returned nil map")
+	}
+
+	for uti, appPath := range defaults {
+		if uti == "" || appPath == "" {
+			t.Errorf("GetAllDefaultHandlers() returned empty entry %q -> %q", uti, appPath)
+		}
+	}
+
+	t.Logf("Found %d explicit UTI defaults", len(defaults))
+}
+
+// TestSnapshotRestoreDefaults_RoundTrip tests that restoring a snapshot undoes a change
+func TestSnapshotRestoreDefaults_RoundTrip(t *testing.T) {
+	if _, err := os.Stat(textEditPath); os.IsNotExist(err) {
+		t.Skipf("TextEdit not found at %s, skipping test", textEditPath)
+	}
+
+	testUTI := "public.plain-text"
+
+	originalApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to get original default app: %v", err)
+	}
+
+	defer func() {
+		if originalApp != "" {
+			_ = SetDefaultForUTIForce(originalApp, testUTI)
+		}
+	}()
+
+	// Make sure the UTI has an explicit handler so it is part of the snapshot
+	if err := SetDefaultForUTIForce(originalApp, testUTI); err != nil {
+		t.Fatalf("SetDefaultForUTIForce() error = %v", err)
+	}
+
+	snapshot, err := SnapshotDefaults()
+	if err != nil {
+		t.Fatalf("SnapshotDefaults() error = %v", err)
+	}
+	if _, ok := snapshot.UTIs[testUTI]; !ok {
+		t.Fatalf("SnapshotDefaults() missing %s", testUTI)
+	}
+
+	otherApp := "/System/Applications/Preview.app"
+	if pathsMatch(originalApp, otherApp) {
+		otherApp = textEditPath
+	}
+	if err := SetDefaultForUTI(otherApp, testUTI); err != nil {
+		t.Fatalf("SetDefaultForUTI() error = %v", err)
+	}
+
+	// Only restore the entry under test to avoid touching unrelated defaults
+	restore := HandlerSnapshot{UTIs: map[string]AppInfo{testUTI: snapshot.UTIs[testUTI]}}
+	if err := RestoreDefaults(restore); err != nil {
+		t.Fatalf("RestoreDefaults() error = %v", err)
+	}
+
+	currentApp, err := GetDefaultAppForUTI(testUTI)
+	if err != nil {
+		t.Fatalf("Failed to verify default app: %v", err)
+	}
+	if !pathsMatch(currentApp, originalApp) {
+		t.Errorf("RestoreDefaults() left default = %s, want %s", currentApp, originalApp)
+	}
+}
+
+// TestWatchDefaultChanges tests that watching starts and that stop closes the channel
+func TestWatchDefaultChanges(t *testing.T) {
+	events, stop, err := WatchDefaultChanges()
+	if err != nil {
+		t.Fatalf("WatchDefaultChanges() error = %v", err)
+	}
+
+	stop()
+
+	// stop returns after the channel is closed, so draining it must end
+	for range events {
+	}
+}
+
+// TestWatchApplicationChanges tests that watching starts and that stop closes the channel
+func TestWatchApplicationChanges(t *testing.T) {
+	events, stop, err := WatchApplicationChanges()
+	if err != nil {
+		t.Fatalf("WatchApplicationChanges() error = %v", err)
+	}
+
+	stop()
+
+	for range events {
+	}
+}
+
+// TestDumpHandlerConfiguration tests producing the configuration report from the system
+func TestDumpHandlerConfiguration(t *testing.T) {
+	report, err := DumpHandlerConfiguration()
+	if err != nil {
+		t.Fatalf("DumpHandlerConfiguration() error = %v", err)
+	}
+
+	if !strings.HasPrefix(report, "Handler configuration") {
+		t.Errorf("DumpHandlerConfiguration() returned unexpected report header: %q", report)
+	}
+
+	t.Logf("Handler configuration:\n%s", report)
+}
+
+// TestRepeatedCallsDoNotLeak tests that success and error paths release their C memory
+//
+// Peak RSS only ever rises, so after a warm-up it is sampled around two equal
+// windows of calls. One-off growth such as caches filling up lands in the
+// first window; a leak raises the peak again in the second. The test takes a
+// while and is only run when BRIDGE_LEAK_TEST=1 is set.
+func TestRepeatedCallsDoNotLeak(t *testing.T) {
+	if testing.Short() || os.Getenv("BRIDGE_LEAK_TEST") != "1" {
+		t.Skip("set BRIDGE_LEAK_TEST=1 to run the leak test")
 	}
 
-	// Try resolving symlinks
-	real1, err1 := filepath.EvalSymlinks(clean1)
-	real2, err2 := filepath.EvalSymlinks(clean2)
+	iterate := func(n int) {
+		for i := 0; i < n; i++ {
+			_, _ = GetDefaultAppForUTI("public.plain-text")
+			_, _ = GetDefaultAppForUTI("com.example.nonexistent")
+			_, _ = GetDefaultAppInfoForUTI("public.html")
+			_, _ = ResolveUTIsForExtension("txt")
+			_, _ = ResolveUTIsForExtension("nonexistentext12345")
+			_, _ = ResolveExtensionsForUTI("public.jpeg")
+			_, _ = ListAppsForUTI("public.plain-text")
+			_, _ = ListAppsForUTI("com.example.nonexistent")
+			_, _ = ListSupportedDocumentTypes(textEditPath)
+			_, _ = ListSupportedDocumentTypes("/nonexistent/App.app")
+		}
+	}
 
-	if err1 == nil && err2 == nil {
-		return real1 == real2
+	maxRSS := func() int64 {
+		// Return freed Go memory first so the Go heap does not raise the peak
+		debug.FreeOSMemory()
+
+		var usage syscall.Rusage
+		if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+			t.Fatalf("Getrusage() error = %v", err)
+		}
+		return usage.Maxrss // bytes on macOS
 	}
 
-	// Case-insensitive match (macOS filesystems are often case-insensitive)
-	return strings.EqualFold(clean1, clean2)
+	const window = 3000
+	const calls = window * 10
+
+	iterate(500)
+	start := maxRSS()
+	iterate(window)
+	middle := maxRSS()
+	iterate(window)
+	end := maxRSS()
+
+	first, second := middle-start, end-middle
+	t.Logf("peak RSS grew by %d bytes in the first window and %d in the second", first, second)
+
+	// 16 bytes leaked per call would add about 480 KiB per window
+	const limit = 256 << 10
+	if second > limit {
+		t.Errorf("peak RSS grew by %d bytes (%d per call) in the second window of %d calls, want at most %d",
+			second, second/calls, calls, limit)
+	}
 }
@@ -0,0 +1,77 @@
+package bridge
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache serves ListAllApplications from an in-memory snapshot until its TTL expires
+//
+// A Cache is safe for concurrent use. Callers that want fresh data every time
+// should use the package-level ListAllApplications instead.
+type Cache struct {
+	handler Handler
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	apps      []AppInfo
+	fetchedAt time.Time
+	valid     bool
+}
+
+// NewCache returns a Cache whose snapshot is refreshed once it is older than ttl
+//
+// A ttl of zero or less disables caching, so every call scans the system.
+//
+// Parameters:
+//   - ttl: How long a snapshot is served before the next call refreshes it
+//
+// Returns:
+//   - cache: Cache backed by the default Handler
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		handler: defaultHandler,
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// ListAllApplications returns the installed applications, scanning the system only when the snapshot has expired
+//
+// Errors are not cached; the next call tries again.
+//
+// Returns:
+//   - apps: Slice of AppInfo structures; the caller may modify it freely
+//   - error: Error if the scan fails
+func (c *Cache) ListAllApplications() ([]AppInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.valid || c.now().Sub(c.fetchedAt) >= c.ttl {
+		apps, err := c.handler.ListAllApplications()
+		if err != nil {
+			return nil, err
+		}
+
+		c.apps = apps
+		c.fetchedAt = c.now()
+		c.valid = true
+	}
+
+	apps := make([]AppInfo, len(c.apps))
+	copy(apps, c.apps)
+
+	return apps, nil
+}
+
+// Invalidate drops the snapshot so the next ListAllApplications call scans the system
+//
+// Call it when the set of installed applications is known to have changed.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.apps = nil
+	c.valid = false
+}
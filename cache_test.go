@@ -0,0 +1,100 @@
+package bridge
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingHandler counts ListAllApplications calls for cache tests
+type countingHandler struct {
+	Handler
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *countingHandler) ListAllApplications() ([]AppInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	return []AppInfo{{Name: "TextEdit", Path: "/System/Applications/TextEdit.app"}}, nil
+}
+
+// TestCacheListAllApplications tests that the snapshot is reused until the TTL expires or it is invalidated
+func TestCacheListAllApplications(t *testing.T) {
+	original := defaultHandler
+	defer func() { defaultHandler = original }()
+
+	handler := &countingHandler{}
+	defaultHandler = handler
+
+	now := time.Unix(0, 0)
+	cache := NewCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		invalid   bool
+		wantCalls int
+	}{
+		{name: "first call scans", wantCalls: 1},
+		{name: "within TTL", advance: 30 * time.Second, wantCalls: 1},
+		{name: "TTL expired", advance: 30 * time.Second, wantCalls: 2},
+		{name: "invalidated", invalid: true, wantCalls: 3},
+		{name: "after refresh", advance: time.Second, wantCalls: 3},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.invalid {
+			cache.Invalidate()
+		}
+
+		apps, err := cache.ListAllApplications()
+		if err != nil {
+			t.Fatalf("%s: ListAllApplications() error = %v", step.name, err)
+		}
+		if len(apps) != 1 {
+			t.Errorf("%s: ListAllApplications() = %v, want 1 app", step.name, apps)
+		}
+		if handler.calls != step.wantCalls {
+			t.Errorf("%s: handler called %d times, want %d", step.name, handler.calls, step.wantCalls)
+		}
+	}
+
+	// Callers get their own copy of the snapshot
+	apps, _ := cache.ListAllApplications()
+	apps[0].Name = "changed"
+	if apps, _ := cache.ListAllApplications(); apps[0].Name != "TextEdit" {
+		t.Errorf("snapshot was modified through a returned slice: %v", apps)
+	}
+}
+
+// TestCacheConcurrentUse tests that concurrent callers share one scan
+func TestCacheConcurrentUse(t *testing.T) {
+	original := defaultHandler
+	defer func() { defaultHandler = original }()
+
+	handler := &countingHandler{}
+	defaultHandler = handler
+
+	cache := NewCache(time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.ListAllApplications(); err != nil {
+				t.Errorf("ListAllApplications() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if handler.calls != 1 {
+		t.Errorf("handler called %d times, want 1", handler.calls)
+	}
+}
@@ -0,0 +1,1846 @@
+package bridge
+
+import "context"
+
+// Handler is the set of operations the package provides
+//
+// The package-level functions call the default Handler returned by NewHandler.
+// Handler grows as the package gains operations, so code that only needs part
+// of it should depend on the smaller interface it embeds instead, such as
+// DefaultsReader or TypeResolver. Test doubles that must satisfy Handler
+// itself can embed it and override only the methods under test.
+type Handler interface {
+	DefaultsReader
+	DefaultsWriter
+	FileOpener
+	TypeResolver
+	AppManager
+}
+
+// DefaultsReader is the set of queries for the current default handlers
+type DefaultsReader interface {
+	GetDefaultAppForUTI(uti string) (string, error)
+	GetDefaultAppsForUTIs(utis []string) (map[string]string, error)
+	GetDefaultAppInfoForUTI(uti string) (AppInfo, error)
+	GetDefaultAppForScheme(scheme string) (string, error)
+	GetDefaultAppInfoForScheme(scheme string) (AppInfo, error)
+	GetDefaultAppForURLContentType() (string, error)
+	GetDefaultAppForExtension(extension string) (string, error)
+	GetDefaultAppForFile(filePath string) (AppInfo, error)
+	IsDefaultAppForUTI(appPath, uti string) (bool, error)
+	CheckSchemeHandlerConsistency(scheme string) (bool, string, error)
+	GetAllDefaultHandlers() (map[string]string, error)
+	WatchDefaultChanges() (<-chan HandlerChangeEvent, func(), error)
+	DumpHandlerConfiguration() (string, error)
+	GetDefaultBrowser() (AppInfo, error)
+	GetDefaultMailClient() (AppInfo, error)
+	GetDefaultTextEditor() (AppInfo, error)
+	GetDefaultTerminal() (AppInfo, error)
+}
+
+// DefaultsWriter is the set of operations that change default handlers
+type DefaultsWriter interface {
+	SetDefaultForUTI(appPath, uti string) error
+	SetDefaultForUTIForce(appPath, uti string) error
+	SetDefaultForUTIVerified(appPath, uti string) error
+	SetDefaultForUTIConfirmed(appPath, uti string) error
+	SetDefaultForUTIReturningPrevious(appPath, uti string) (string, error)
+	SetDefaultForUTIWithRole(appPath, uti string, role Role) error
+	SetDefaultForExtension(appPath, extension string) error
+	ResetDefaultForUTI(uti string) error
+	SetDefaultForScheme(appPath, scheme string) error
+	SetDefaultForSchemeVerified(appPath, scheme string) error
+	ResetDefaultForScheme(scheme string) error
+	SnapshotDefaults() (HandlerSnapshot, error)
+	RestoreDefaults(snapshot HandlerSnapshot) error
+	ResolveSnapshot(snapshot HandlerSnapshot) (HandlerSnapshot, []string, error)
+	SetDefaultForUTIByBundleID(bundleID, uti string) error
+	SetDefaultForExtensionByBundleID(bundleID, extension string) error
+	SetDefaultForSchemeByBundleID(bundleID, scheme string) error
+	SetDefaultBrowser(appPath string) error
+	SetDefaultMailClient(appPath string) error
+	SetDefaultTextEditor(appPath string) error
+}
+
+// FileOpener is the set of operations that open files in applications
+type FileOpener interface {
+	OpenFile(filePath string) error
+	OpenFileWithApp(filePath, appPath string) error
+	OpenFilesWithApp(appPath string, filePaths []string) error
+}
+
+// TypeResolver is the set of Uniform Type Identifier lookups
+type TypeResolver interface {
+	ResolveUTIsForExtension(extension string) ([]string, error)
+	ResolveUTIsForExtensionDeclaredOnly(extension string) ([]string, error)
+	PreferredUTIForExtension(extension string) (string, error)
+	ResolveExtensionsForUTI(uti string) ([]string, error)
+	ResolveExtensionsForUTIs(utis []string) (map[string][]string, error)
+	PreferredExtensionForUTI(uti string) (string, error)
+	GetTagSpecification(uti string) (TagSpec, error)
+	ResolveMIMETypesForUTI(uti string) ([]string, error)
+	ResolveUTIsForMIMEType(mimeType string) ([]string, error)
+	ResolveUTIForFile(filePath string) (string, error)
+	IsDynamicUTI(uti string) bool
+	IsRegisteredUTI(uti string) (bool, error)
+	ExtensionsShareUTI(extA, extB string) (bool, error)
+	ConformsTo(uti, parentUTI string) (bool, error)
+	GetConformingUTIs(uti string) ([]string, error)
+	GetUTIDescription(uti string) (string, error)
+	GetSchemeDescription(scheme string) (string, error)
+	GetUTIIconPNG(uti string, size int) ([]byte, error)
+	ListAllRegisteredUTIs() ([]string, error)
+	ListAllRegisteredUTIsFunc(fn func(uti string) bool) error
+	ListAllSchemes() ([]string, error)
+	ListAllDeclaredUTIs() ([]string, error)
+}
+
+// AppManager is the set of operations on installed and running applications
+type AppManager interface {
+	ValidateAppBundle(appPath string) error
+	ListAppsForUTI(uti string) ([]string, error)
+	ListAppInfosForUTI(uti string) ([]AppInfo, error)
+	ListAppsForUTIWithRoles(uti string) ([]RankedApp, error)
+	FindConflictingHandlers(uti string) ([]AppInfo, error)
+	ListAppsForUTIWithRole(uti string, role Role) ([]string, error)
+	ListAppsForUTIContext(ctx context.Context, uti string) ([]string, error)
+	ListAppsForUTIs(utis []string) (map[string][]string, error)
+	AppsHandlingAllUTIs(utis []string) ([]string, error)
+	AppsHandlingAnyUTI(utis []string) ([]string, error)
+	ListAppsForScheme(scheme string) ([]string, error)
+	GetAllHandlersForUTIByRole(uti string) (map[string][]AppInfo, error)
+	ListAllApplications() ([]AppInfo, error)
+	ForEachApplication(fn func(app AppInfo) bool) error
+	ListAllApplicationsContext(ctx context.Context) ([]AppInfo, error)
+	ListAllApplicationsDeduplicated() ([]AppInfo, error)
+	ListApplicationsInDirectory(dir string) ([]AppInfo, error)
+	RebuildLaunchServicesDatabase() error
+	WatchApplicationChanges() (<-chan AppChangeEvent, func(), error)
+	RegisterApp(appPath string) error
+	FindAppsByName(query string) ([]AppInfo, error)
+	ListApplicationsByCategory(category string) ([]AppInfo, error)
+	FindAppByBundleID(bundleID string) (AppInfo, error)
+	GetAppInfoForPID(pid int) (AppInfo, error)
+	GetFrontmostApp() (AppInfo, error)
+	IsAppRunning(bundleID string) (bool, error)
+	ActivateApp(bundleID string) error
+	QuitApp(bundleID string, force bool) error
+	GetBundleID(appPath string) (string, error)
+	GetAppSummary(appPath string) (AppSummary, error)
+	GetBundleInfoValue(appPath, key string) (string, error)
+	GetAppCategory(appPath string) (string, error)
+	IsSandboxed(appPath string) (bool, error)
+	GetCodeSigningTeamID(appPath string) (string, error)
+	GetAppProfile(appPath string) (AppProfile, error)
+	GetAppIconPNG(appPath string, size int) ([]byte, error)
+	ListSupportedDocumentTypes(appPath string, opts ...DocumentTypeOption) ([]DocumentType, error)
+	ListSupportedDocumentTypesByRole(appPath string, role Role) ([]DocumentType, error)
+	GetDocumentTypeForUTI(appPath, uti string) (DocumentType, error)
+	AppSupportsUTI(appPath, uti string) (bool, error)
+	ListSupportedSchemes(appPath string) ([]string, error)
+	ListExportedUTIs(appPath string) ([]string, error)
+	ListImportedUTIs(appPath string) ([]string, error)
+	ListDefaultSchemes(appPath string) ([]string, error)
+	ListDefaultDocumentTypes(appPath string) ([]DocumentType, error)
+	GroupSupportedDocumentTypesByFamily(appPath string) (map[TypeFamily][]DocumentType, error)
+}
+
+// systemHandler implements Handler using the system's LaunchServices and type
+// system; on platforms other than macOS every method returns ErrUnsupportedPlatform
+type systemHandler struct{}
+
+// defaultHandler backs the package-level functions
+var defaultHandler Handler = NewHandler()
+
+// NewHandler returns a Handler backed by the system's LaunchServices
+//
+// Returns:
+//   - handler: Handler implementation that talks to macOS
+func NewHandler() Handler {
+	return &systemHandler{}
+}
+
+// GetDefaultAppForUTI returns the default application path for a UTI
+//
+// The URL content types used on the pasteboard rarely have a content-type
+// handler of their own. When the lookup fails for them, the scheme handler is
+// returned instead:
+//   - public.url: the default handler for "http" (the default browser)
+//   - public.file-url: the default handler for "file"
+//
+// The returned path is checked with ValidateAppBundle; a stale LaunchServices
+// entry that no longer points at an app yields ErrDefaultHandlerInvalid.
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier (e.g., "public.plain-text")
+//
+// Returns:
+//   - appPath: Full path to the default application bundle
+//   - error: Error if any
+func GetDefaultAppForUTI(uti string) (string, error) {
+	return defaultHandler.GetDefaultAppForUTI(uti)
+}
+
+// GetDefaultAppsForUTIs returns the default application paths for several UTIs in a single call
+//
+// Each UTI is resolved exactly like GetDefaultAppForUTI, including the
+// public.url and public.file-url fallback to their scheme handlers and the
+// check that the handler is still an application bundle. UTIs that are
+// unknown, have no default or whose default fails that check are omitted from
+// the result rather than failing the whole batch.
+//
+// Parameters:
+//   - utis: Uniform Type Identifiers to look up
+//
+// Returns:
+//   - defaults: Map from UTI to the full path of its default application bundle
+//   - error: Error if any
+func GetDefaultAppsForUTIs(utis []string) (map[string]string, error) {
+	return defaultHandler.GetDefaultAppsForUTIs(utis)
+}
+
+// GetDefaultAppInfoForUTI returns the default application for a UTI with its name and bundle ID
+//
+// This is equivalent to GetDefaultAppForUTI followed by a lookup of the app's
+// metadata, but needs only a single call into the bridge. public.url and
+// public.file-url fall back to their scheme handlers the same way.
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier (e.g., "public.plain-text")
+//
+// Returns:
+//   - app: AppInfo for the default application
+//   - error: ErrNotFound BridgeError if no default is set, or other error
+func GetDefaultAppInfoForUTI(uti string) (AppInfo, error) {
+	return defaultHandler.GetDefaultAppInfoForUTI(uti)
+}
+
+// GetDefaultAppForScheme returns the default application path for a URL scheme
+//
+// Parameters:
+//   - scheme: The URL scheme (e.g., "http", "mailto")
+//
+// Returns:
+//   - appPath: Full path to the default application bundle
+//   - error: Error if any
+func GetDefaultAppForScheme(scheme string) (string, error) {
+	return defaultHandler.GetDefaultAppForScheme(scheme)
+}
+
+// GetDefaultAppInfoForScheme returns the default application for a URL scheme with its name and bundle ID
+//
+// Parameters:
+//   - scheme: The URL scheme (e.g., "http", "mailto")
+//
+// Returns:
+//   - app: AppInfo for the default application
+//   - error: ErrNotFound BridgeError if no handler is registered, or other error
+func GetDefaultAppInfoForScheme(scheme string) (AppInfo, error) {
+	return defaultHandler.GetDefaultAppInfoForScheme(scheme)
+}
+
+// GetDefaultAppForURLContentType returns the default application for copied URLs (public.url)
+//
+// This resolves public.url through GetDefaultAppForUTI, which falls back to
+// the default "http" handler when no content-type handler is registered.
+//
+// Returns:
+//   - appPath: Full path to the default application bundle
+//   - error: Error if any
+func GetDefaultAppForURLContentType() (string, error) {
+	return defaultHandler.GetDefaultAppForURLContentType()
+}
+
+// GetDefaultAppForExtension returns the default application path for a file extension
+//
+// The extension's preferred UTI is tried first, so this reads back what
+// SetDefaultForExtension writes. If it has no default, the extension's other
+// UTIs are tried in the order the type system returns them; the first UTI
+// with a registered default wins.
+//
+// Parameters:
+//   - extension: File extension, with or without a leading dot (e.g., "txt", ".md")
+//
+// Returns:
+//   - appPath: Full path to the default application bundle
+//   - error: ErrNotFound BridgeError if none of the UTIs have a default, or other error
+func GetDefaultAppForExtension(extension string) (string, error) {
+	return defaultHandler.GetDefaultAppForExtension(extension)
+}
+
+// GetDefaultAppForFile returns the application that opens a file on this machine
+//
+// The file's UTI is resolved with ResolveUTIForFile and its default handler is
+// looked up with GetDefaultAppInfoForUTI.
+//
+// Parameters:
+//   - filePath: Full path to the file
+//
+// Returns:
+//   - app: AppInfo of the default application for the file
+//   - error: ErrInvalidParameters if the path is empty or the file does not exist,
+//     ErrNotFound BridgeError if the file's type or its default handler cannot be found, or other error
+func GetDefaultAppForFile(filePath string) (AppInfo, error) {
+	return defaultHandler.GetDefaultAppForFile(filePath)
+}
+
+// IsDefaultAppForUTI reports whether an application is the current default handler for a UTI
+//
+// Paths are compared after resolving symlinks, and case-insensitively when
+// they cannot be resolved, so "/Applications/Safari.app" matches its
+// /System/Volumes/Preboot/Cryptexes counterpart.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - isDefault: true if the app is the default handler for the UTI
+//   - error: Error for invalid inputs or system failures; a different default is not an error
+func IsDefaultAppForUTI(appPath, uti string) (bool, error) {
+	return defaultHandler.IsDefaultAppForUTI(appPath, uti)
+}
+
+// CheckSchemeHandlerConsistency reports whether the default handler for a URL scheme still declares it
+//
+// After an app update the configured scheme handler may no longer list the
+// scheme in its CFBundleURLTypes. In that case consistent is false and appPath
+// identifies the stale handler.
+//
+// Parameters:
+//   - scheme: The URL scheme (e.g., "http", "mailto")
+//
+// Returns:
+//   - consistent: true if the default app declares the scheme
+//   - appPath: Full path to the current default application bundle
+//   - error: Error if any
+func CheckSchemeHandlerConsistency(scheme string) (bool, string, error) {
+	return defaultHandler.CheckSchemeHandlerConsistency(scheme)
+}
+
+// GetAllDefaultHandlers returns the default application for every UTI with an explicit handler
+//
+// The UTIs are discovered from the user's LaunchServices handler preferences
+// (LSHandlers), i.e. every content type whose default has been chosen
+// explicitly; types that fall back to LaunchServices' own choice are not
+// included. Their current defaults are then resolved in a single batch, so the
+// cost stays linear even for users with hundreds of customized types.
+//
+// Returns:
+//   - defaults: Map from UTI to default application path
+//   - error: Error if any
+func GetAllDefaultHandlers() (map[string]string, error) {
+	return defaultHandler.GetAllDefaultHandlers()
+}
+
+// WatchDefaultChanges reports changes to the user's default handlers as they happen
+//
+// macOS does not post a public notification when a default handler changes,
+// so the LaunchServices handler preferences (LSHandlers) are re-read every
+// two seconds and compared with the previous read. Each added, removed or
+// changed UTI or URL scheme default produces one event. Only defaults stored
+// in LSHandlers are seen: a change in which app LaunchServices picks when the
+// user has not chosen one, e.g. after installing an app, is not reported.
+//
+// Returns:
+//   - events: Channel of HandlerChangeEvent, closed when stop is called
+//   - stop: Function that stops watching and closes the channel; safe to call more than once
+//   - error: Error if the handler preferences cannot be read
+func WatchDefaultChanges() (<-chan HandlerChangeEvent, func(), error) {
+	return defaultHandler.WatchDefaultChanges()
+}
+
+// DumpHandlerConfiguration returns a human-readable report of the user's default handlers
+//
+// The report lists every UTI and URL scheme default stored by LaunchServices,
+// with the handler's name, bundle ID and path, sorted so that the output is
+// stable between runs. Handlers that are no longer installed are marked as such.
+//
+// Returns:
+//   - report: Multi-line text report
+//   - error: Error if any
+func DumpHandlerConfiguration() (string, error) {
+	return defaultHandler.DumpHandlerConfiguration()
+}
+
+// SetDefaultForUTI sets the default application for a UTI
+//
+// UTIs that are not registered (see IsRegisteredUTI) are rejected up front with
+// ErrInvalidUTI. This includes dynamic (dyn.*) UTIs, which LaunchServices
+// accepts without the setting ever taking effect.
+//
+// The app must also be able to open the UTI (see AppSupportsUTI); otherwise the
+// error matches ErrAppDoesNotSupportUTI and nothing is changed. Use
+// SetDefaultForUTIForce to skip this check.
+//
+// Like every function that changes handler settings, it is serialized with the
+// package's other writes and is safe to call from multiple goroutines.
+//
+// macOS may ask the user to confirm the change. If they cancel, the error
+// matches ErrUserDeclinedError; nothing was changed and the call can be retried.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - error: ErrUserDeclinedError if the user cancelled the confirmation, or other error
+func SetDefaultForUTI(appPath, uti string) error {
+	return defaultHandler.SetDefaultForUTI(appPath, uti)
+}
+
+// SetDefaultForUTIForce sets the default application for a UTI even if the app does not declare it
+//
+// It behaves like SetDefaultForUTI without the AppSupportsUTI check; unregistered
+// UTIs are still rejected. The resulting default may be unable to open the files.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - error: ErrUserDeclinedError if the user cancelled the confirmation, or other error
+func SetDefaultForUTIForce(appPath, uti string) error {
+	return defaultHandler.SetDefaultForUTIForce(appPath, uti)
+}
+
+// SetDefaultForUTIVerified sets the default application for a UTI only if the app's code signature is valid
+//
+// The signature is checked with SecStaticCodeCheckValidity before doing what
+// SetDefaultForUTI does. The check hashes the app's executable and resources,
+// so it can take from milliseconds for small apps to seconds for large ones;
+// callers opt into that cost by choosing this function.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - error: An error matching ErrCodeSignatureInvalid if the app is unsigned or
+//     its signature does not validate, otherwise as SetDefaultForUTI
+func SetDefaultForUTIVerified(appPath, uti string) error {
+	return defaultHandler.SetDefaultForUTIVerified(appPath, uti)
+}
+
+// SetDefaultForUTIConfirmed sets the default application for a UTI and checks that the change took effect
+//
+// After SetDefaultForUTI succeeds the default is read back with
+// GetDefaultAppForUTI. On managed or sandboxed machines LaunchServices can
+// accept a change and then ignore it; this reports that case instead of
+// returning success. It is the UTI counterpart of SetDefaultForSchemeVerified;
+// SetDefaultForUTIVerified is the variant that checks the app's code signature.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - error: An error matching ErrVerificationFailed if another app is still
+//     the default, otherwise as SetDefaultForUTI
+func SetDefaultForUTIConfirmed(appPath, uti string) error {
+	return defaultHandler.SetDefaultForUTIConfirmed(appPath, uti)
+}
+
+// SetDefaultForUTIReturningPrevious sets the default application for a UTI and returns the one it replaced
+//
+// The current default is read and the new one set while holding the package's
+// write lock, so no other write from this package can slip in between.
+// Unregistered UTIs and apps that do not declare the UTI are rejected like in
+// SetDefaultForUTI. The previous default may itself not declare the UTI (it
+// can handle it through a type it conforms to), so undo the change with
+// SetDefaultForUTIForce rather than SetDefaultForUTI.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - previousAppPath: Path of the previous default application, or empty if there was none
+//   - error: Error if any
+func SetDefaultForUTIReturningPrevious(appPath, uti string) (string, error) {
+	return defaultHandler.SetDefaultForUTIReturningPrevious(appPath, uti)
+}
+
+// SetDefaultForUTIWithRole sets the default application for a UTI in a single role
+//
+// SetDefaultForUTI is equivalent to RoleAll, and RoleAll is handled by it.
+// RoleViewer, RoleEditor and RoleShell change only that role's handler, so a
+// read-only type can get a default viewer without affecting its editor.
+// RoleNone is rejected, since there is nothing to open the type with.
+// Unregistered UTIs and apps that do not declare the UTI are rejected like in
+// SetDefaultForUTI.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - uti: The Uniform Type Identifier
+//   - role: RoleViewer, RoleEditor, RoleShell or RoleAll
+//
+// Returns:
+//   - error: ErrInvalidParameters for an unsupported role, or other error
+func SetDefaultForUTIWithRole(appPath, uti string, role Role) error {
+	return defaultHandler.SetDefaultForUTIWithRole(appPath, uti, role)
+}
+
+// SetDefaultForExtension sets the default application for a file extension
+//
+// Only the extension's preferred UTI (the one the type system picks first) is
+// changed; other UTIs that happen to claim the same extension are left alone.
+// Extensions that resolve only to a dynamic UTI return an ErrNotFound error.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - extension: File extension, with or without a leading dot (e.g., "txt", ".md")
+//
+// Returns:
+//   - error: Error if any
+func SetDefaultForExtension(appPath, extension string) error {
+	return defaultHandler.SetDefaultForExtension(appPath, extension)
+}
+
+// ResetDefaultForUTI clears the user's default application override for a UTI
+//
+// The UTI's entries are removed from the LaunchServices handler preferences so
+// that LaunchServices falls back to its own choice of handler. Running
+// processes may keep seeing the previous default until LaunchServices reloads
+// its preferences. Resetting a UTI without an override is not an error.
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - error: ErrInvalidUTI BridgeError for unknown UTIs, or other error
+func ResetDefaultForUTI(uti string) error {
+	return defaultHandler.ResetDefaultForUTI(uti)
+}
+
+// SetDefaultForScheme sets the default application for a URL scheme
+//
+// Serialized with the package's other writes; safe for concurrent use.
+//
+// macOS asks the user to confirm a new web browser or mail client. If they
+// cancel, the error matches ErrUserDeclinedError.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - scheme: The URL scheme
+//
+// Returns:
+//   - error: ErrUserDeclinedError if the user cancelled the confirmation, or other error
+func SetDefaultForScheme(appPath, scheme string) error {
+	return defaultHandler.SetDefaultForScheme(appPath, scheme)
+}
+
+// SetDefaultForSchemeVerified sets the default application for a URL scheme and checks that the change took effect
+//
+// After SetDefaultForScheme succeeds the default is read back with
+// GetDefaultAppForScheme. On managed or sandboxed machines LaunchServices can
+// accept a change and then ignore it; this reports that case instead of
+// returning success.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - scheme: The URL scheme
+//
+// Returns:
+//   - error: An error matching ErrVerificationFailed if another app is still
+//     the default, otherwise as SetDefaultForScheme
+func SetDefaultForSchemeVerified(appPath, scheme string) error {
+	return defaultHandler.SetDefaultForSchemeVerified(appPath, scheme)
+}
+
+// ResetDefaultForScheme clears the user's default application override for a URL scheme
+//
+// The scheme's entries are removed from the LaunchServices handler preferences
+// so that LaunchServices falls back to its own choice of handler, the same way
+// ResetDefaultForUTI does for UTIs. Running processes may keep seeing the
+// previous default until LaunchServices reloads its preferences. Resetting a
+// scheme without an override is not an error.
+//
+// Parameters:
+//   - scheme: The URL scheme (e.g., "webcal")
+//
+// Returns:
+//   - error: Error if any
+func ResetDefaultForScheme(scheme string) error {
+	return defaultHandler.ResetDefaultForScheme(scheme)
+}
+
+// SnapshotDefaults captures the current default handlers for later restoration
+//
+// The snapshot covers every UTI and URL scheme with an explicit handler, as
+// discovered by GetAllDefaultHandlers. Entries whose default can no longer be
+// resolved are left out.
+//
+// Returns:
+//   - snapshot: HandlerSnapshot of the current UTI and scheme defaults
+//   - error: Error if any
+func SnapshotDefaults() (HandlerSnapshot, error) {
+	return defaultHandler.SnapshotDefaults()
+}
+
+// RestoreDefaults applies the default handlers recorded in a snapshot
+//
+// Every mapping is attempted even if some fail. UTIs are restored with
+// SetDefaultForUTIForce so the snapshot is reproduced as recorded. Restoring
+// scheme defaults may prompt the user for confirmation. Entries without a
+// path, as in a snapshot decoded from JSON, are looked up by bundle ID with
+// FindAppByBundleID; apps that are not installed are reported per entry.
+//
+// Parameters:
+//   - snapshot: HandlerSnapshot from SnapshotDefaults, ResolveSnapshot or json.Unmarshal
+//
+// Returns:
+//   - error: All per-entry failures joined together, or nil if every mapping was applied
+func RestoreDefaults(snapshot HandlerSnapshot) error {
+	return defaultHandler.RestoreDefaults(snapshot)
+}
+
+// ResolveSnapshot resolves the bundle IDs in a snapshot to the apps installed on this machine
+//
+// Use it after decoding a snapshot from JSON and before RestoreDefaults.
+// Entries whose app is not installed are left out of the result.
+//
+// Parameters:
+//   - snapshot: HandlerSnapshot whose entries carry bundle IDs
+//
+// Returns:
+//   - resolved: HandlerSnapshot with the current path, name and bundle ID of every installed handler
+//   - skipped: Entries that were left out, as "UTI <uti>" or "scheme <scheme>"
+//   - error: Error if any
+func ResolveSnapshot(snapshot HandlerSnapshot) (HandlerSnapshot, []string, error) {
+	return defaultHandler.ResolveSnapshot(snapshot)
+}
+
+// SetDefaultForUTIByBundleID sets the default application for a UTI, identifying the app by bundle ID
+//
+// Parameters:
+//   - bundleID: The application's bundle identifier (e.g., "com.apple.TextEdit")
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - error: ErrNotFound BridgeError if no installed application has the identifier, or other error
+func SetDefaultForUTIByBundleID(bundleID, uti string) error {
+	return defaultHandler.SetDefaultForUTIByBundleID(bundleID, uti)
+}
+
+// SetDefaultForExtensionByBundleID sets the default application for a file extension, identifying the app by bundle ID
+//
+// Parameters:
+//   - bundleID: The application's bundle identifier (e.g., "com.microsoft.VSCode")
+//   - extension: File extension, with or without a leading dot (e.g., "txt", ".md")
+//
+// Returns:
+//   - error: ErrNotFound BridgeError if no installed application has the identifier, or other error
+func SetDefaultForExtensionByBundleID(bundleID, extension string) error {
+	return defaultHandler.SetDefaultForExtensionByBundleID(bundleID, extension)
+}
+
+// SetDefaultForSchemeByBundleID sets the default application for a URL scheme, identifying the app by bundle ID
+//
+// Parameters:
+//   - bundleID: The application's bundle identifier (e.g., "org.mozilla.firefox")
+//   - scheme: The URL scheme
+//
+// Returns:
+//   - error: ErrNotFound BridgeError if no installed application has the identifier, or other error
+func SetDefaultForSchemeByBundleID(bundleID, scheme string) error {
+	return defaultHandler.SetDefaultForSchemeByBundleID(bundleID, scheme)
+}
+
+// GetDefaultBrowser returns the default web browser
+//
+// The browser is the default handler for the https scheme.
+//
+// Returns:
+//   - app: AppInfo for the default browser
+//   - error: ErrNotFound BridgeError if no browser is set, or other error
+func GetDefaultBrowser() (AppInfo, error) {
+	return defaultHandler.GetDefaultBrowser()
+}
+
+// SetDefaultBrowser makes an application the default web browser
+//
+// Like the browser setting in System Settings, it sets the http and https
+// schemes and the public.html UTI. Every one is attempted even if an earlier
+// one fails; macOS may ask the user to confirm the change.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - error: The failed schemes and UTIs joined together, or nil if all were set
+func SetDefaultBrowser(appPath string) error {
+	return defaultHandler.SetDefaultBrowser(appPath)
+}
+
+// GetDefaultMailClient returns the default mail client
+//
+// The mail client is the default handler for the mailto scheme.
+//
+// Returns:
+//   - app: AppInfo for the default mail client
+//   - error: ErrNotFound BridgeError if no mail client is set, or other error
+func GetDefaultMailClient() (AppInfo, error) {
+	return defaultHandler.GetDefaultMailClient()
+}
+
+// SetDefaultMailClient makes an application the default mail client
+//
+// It sets the mailto scheme; macOS may ask the user to confirm the change.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - error: An error matching ErrUserDeclinedError if the user cancelled the confirmation, or other error
+func SetDefaultMailClient(appPath string) error {
+	return defaultHandler.SetDefaultMailClient(appPath)
+}
+
+// GetDefaultTextEditor returns the default text editor
+//
+// The text editor is the default handler for public.plain-text.
+//
+// Returns:
+//   - app: AppInfo for the default text editor
+//   - error: ErrNotFound BridgeError if no text editor is set, or other error
+func GetDefaultTextEditor() (AppInfo, error) {
+	return defaultHandler.GetDefaultTextEditor()
+}
+
+// SetDefaultTextEditor makes an application the default text editor
+//
+// Like Finder's "Change All…" for a text file, it sets both public.plain-text
+// and public.text. Both are attempted even if the first fails.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - error: The failed UTIs joined together, or nil if both were set
+func SetDefaultTextEditor(appPath string) error {
+	return defaultHandler.SetDefaultTextEditor(appPath)
+}
+
+// GetDefaultTerminal returns the default terminal application
+//
+// macOS has no terminal setting of its own. The terminal is taken to be the
+// default handler for public.unix-executable, which is the app that runs
+// executables opened from Finder (Terminal unless the user changed it).
+//
+// Returns:
+//   - app: AppInfo for the default terminal
+//   - error: ErrNotFound BridgeError if no handler is set, or other error
+func GetDefaultTerminal() (AppInfo, error) {
+	return defaultHandler.GetDefaultTerminal()
+}
+
+// OpenFile opens a file with its default application
+//
+// Parameters:
+//   - filePath: Full path to the file to open
+//
+// Returns:
+//   - error: ErrInvalidParameters if the path is empty or does not exist,
+//     ErrNotFound BridgeError if no application can open the file, or a BridgeError
+//     whose OSStatus holds the system's status code if the launch fails
+func OpenFile(filePath string) error {
+	return defaultHandler.OpenFile(filePath)
+}
+
+// OpenFileWithApp opens a file with a specific application, regardless of the default
+//
+// Parameters:
+//   - filePath: Full path to the file to open
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - error: ErrInvalidParameters if either path is empty or the file does not exist, error if any
+func OpenFileWithApp(filePath, appPath string) error {
+	return defaultHandler.OpenFileWithApp(filePath, appPath)
+}
+
+// OpenFilesWithApp opens several files with a specific application in a single launch
+//
+// The application receives every file in one open request rather than one launch
+// per file. If any file does not exist nothing is opened.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - filePaths: Full paths to the files to open
+//
+// Returns:
+//   - error: ErrInvalidParameters if appPath or filePaths is empty or a file does not exist, error if any
+func OpenFilesWithApp(appPath string, filePaths []string) error {
+	return defaultHandler.OpenFilesWithApp(appPath, filePaths)
+}
+
+// ResolveUTIsForExtension resolves a file extension to one or more UTIs
+//
+// The extension is matched case-insensitively and a single leading dot is
+// ignored, so ".TXT", ".txt" and "txt" all resolve the same way.
+//
+// Parameters:
+//   - extension: File extension, with or without a leading dot (e.g., "txt", ".md")
+//
+// Returns:
+//   - utis: Sorted, deduplicated slice of UTI identifiers
+//   - error: ErrInvalidParameters for an empty extension, or other error
+func ResolveUTIsForExtension(extension string) ([]string, error) {
+	return defaultHandler.ResolveUTIsForExtension(extension)
+}
+
+// ResolveUTIsForExtensionDeclaredOnly resolves a file extension to its UTIs, excluding dynamic types
+//
+// Parameters:
+//   - extension: File extension, with or without a leading dot (e.g., "txt", ".md")
+//
+// Returns:
+//   - utis: Slice of declared UTI strings, empty if the extension is unregistered
+//   - error: Error if any
+func ResolveUTIsForExtensionDeclaredOnly(extension string) ([]string, error) {
+	return defaultHandler.ResolveUTIsForExtensionDeclaredOnly(extension)
+}
+
+// PreferredUTIForExtension resolves a file extension to the single UTI the system prefers for it
+//
+// This is UTType's preferred type for the extension, the same one Finder uses.
+// Declared types are preferred; a dynamic UTI (dyn.*) is returned only if no
+// declared type has the extension. Use ResolveUTIsForExtension to get every
+// UTI the extension maps to.
+//
+// Parameters:
+//   - extension: File extension, with or without a leading dot (e.g., "txt", ".md")
+//
+// Returns:
+//   - uti: The preferred UTI
+//   - error: Error if any
+func PreferredUTIForExtension(extension string) (string, error) {
+	return defaultHandler.PreferredUTIForExtension(extension)
+}
+
+// ResolveExtensionsForUTI returns all file extensions associated with a UTI
+//
+// Parameters:
+//   - uti: The UTI string (e.g., "public.plain-text", "public.html")
+//
+// Returns:
+//   - extensions: Sorted, deduplicated slice of file extensions (without dots)
+//   - error: Error if any
+func ResolveExtensionsForUTI(uti string) ([]string, error) {
+	return defaultHandler.ResolveExtensionsForUTI(uti)
+}
+
+// ResolveExtensionsForUTIs returns the file extensions of several UTIs in a single call
+//
+// UTIs that are unknown or have no file extensions are omitted from the
+// result rather than failing the whole batch.
+//
+// Parameters:
+//   - utis: Uniform Type Identifiers to look up
+//
+// Returns:
+//   - extensions: Map from UTI to its sorted, deduplicated file extensions (without dots)
+//   - error: Error if any
+func ResolveExtensionsForUTIs(utis []string) (map[string][]string, error) {
+	return defaultHandler.ResolveExtensionsForUTIs(utis)
+}
+
+// PreferredExtensionForUTI returns the canonical file extension for a UTI
+//
+// This is UTType's preferred filename extension, e.g. "jpg" for public.jpeg,
+// which is the one to use when naming a new file of that type.
+//
+// Parameters:
+//   - uti: The UTI string (e.g., "public.jpeg", "public.html")
+//
+// Returns:
+//   - extension: Preferred file extension, without a dot
+//   - error: ErrNotFound BridgeError if the UTI has no filename extension (e.g., public.folder),
+//     ErrInvalidUTI BridgeError if the UTI is unknown, or other error
+func PreferredExtensionForUTI(uti string) (string, error) {
+	return defaultHandler.PreferredExtensionForUTI(uti)
+}
+
+// GetTagSpecification returns every filename extension, MIME type, pasteboard type and OSType of a UTI
+//
+// All tag classes are read in a single call, which is cheaper than calling
+// ResolveExtensionsForUTI and ResolveMIMETypesForUTI separately when building
+// a catalog of types. Only the UTI's own tags are returned, not those of the
+// types it conforms to.
+//
+// Parameters:
+//   - uti: The UTI string (e.g., "public.jpeg")
+//
+// Returns:
+//   - spec: TagSpec with one slice per tag class; classes without tags are empty slices
+//   - error: ErrInvalidUTI BridgeError if the UTI is unknown, or other error
+func GetTagSpecification(uti string) (TagSpec, error) {
+	return defaultHandler.GetTagSpecification(uti)
+}
+
+// ResolveMIMETypesForUTI returns all MIME types associated with a UTI
+//
+// The preferred MIME type comes first. UTIs without a MIME type (or unknown
+// UTIs) return an empty slice rather than an error.
+//
+// Parameters:
+//   - uti: The UTI string (e.g., "public.html", "public.jpeg")
+//
+// Returns:
+//   - mimeTypes: Slice of MIME types (e.g., "text/html")
+//   - error: Error if any
+func ResolveMIMETypesForUTI(uti string) ([]string, error) {
+	return defaultHandler.ResolveMIMETypesForUTI(uti)
+}
+
+// ResolveUTIsForMIMEType returns the UTIs registered for a MIME type
+//
+// Parameters such as "; charset=utf-8" are stripped before the lookup, so a
+// Content-Type header value can be passed as is.
+//
+// Parameters:
+//   - mimeType: The MIME type (e.g., "text/html", "text/html; charset=utf-8")
+//
+// Returns:
+//   - utis: Slice of UTI strings, empty if nothing matches
+//   - error: Error if any
+func ResolveUTIsForMIMEType(mimeType string) ([]string, error) {
+	return defaultHandler.ResolveUTIsForMIMEType(mimeType)
+}
+
+// ResolveUTIForFile returns the UTI of a file on disk
+//
+// The file's extension is used when it maps to a declared type. Files without
+// an extension, or with one the system doesn't know, fall back to the content
+// type macOS records for the file, so downloads without an extension still
+// resolve.
+//
+// Parameters:
+//   - filePath: Full path to the file
+//
+// Returns:
+//   - uti: The file's UTI (e.g., "public.plain-text")
+//   - error: ErrInvalidParameters if the path is empty or the file does not exist,
+//     ErrNotFound BridgeError if the type cannot be determined, or other error
+func ResolveUTIForFile(filePath string) (string, error) {
+	return defaultHandler.ResolveUTIForFile(filePath)
+}
+
+// IsDynamicUTI reports whether a UTI is a dynamic (dyn.*) placeholder type
+//
+// The type system synthesizes dynamic UTIs for tags no app has declared, such
+// as an unregistered file extension. Identifiers are checked with UTType's
+// isDynamic, falling back to the "dyn." prefix for strings it does not accept.
+//
+// Parameters:
+//   - uti: The UTI to check
+//
+// Returns:
+//   - isDynamic: true if the UTI is dynamic
+func IsDynamicUTI(uti string) bool {
+	return defaultHandler.IsDynamicUTI(uti)
+}
+
+// IsRegisteredUTI reports whether a UTI is a type declared by the system or an installed application
+//
+// Unknown identifiers such as "com.example.nonexistent" and dynamic (dyn.*)
+// types are not registered.
+//
+// Parameters:
+//   - uti: The UTI to check
+//
+// Returns:
+//   - registered: true if the UTI is a declared type
+//   - error: ErrInvalidParameters for an empty UTI, or other error
+func IsRegisteredUTI(uti string) (bool, error) {
+	return defaultHandler.IsRegisteredUTI(uti)
+}
+
+// ExtensionsShareUTI reports whether two file extensions resolve to the same preferred UTI
+//
+// Each extension is resolved to its preferred declared UTI. If either extension
+// only yields a dynamic UTI (dyn.*), an ErrNotFound BridgeError is returned.
+//
+// Parameters:
+//   - extA: First file extension, with or without a leading dot (e.g., "jpg")
+//   - extB: Second file extension, with or without a leading dot (e.g., ".jpeg")
+//
+// Returns:
+//   - shared: true if both extensions map to the same UTI
+//   - error: Error if any
+func ExtensionsShareUTI(extA, extB string) (bool, error) {
+	return defaultHandler.ExtensionsShareUTI(extA, extB)
+}
+
+// ConformsTo reports whether a UTI conforms to another UTI
+//
+// Conformance is transitive and reflexive: "public.jpeg" conforms to
+// "public.image", "public.data" and itself.
+//
+// Parameters:
+//   - uti: The UTI to check (e.g., "public.jpeg")
+//   - parentUTI: The UTI to check against (e.g., "public.image")
+//
+// Returns:
+//   - conforms: true if uti conforms to parentUTI
+//   - error: ErrInvalidUTI BridgeError if either UTI is unknown, or other error
+func ConformsTo(uti, parentUTI string) (bool, error) {
+	return defaultHandler.ConformsTo(uti, parentUTI)
+}
+
+// GetConformingUTIs returns every UTI that a type conforms to
+//
+// The result is the full transitive closure of parent types (not just the
+// direct parents), sorted and excluding the type itself. For "public.jpeg" it
+// includes "public.image", "public.data" and "public.item". Root types such as
+// "public.item" return an empty slice.
+//
+// Parameters:
+//   - uti: The UTI to inspect (e.g., "public.jpeg")
+//
+// Returns:
+//   - utis: Slice of parent UTI strings
+//   - error: ErrInvalidUTI BridgeError if the UTI is unknown, or other error
+func GetConformingUTIs(uti string) ([]string, error) {
+	return defaultHandler.GetConformingUTIs(uti)
+}
+
+// GetUTIDescription returns the human-readable description of a UTI
+//
+// Types without a localized description return the identifier itself, so the
+// result is never empty.
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier (e.g., "public.plain-text")
+//
+// Returns:
+//   - description: Localized description (e.g., "Plain Text Document")
+//   - error: ErrInvalidUTI BridgeError if the UTI is unknown, or other error
+func GetUTIDescription(uti string) (string, error) {
+	return defaultHandler.GetUTIDescription(uti)
+}
+
+// GetSchemeDescription returns a human-readable name for a URL scheme
+//
+// macOS provides no description for URL schemes, so well-known schemes are
+// named from a built-in table (e.g., "mailto" is "Email", "http" and "https"
+// are "Web Page"). Any other scheme is returned unchanged. The table is
+// listed in the README.
+//
+// Parameters:
+//   - scheme: The URL scheme, with or without the trailing colon (e.g., "mailto")
+//
+// Returns:
+//   - description: Name of the scheme, or the scheme itself if it is not well known
+//   - error: Error if any
+func GetSchemeDescription(scheme string) (string, error) {
+	return defaultHandler.GetSchemeDescription(scheme)
+}
+
+// GetUTIIconPNG renders the generic document icon for a UTI as PNG data
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier (e.g., "public.jpeg")
+//   - size: Width and height of the icon in pixels; values <= 0 use 64
+//
+// Returns:
+//   - png: PNG-encoded icon bytes
+//   - error: ErrInvalidUTI BridgeError if the UTI is unknown, ErrNotFound BridgeError if no icon is available, or other error
+func GetUTIIconPNG(uti string, size int) ([]byte, error) {
+	return defaultHandler.GetUTIIconPNG(uti, size)
+}
+
+// ListAllRegisteredUTIs returns every UTI registered by installed applications
+//
+// This includes the UTIs apps claim in their document types as well as the
+// ones they export or import. The result is deduplicated and sorted.
+//
+// Returns:
+//   - utis: Slice of UTI strings
+//   - error: Error if any
+func ListAllRegisteredUTIs() ([]string, error) {
+	return defaultHandler.ListAllRegisteredUTIs()
+}
+
+// ListAllRegisteredUTIsFunc calls fn for every UTI registered by installed applications
+//
+// The full set of UTIs is still collected into a C array first, exactly as for
+// ListAllRegisteredUTIs; only the iteration is callback-driven. UTIs are
+// visited in sorted order and converted to Go strings one at a time, so no Go
+// slice of the whole set is built. Iteration stops early when fn returns false.
+//
+// Parameters:
+//   - fn: Callback invoked per UTI; return false to stop iterating
+//
+// Returns:
+//   - error: Error if any
+func ListAllRegisteredUTIsFunc(fn func(uti string) bool) error {
+	return defaultHandler.ListAllRegisteredUTIsFunc(fn)
+}
+
+// ListAllSchemes returns every URL scheme declared by an installed application
+//
+// This is the union of each app's CFBundleURLTypes, as reported by
+// ListSupportedSchemes for every app from ListAllApplications. A declared
+// scheme is not necessarily active: the app may never have been launched or
+// may not be its default handler. Apps whose Info.plist cannot be read are
+// skipped.
+//
+// Returns:
+//   - schemes: Sorted, deduplicated slice of lowercase scheme names
+//   - error: Error if the applications cannot be listed
+func ListAllSchemes() ([]string, error) {
+	return defaultHandler.ListAllSchemes()
+}
+
+// ListAllDeclaredUTIs returns every UTI an installed application declares it can open
+//
+// This is the union of the LSItemContentTypes in each app's
+// CFBundleDocumentTypes, covering system types such as public.plain-text as
+// well as third-party ones. Unlike ListAllRegisteredUTIs, types an app only
+// exports or imports without opening them are left out. The set is built,
+// deduplicated and sorted in a single call into macOS.
+//
+// Returns:
+//   - utis: Sorted, deduplicated slice of UTI strings
+//   - error: Error if any
+func ListAllDeclaredUTIs() ([]string, error) {
+	return defaultHandler.ListAllDeclaredUTIs()
+}
+
+// ValidateAppBundle checks that a path points to a loadable application bundle
+//
+// A valid bundle is a directory with a readable Info.plist, an APPL package
+// type (or .app extension when none is declared) and an executable.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - error: ErrInvalidApp BridgeError describing the problem, or nil if the bundle is valid
+func ValidateAppBundle(appPath string) error {
+	return defaultHandler.ValidateAppBundle(appPath)
+}
+
+// ListAppsForUTI returns all applications that can open a UTI
+//
+// The paths are sorted and deduplicated, so the result does not depend on the
+// order LaunchServices happens to return them in. Use GetDefaultAppForUTI or
+// ListAppsForUTIWithRoles to find the preferred handler. This is the same list
+// as ListAppsForUTIWithRole with RoleAll.
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - appPaths: Sorted, deduplicated slice of application bundle paths
+//   - error: Error if any
+func ListAppsForUTI(uti string) ([]string, error) {
+	return defaultHandler.ListAppsForUTI(uti)
+}
+
+// ListAppInfosForUTI returns all applications that can open a UTI along with their names and bundle IDs
+//
+// Unlike ListAppsForUTI, the apps keep LaunchServices' ranking order, with
+// repeated paths dropped. The order reflects LaunchServices' own preference
+// but is not guaranteed to start with the default handler; use
+// GetDefaultAppForUTI for that.
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - apps: Slice of AppInfo structures, empty if no app can open the UTI
+//   - error: Error if any
+func ListAppInfosForUTI(uti string) ([]AppInfo, error) {
+	return defaultHandler.ListAppInfosForUTI(uti)
+}
+
+// ListAppsForUTIWithRoles returns the applications that can open a UTI with the rank and role each declares for it
+//
+// The rank and role come from the document type in the app's Info.plist that
+// lists the UTI, or failing that a UTI it conforms to. The current default
+// handler is listed first, followed by the others ordered by rank (Owner,
+// Default, unspecified, Alternate, None). Apps with the same rank keep the
+// LaunchServices order from ListAppInfosForUTI. Finder's own "Open With"
+// ordering is not public, so this approximates it rather than matching it.
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - apps: Slice of RankedApp structures, empty if no app can open the UTI
+//   - error: Error if any
+func ListAppsForUTIWithRoles(uti string) ([]RankedApp, error) {
+	return defaultHandler.ListAppsForUTIWithRoles(uti)
+}
+
+// FindConflictingHandlers returns the apps that share the strongest handler rank claimed for a UTI
+//
+// Every app declaring rank Owner is returned, or every app declaring Default if
+// none claims Owner. Two or more apps means they compete for the type and the
+// effective default is unpredictable.
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - apps: The competing applications sorted by path; fewer than two means no conflict
+//   - error: Error if any
+func FindConflictingHandlers(uti string) ([]AppInfo, error) {
+	return defaultHandler.FindConflictingHandlers(uti)
+}
+
+// ListAppsForUTIWithRole returns the applications that can open a UTI in a given role
+//
+// RoleAll returns the same list as ListAppsForUTI. The other roles use the
+// handlers LaunchServices has registered for exactly that role, so RoleEditor
+// gives "apps that can edit this file".
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier
+//   - role: RoleViewer, RoleEditor, RoleShell, RoleAll or RoleNone
+//
+// Returns:
+//   - appPaths: Sorted, deduplicated slice of application bundle paths, empty if no app handles the UTI in that role
+//   - error: ErrInvalidParameters for an unknown role, or other error
+func ListAppsForUTIWithRole(uti string, role Role) ([]string, error) {
+	return defaultHandler.ListAppsForUTIWithRole(uti, role)
+}
+
+// ListAppsForUTIContext is like ListAppsForUTI but returns ctx.Err() once ctx is done
+//
+// The LaunchServices query cannot be interrupted; after an early return it
+// finishes in the background and its memory is still freed.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation and deadline
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - appPaths: Slice of application paths
+//   - error: ctx.Err() if the context ends first, otherwise as ListAppsForUTI
+func ListAppsForUTIContext(ctx context.Context, uti string) ([]string, error) {
+	return defaultHandler.ListAppsForUTIContext(ctx, uti)
+}
+
+// ListAppsForUTIs returns the applications that can open each of several UTIs in a single call
+//
+// Each UTI maps to the same sorted, deduplicated list ListAppsForUTI would
+// return. UTIs that are unknown or have no applications are omitted from the
+// result rather than failing the whole batch.
+//
+// Parameters:
+//   - utis: Uniform Type Identifiers to look up
+//
+// Returns:
+//   - appPaths: Map from UTI to the application bundle paths that can open it
+//   - error: Error if any
+func ListAppsForUTIs(utis []string) (map[string][]string, error) {
+	return defaultHandler.ListAppsForUTIs(utis)
+}
+
+// AppsHandlingAllUTIs returns the applications that can open every one of several UTIs
+//
+// Use it to find the apps that can open a mixed selection of files as a
+// whole. An unknown UTI matches no application, so the result is empty.
+//
+// Parameters:
+//   - utis: Uniform Type Identifiers the applications must all handle
+//
+// Returns:
+//   - appPaths: Sorted slice of application bundle paths, empty if utis is empty
+//   - error: Error if any
+func AppsHandlingAllUTIs(utis []string) ([]string, error) {
+	return defaultHandler.AppsHandlingAllUTIs(utis)
+}
+
+// AppsHandlingAnyUTI returns the applications that can open at least one of several UTIs
+//
+// Parameters:
+//   - utis: Uniform Type Identifiers to look up
+//
+// Returns:
+//   - appPaths: Sorted, deduplicated slice of application bundle paths
+//   - error: Error if any
+func AppsHandlingAnyUTI(utis []string) ([]string, error) {
+	return defaultHandler.AppsHandlingAnyUTI(utis)
+}
+
+// ListAppsForScheme returns all applications that can handle a URL scheme
+//
+// The paths are sorted and deduplicated, as for ListAppsForUTI. Use
+// GetDefaultAppForScheme to find the preferred handler.
+//
+// Parameters:
+//   - scheme: The URL scheme
+//
+// Returns:
+//   - appPaths: Sorted, deduplicated slice of application bundle paths
+//   - error: Error if any
+func ListAppsForScheme(scheme string) ([]string, error) {
+	return defaultHandler.ListAppsForScheme(scheme)
+}
+
+// GetAllHandlersForUTIByRole returns the applications that can handle a UTI, grouped by role
+//
+// The result is keyed by role:
+//   - "Editor": apps registered to edit the type
+//   - "Viewer": apps registered to view the type
+//   - "All": every app that can open the type (same set as ListAppsForUTI)
+//
+// All three groups are gathered in a single call into the bridge.
+//
+// Parameters:
+//   - uti: The Uniform Type Identifier
+//
+// Returns:
+//   - handlers: Map from role to the applications registered for it
+//   - error: Error if any
+func GetAllHandlersForUTIByRole(uti string) (map[string][]AppInfo, error) {
+	return defaultHandler.GetAllHandlersForUTIByRole(uti)
+}
+
+// ListAllApplications returns all installed applications on the system
+//
+// Returns:
+//   - apps: Slice of AppInfo structures containing app metadata
+//   - error: Error if any
+func ListAllApplications() ([]AppInfo, error) {
+	return defaultHandler.ListAllApplications()
+}
+
+// ForEachApplication calls fn for every installed application
+//
+// The system is scanned in full and every application is collected into a C
+// array first, exactly as for ListAllApplications; only the iteration is
+// callback-driven. Each AppInfo is converted to Go when it is passed to fn, so
+// no Go slice of all applications is built. Iteration stops early when fn
+// returns false, which saves the remaining conversions but not the scan.
+//
+// Parameters:
+//   - fn: Callback invoked per application; return false to stop iterating
+//
+// Returns:
+//   - error: Error if the scan fails
+func ForEachApplication(fn func(app AppInfo) bool) error {
+	return defaultHandler.ForEachApplication(fn)
+}
+
+// ListAllApplicationsContext is like ListAllApplications but returns ctx.Err() once ctx is done
+//
+// The scan cannot be interrupted; after an early return it finishes in the
+// background and its memory is still freed.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation and deadline
+//
+// Returns:
+//   - apps: Slice of AppInfo structures containing app metadata
+//   - error: ctx.Err() if the context ends first, otherwise as ListAllApplications
+func ListAllApplicationsContext(ctx context.Context) ([]AppInfo, error) {
+	return defaultHandler.ListAllApplicationsContext(ctx)
+}
+
+// ListAllApplicationsDeduplicated is like ListAllApplications but returns each bundle ID only once
+//
+// When the same bundle ID is installed more than once, the copy in the most
+// canonical location wins: /Applications, then /System/Applications, then
+// anywhere else. Within the same location the highest Version wins, and the
+// lexically smallest path breaks any remaining tie. Apps without a bundle ID
+// are all kept.
+//
+// Returns:
+//   - apps: Slice of AppInfo structures with one entry per bundle ID
+//   - error: Error if any
+func ListAllApplicationsDeduplicated() ([]AppInfo, error) {
+	return defaultHandler.ListAllApplicationsDeduplicated()
+}
+
+// ListApplicationsInDirectory returns the applications directly inside a directory
+//
+// Only the directory itself is scanned; apps in subdirectories (for example
+// /Applications/Utilities) are not included. Items named *.app that are not
+// valid application bundles are skipped.
+//
+// Parameters:
+//   - dir: Directory to scan (e.g., "/Applications")
+//
+// Returns:
+//   - apps: Slice of AppInfo structures sorted by file name, empty if the directory contains no apps
+//   - error: ErrInvalidParameters if dir is empty or not a directory, or other error
+func ListApplicationsInDirectory(dir string) ([]AppInfo, error) {
+	return defaultHandler.ListApplicationsInDirectory(dir)
+}
+
+// RebuildLaunchServicesDatabase registers every application in the standard directories with LaunchServices
+//
+// Apps directly in /Applications, /System/Applications and ~/Applications, or
+// in one level of folders below them such as Utilities, are registered with
+// LSRegisterURL; symlinked bundles are followed. This picks up
+// handlers of apps installed by scripts that LaunchServices has not noticed yet.
+// It can take a while on machines with many apps. Queries made after it
+// returns reflect the newly registered apps; a Cache should be invalidated.
+//
+// Entries are only added or refreshed: apps that were deleted or moved keep
+// their stale registrations. Removing those needs a full reset with
+// "lsregister -kill -r -domain local -domain system -domain user", which this
+// package does not run because it also discards the user's chosen defaults.
+//
+// Each registration is serialized with the package's other writes; the lock
+// is released between apps, so other writes can interleave with the walk.
+//
+// Returns:
+//   - error: The apps or directories that failed, joined together, or nil if all were registered
+func RebuildLaunchServicesDatabase() error {
+	return defaultHandler.RebuildLaunchServicesDatabase()
+}
+
+// WatchApplicationChanges reports applications being installed or removed
+//
+// The standard application directories (/Applications, /System/Applications
+// and ~/Applications) are rescanned every two seconds and compared with the
+// previous scan, the same way WatchDefaultChanges polls the handler
+// preferences. A scan lists the apps directly in those directories and in one
+// level of folders below them, such as Utilities, following symlinks; it does
+// not look inside bundles and skips folders it cannot read. Polling avoids
+// running a CFRunLoop thread for FSEvents at the cost of up to two seconds of
+// latency. Moving or renaming an app produces a removed and an added event.
+// Apps deeper in a folder tree or outside these directories are not watched.
+//
+// Pair it with Cache.Invalidate to keep a cached application list current.
+//
+// Returns:
+//   - events: Channel of AppChangeEvent, closed when stop is called
+//   - stop: Function that stops watching and closes the channel; safe to call more than once
+//   - error: Error if the application directories cannot be scanned
+func WatchApplicationChanges() (<-chan AppChangeEvent, func(), error) {
+	return defaultHandler.WatchApplicationChanges()
+}
+
+// RegisterApp registers a single application bundle with LaunchServices
+//
+// The app's declared document types and URL schemes become queryable right
+// away, which is much faster than RebuildLaunchServicesDatabase when only one
+// app changed. Registering an app that is already known refreshes its entry.
+//
+// Serialized with the package's other writes.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - error: ErrInvalidApp BridgeError if the path is not a valid app bundle, or other error
+func RegisterApp(appPath string) error {
+	return defaultHandler.RegisterApp(appPath)
+}
+
+// FindAppsByName returns the installed applications whose display name contains a query
+//
+// Matching is a case-insensitive substring match, suitable for an "Open With"
+// search box.
+//
+// Parameters:
+//   - query: Text to search for (e.g., "code")
+//
+// Returns:
+//   - apps: Slice of matching AppInfo structures, empty if nothing matches
+//   - error: Error if any
+func FindAppsByName(query string) ([]AppInfo, error) {
+	return defaultHandler.FindAppsByName(query)
+}
+
+// ListApplicationsByCategory returns the installed applications in an App Store category
+//
+// The category is compared exactly with each app's LSApplicationCategoryType,
+// so pass the full identifier.
+//
+// Parameters:
+//   - category: Category UTI (e.g., "public.app-category.developer-tools")
+//
+// Returns:
+//   - apps: Slice of AppInfo structures in the category, empty if there are none
+//   - error: ErrInvalidParameters for an empty category, or other error
+func ListApplicationsByCategory(category string) ([]AppInfo, error) {
+	return defaultHandler.ListApplicationsByCategory(category)
+}
+
+// FindAppByBundleID finds an installed application by its bundle identifier
+//
+// Parameters:
+//   - bundleID: The bundle identifier (e.g., "com.apple.Safari")
+//
+// Returns:
+//   - app: AppInfo with the resolved path and display name
+//   - error: ErrNotFound BridgeError if no installed application has the identifier, or other error
+func FindAppByBundleID(bundleID string) (AppInfo, error) {
+	return defaultHandler.FindAppByBundleID(bundleID)
+}
+
+// GetAppInfoForPID returns the application bundle that owns a running process
+//
+// Only processes LaunchServices knows as applications have a bundle; command
+// line tools, daemons and helper processes outside a bundle do not.
+//
+// Parameters:
+//   - pid: The process identifier
+//
+// Returns:
+//   - app: AppInfo of the owning application bundle
+//   - error: ErrNotFound BridgeError if no application bundle owns the process, or other error
+func GetAppInfoForPID(pid int) (AppInfo, error) {
+	return defaultHandler.GetAppInfoForPID(pid)
+}
+
+// GetFrontmostApp returns the application the user is currently working in
+//
+// This is the frontmost application, which receives key events. It is found
+// by checking which running application is active. NSWorkspace refreshes its
+// list of running applications from the main run loop, which Go programs
+// usually do not run, so an app launched after the process started may be
+// missed and the answer can lag behind the screen. Do not cache the result.
+//
+// Returns:
+//   - app: AppInfo of the frontmost application
+//   - error: ErrNotFound BridgeError if there is no frontmost application (e.g., no GUI session), or other error
+func GetFrontmostApp() (AppInfo, error) {
+	return defaultHandler.GetFrontmostApp()
+}
+
+// IsAppRunning reports whether an application is running
+//
+// Apps are matched by bundle identifier, the stable identity of a running
+// app, so any copy of the app counts regardless of where it is installed.
+//
+// Parameters:
+//   - bundleID: The bundle identifier (e.g., "com.apple.Safari")
+//
+// Returns:
+//   - running: true if at least one instance is running, false if none is
+//   - error: Error if any
+func IsAppRunning(bundleID string) (bool, error) {
+	return defaultHandler.IsAppRunning(bundleID)
+}
+
+// ActivateApp brings a running application to the foreground
+//
+// All of the app's windows are brought forward. Combined with IsAppRunning
+// and OpenFileWithApp this implements "focus the app if it is open, otherwise
+// launch it". macOS may decline to activate an app while the user is busy in
+// another one, in which case an ErrSystem BridgeError is returned.
+//
+// Parameters:
+//   - bundleID: The bundle identifier (e.g., "com.apple.Safari")
+//
+// Returns:
+//   - error: ErrNotFound BridgeError if the app is not running, or other error
+func ActivateApp(bundleID string) error {
+	return defaultHandler.ActivateApp(bundleID)
+}
+
+// QuitApp asks every running instance of an application to quit
+//
+// Without force the app is asked to quit the way the Quit menu item does: it
+// may prompt the user to save documents, and the user or the app can cancel.
+// A nil error therefore means the request was delivered, not that the app has
+// exited; poll IsAppRunning to find out. With force the processes are killed
+// at once and unsaved changes are lost.
+//
+// Parameters:
+//   - bundleID: The bundle identifier (e.g., "com.apple.Safari")
+//   - force: true to terminate immediately, false to ask the app to quit
+//
+// Returns:
+//   - error: ErrNotFound BridgeError if the app is not running, ErrSystem
+//     BridgeError if an instance did not accept the request, or other error
+func QuitApp(bundleID string, force bool) error {
+	return defaultHandler.QuitApp(bundleID, force)
+}
+
+// GetBundleID returns the bundle identifier of the application at a path
+//
+// Different paths to the same app (symlinks, aliases, /System/Applications vs
+// /Applications) all yield the same identifier, making it a stable key.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - bundleID: The CFBundleIdentifier (e.g., "com.apple.TextEdit")
+//   - error: ErrInvalidApp BridgeError if the path is not a valid app bundle,
+//     ErrNotFound BridgeError if the bundle declares no identifier, or other error
+func GetBundleID(appPath string) (string, error) {
+	return defaultHandler.GetBundleID(appPath)
+}
+
+// GetBundleInfoValue reads a top-level value from an application's Info.plist
+//
+// Use it for one-off keys such as LSMinimumSystemVersion or
+// NSHumanReadableCopyright. Localized values take precedence. Values that are
+// not strings are returned as their Foundation description, so a number or
+// boolean comes back as its digits ("1" for true) and arrays and dictionaries
+// in property list text form.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - key: The Info.plist key
+//
+// Returns:
+//   - value: The value as a string
+//   - error: ErrNotFound BridgeError if the key is absent,
+//     ErrInvalidApp BridgeError if the path is not a valid app bundle, or other error
+func GetBundleInfoValue(appPath, key string) (string, error) {
+	return defaultHandler.GetBundleInfoValue(appPath, key)
+}
+
+// GetAppCategory returns an application's App Store category
+//
+// Many apps from outside the App Store do not declare a category; that is not
+// an error and yields an empty string. The same value is available as
+// AppInfo.Category.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - category: The LSApplicationCategoryType (e.g., "public.app-category.developer-tools"), or empty
+//   - error: ErrInvalidApp BridgeError if the path is not a valid app bundle, or other error
+func GetAppCategory(appPath string) (string, error) {
+	return defaultHandler.GetAppCategory(appPath)
+}
+
+// IsSandboxed reports whether an application runs in the App Sandbox
+//
+// The com.apple.security.app-sandbox entitlement is read from the bundle's code
+// signature. Unsigned apps are reported as not sandboxed.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - sandboxed: true if the app is signed with the App Sandbox entitlement
+//   - error: ErrInvalidApp BridgeError if the path is not a valid app bundle, or other error
+func IsSandboxed(appPath string) (bool, error) {
+	return defaultHandler.IsSandboxed(appPath)
+}
+
+// GetCodeSigningTeamID returns the Team Identifier from an application's code signature
+//
+// The Team ID identifies the developer account that signed the app, which makes
+// it suitable for "only trusted developers may become defaults" policies.
+// Apple's own apps are signed without a team and return an empty string, as
+// do unsigned and ad-hoc signed apps.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - teamID: The team identifier (e.g., "43AQ936H96"), or empty if the app has none
+//   - error: ErrInvalidApp BridgeError if the path is not a valid app bundle, or other error
+func GetCodeSigningTeamID(appPath string) (string, error) {
+	return defaultHandler.GetCodeSigningTeamID(appPath)
+}
+
+// GetAppSummary returns an application's metadata along with counts of the document types it handles
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - summary: AppSummary for the application
+//   - error: ErrInvalidApp BridgeError if the path is not a valid app bundle, or other error
+func GetAppSummary(appPath string) (AppSummary, error) {
+	return defaultHandler.GetAppSummary(appPath)
+}
+
+// GetAppProfile returns everything the bridge knows about what an application handles
+//
+// The app path is resolved once and the supported document types and schemes
+// are read once; the defaults are derived from them as ListDefaultDocumentTypes
+// and ListDefaultSchemes would.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - profile: AppProfile for the application
+//   - error: ErrInvalidApp BridgeError if the path is not a valid app bundle, or other error
+func GetAppProfile(appPath string) (AppProfile, error) {
+	return defaultHandler.GetAppProfile(appPath)
+}
+
+// GetAppIconPNG renders an application's icon as PNG data
+//
+// Icons are comparatively expensive to render, so they are loaded on demand
+// rather than as part of AppInfo. An app that declares no icon of its own gets
+// the generic application icon, as in Finder.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - size: Width and height of the icon in pixels; values <= 0 use 64
+//
+// Returns:
+//   - png: PNG-encoded icon bytes
+//   - error: ErrInvalidApp BridgeError if the path is not a valid app bundle, or other error
+func GetAppIconPNG(appPath string, size int) ([]byte, error) {
+	return defaultHandler.GetAppIconPNG(appPath, size)
+}
+
+// ListSupportedDocumentTypes returns all document types that an application can handle
+//
+// This returns what the app CLAIMS it can handle, not what it's the default for.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - opts: Optional settings (e.g., WithDerived)
+//
+// Returns:
+//   - docTypes: Slice of DocumentType structures containing detailed info about supported file types
+//   - error: Error if any
+func ListSupportedDocumentTypes(appPath string, opts ...DocumentTypeOption) ([]DocumentType, error) {
+	return defaultHandler.ListSupportedDocumentTypes(appPath, opts...)
+}
+
+// ListSupportedDocumentTypesByRole returns the document types an application declares with a given role
+//
+// The role is matched against each type's CFBundleTypeRole, so RoleEditor gives
+// the types the app can edit. RoleAll returns every type, including those that
+// declare no role.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - role: RoleEditor, RoleViewer, RoleShell, RoleNone or RoleAll
+//
+// Returns:
+//   - docTypes: Slice of DocumentType structures with that role, empty if there are none
+//   - error: ErrInvalidParameters for an unknown role, or other error
+func ListSupportedDocumentTypesByRole(appPath string, role Role) ([]DocumentType, error) {
+	return defaultHandler.ListSupportedDocumentTypesByRole(appPath, role)
+}
+
+// GetDocumentTypeForUTI returns the document type an application declares for a UTI
+//
+// Only UTIs listed in the app's CFBundleDocumentTypes match; conformance is not
+// considered. The returned entry carries the role and handler rank for this
+// app and type.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - uti: Uniform Type Identifier (e.g., "public.plain-text")
+//
+// Returns:
+//   - docType: The DocumentType entry whose UTIs contain uti
+//   - error: ErrNotFound BridgeError if the app does not declare uti, or other error
+func GetDocumentTypeForUTI(appPath, uti string) (DocumentType, error) {
+	return defaultHandler.GetDocumentTypeForUTI(appPath, uti)
+}
+
+// AppSupportsUTI reports whether an application can handle a UTI
+//
+// Conformance is considered: an app declaring public.image supports
+// public.jpeg. Apps that only declare wildcard types such as public.data,
+// which ListSupportedDocumentTypes leaves out, are found through
+// LaunchServices' list of apps for the UTI. To ask whether the app declares
+// exactly this UTI, use GetDocumentTypeForUTI instead.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//   - uti: Uniform Type Identifier (e.g., "public.jpeg")
+//
+// Returns:
+//   - supported: true if the app declares uti or a UTI it conforms to
+//   - error: Error if the app's document types cannot be read
+func AppSupportsUTI(appPath, uti string) (bool, error) {
+	return defaultHandler.AppSupportsUTI(appPath, uti)
+}
+
+// ListSupportedSchemes returns the URL schemes an application declares in CFBundleURLTypes
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - schemes: Slice of URL schemes (e.g., "http", "https"), empty if the app registers none
+//   - error: ErrInvalidApp BridgeError if the path is not an app bundle, or other error
+func ListSupportedSchemes(appPath string) ([]string, error) {
+	return defaultHandler.ListSupportedSchemes(appPath)
+}
+
+// ListExportedUTIs returns the UTIs an application defines in UTExportedTypeDeclarations
+//
+// These are the custom types the app owns, as opposed to the ones it merely
+// understands (see ListImportedUTIs).
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - utis: Sorted slice of UTIs, empty if the app exports none
+//   - error: ErrInvalidApp BridgeError if the path is not an app bundle, or other error
+func ListExportedUTIs(appPath string) ([]string, error) {
+	return defaultHandler.ListExportedUTIs(appPath)
+}
+
+// ListImportedUTIs returns the UTIs an application declares in UTImportedTypeDeclarations
+//
+// These are types defined elsewhere that the app describes so it can open them
+// even when their owner is not installed.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - utis: Sorted slice of UTIs, empty if the app imports none
+//   - error: ErrInvalidApp BridgeError if the path is not an app bundle, or other error
+func ListImportedUTIs(appPath string) ([]string, error) {
+	return defaultHandler.ListImportedUTIs(appPath)
+}
+
+// ListDefaultSchemes returns the URL schemes for which the given application is the system default
+//
+// This checks which schemes the app declares AND is actually set as the default handler for.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - schemes: Slice of URL schemes where this app is the default, empty if there are none
+//   - error: Error if any
+func ListDefaultSchemes(appPath string) ([]string, error) {
+	return defaultHandler.ListDefaultSchemes(appPath)
+}
+
+// ListDefaultDocumentTypes returns all document types where the given application is the system default
+//
+// This function checks which document types the app supports AND is actually set as the default handler for.
+// The defaults are looked up in one batch and the extensions of the matching
+// types are resolved concurrently; the result keeps ListSupportedDocumentTypes' order.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - docTypes: Slice of DocumentType structures where this app is the system default
+//   - error: Error if any
+func ListDefaultDocumentTypes(appPath string) ([]DocumentType, error) {
+	return defaultHandler.ListDefaultDocumentTypes(appPath)
+}
+
+// GroupSupportedDocumentTypesByFamily groups the document types an application can handle by family
+//
+// A UTI may conform to several families (e.g. SVG is both an image and text).
+// Each document type is placed in exactly one family, chosen by checking its
+// UTIs against the families in priority order:
+// audiovisual > image > text > data. Types matching none of them are grouped
+// under FamilyOther.
+//
+// Parameters:
+//   - appPath: Full path to the application bundle (Finder aliases and symlinks are resolved)
+//
+// Returns:
+//   - groups: Map from family to the document types in that family
+//   - error: Error if any
+func GroupSupportedDocumentTypesByFamily(appPath string) (map[TypeFamily][]DocumentType, error) {
+	return defaultHandler.GroupSupportedDocumentTypesByFamily(appPath)
+}
@@ -0,0 +1,63 @@
+package bridge
+
+import "testing"
+
+// fakeHandler overrides a single Handler method for delegation tests
+type fakeHandler struct {
+	Handler
+	defaultApp string
+}
+
+func (f *fakeHandler) GetDefaultAppForUTI(uti string) (string, error) {
+	return f.defaultApp, nil
+}
+
+// TestPackageFunctionsDelegateToDefaultHandler tests that package-level functions call the default Handler
+func TestPackageFunctionsDelegateToDefaultHandler(t *testing.T) {
+	original := defaultHandler
+	defer func() { defaultHandler = original }()
+
+	defaultHandler = &fakeHandler{defaultApp: "/Applications/Fake.app"}
+
+	got, err := GetDefaultAppForUTI("public.plain-text")
+	if err != nil {
+		t.Fatalf("GetDefaultAppForUTI() error = %v", err)
+	}
+	if got != "/Applications/Fake.app" {
+		t.Errorf("GetDefaultAppForUTI() = %q, want %q", got, "/Applications/Fake.app")
+	}
+}
+
+// TestNewHandler tests that NewHandler returns a usable Handler
+func TestNewHandler(t *testing.T) {
+	var h Handler = NewHandler()
+	if h == nil {
+		t.Fatal("NewHandler() returned nil")
+	}
+}
+
+// fakeDefaultsReader implements only DefaultsReader, the way a consumer's test double would
+type fakeDefaultsReader struct {
+	DefaultsReader
+	defaultApp string
+}
+
+func (f fakeDefaultsReader) GetDefaultAppForUTI(uti string) (string, error) {
+	return f.defaultApp, nil
+}
+
+// TestRoleInterfaces tests that the role interfaces can be used on their own
+func TestRoleInterfaces(t *testing.T) {
+	h := NewHandler()
+	roles := []any{DefaultsReader(h), DefaultsWriter(h), FileOpener(h), TypeResolver(h), AppManager(h)}
+	for i, role := range roles {
+		if role == nil {
+			t.Errorf("role %d of NewHandler() is nil", i)
+		}
+	}
+
+	var reader DefaultsReader = fakeDefaultsReader{defaultApp: "/Applications/Fake.app"}
+	if got, _ := reader.GetDefaultAppForUTI("public.plain-text"); got != "/Applications/Fake.app" {
+		t.Errorf("fake DefaultsReader GetDefaultAppForUTI() = %q, want %q", got, "/Applications/Fake.app")
+	}
+}
@@ -0,0 +1,883 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// hasErrorCode reports whether err is a BridgeError with the given code
+func hasErrorCode(err error, code int) bool {
+	var bridgeErr *BridgeError
+	return errors.As(err, &bridgeErr) && bridgeErr.Code == code
+}
+
+// normalizeExtension strips a single leading dot and lowercases a file extension,
+// so filepath.Ext output can be passed in directly
+func normalizeExtension(extension string) string {
+	return strings.ToLower(strings.TrimPrefix(extension, "."))
+}
+
+// sortedUnique sorts a slice of strings in place and drops duplicates
+func sortedUnique(values []string) []string {
+	sort.Strings(values)
+
+	unique := values[:0]
+	for i, value := range values {
+		if i > 0 && value == values[i-1] {
+			continue
+		}
+		unique = append(unique, value)
+	}
+
+	return unique
+}
+
+// sortedUniqueApps sorts applications by path in place and drops repeated paths
+func sortedUniqueApps(apps []AppInfo) []AppInfo {
+	sort.SliceStable(apps, func(i, j int) bool {
+		return apps[i].Path < apps[j].Path
+	})
+
+	unique := apps[:0]
+	for i, app := range apps {
+		if i > 0 && app.Path == apps[i-1].Path {
+			continue
+		}
+		unique = append(unique, app)
+	}
+
+	return unique
+}
+
+// uniqueInOrder drops repeated strings, keeping the first occurrence and the original order
+func uniqueInOrder(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+
+	return unique
+}
+
+// uniqueAppsInOrder drops applications with a repeated path, keeping the first occurrence and the original order
+func uniqueAppsInOrder(apps []AppInfo) []AppInfo {
+	seen := make(map[string]bool, len(apps))
+	unique := make([]AppInfo, 0, len(apps))
+	for _, app := range apps {
+		if seen[app.Path] {
+			continue
+		}
+		seen[app.Path] = true
+		unique = append(unique, app)
+	}
+
+	return unique
+}
+
+// appNameFromPath derives a display name from a bundle path, for apps that declare none
+func appNameFromPath(appPath string) string {
+	if appPath == "" {
+		return ""
+	}
+	return strings.TrimSuffix(filepath.Base(appPath), ".app")
+}
+
+// appPathsEqual compares two app paths, handling symlinks and case-insensitive filesystems
+func appPathsEqual(path1, path2 string) bool {
+	clean1 := filepath.Clean(path1)
+	clean2 := filepath.Clean(path2)
+
+	if clean1 == clean2 {
+		return true
+	}
+
+	real1, err1 := filepath.EvalSymlinks(clean1)
+	real2, err2 := filepath.EvalSymlinks(clean2)
+
+	if err1 == nil && err2 == nil {
+		return real1 == real2
+	}
+
+	// Case-insensitive match (macOS filesystems are often case-insensitive)
+	return strings.EqualFold(clean1, clean2)
+}
+
+// appPathMatcher compares many paths against one app path, resolving the app's
+// symlinks once and remembering the result for each path it has seen
+//
+// It gives the same answers as appPathsEqual but is not safe for concurrent use.
+type appPathMatcher struct {
+	clean    string
+	real     string
+	resolved bool
+	seen     map[string]bool
+}
+
+// newAppPathMatcher returns an appPathMatcher for appPath
+func newAppPathMatcher(appPath string) *appPathMatcher {
+	m := &appPathMatcher{
+		clean: filepath.Clean(appPath),
+		seen:  make(map[string]bool),
+	}
+
+	if real, err := filepath.EvalSymlinks(m.clean); err == nil {
+		m.real = real
+		m.resolved = true
+	}
+
+	return m
+}
+
+// matches reports whether path refers to the matcher's app
+func (m *appPathMatcher) matches(path string) bool {
+	if equal, ok := m.seen[path]; ok {
+		return equal
+	}
+
+	clean := filepath.Clean(path)
+	equal := clean == m.clean
+	if !equal {
+		if real, err := filepath.EvalSymlinks(clean); err == nil && m.resolved {
+			equal = real == m.real
+		} else {
+			// Case-insensitive match (macOS filesystems are often case-insensitive)
+			equal = strings.EqualFold(clean, m.clean)
+		}
+	}
+
+	m.seen[path] = equal
+	return equal
+}
+
+// forEachIndex calls fn for every index in [0, n) using up to workers goroutines
+//
+// fn must only touch state belonging to its own index; forEachIndex returns
+// once every call has finished.
+func forEachIndex(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// runWithContext runs fn on its own goroutine and stops waiting for it once ctx is done
+//
+// fn always runs to completion, so any C memory it allocates is still freed.
+func runWithContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+
+	// Buffered so the goroutine can always deliver its result and exit
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// primaryFamily returns the highest priority family any of the UTIs conforms to
+func primaryFamily(utis []string, conforms func(uti, parentUTI string) bool) TypeFamily {
+	for _, candidate := range familyPriority {
+		for _, uti := range utis {
+			if conforms(uti, candidate.uti) {
+				return candidate.family
+			}
+		}
+	}
+
+	return FamilyOther
+}
+
+// formatHandlerConfiguration renders handler preferences as sorted, aligned text sections
+func formatHandlerConfiguration(prefs []handlerPreference) string {
+	sorted := make([]handlerPreference, len(prefs))
+	copy(sorted, prefs)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Identifier != sorted[j].Identifier {
+			return sorted[i].Identifier < sorted[j].Identifier
+		}
+		return sorted[i].Role < sorted[j].Role
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Handler configuration (%d entries)\n", len(sorted))
+
+	sections := []struct {
+		kind  string
+		title string
+	}{
+		{kind: "uti", title: "UTI defaults"},
+		{kind: "scheme", title: "URL scheme defaults"},
+	}
+
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n%s:\n", section.title)
+
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		written := 0
+		for _, pref := range sorted {
+			if pref.Kind != section.kind {
+				continue
+			}
+
+			name, path := pref.App.Name, pref.App.Path
+			if path == "" {
+				name, path = "(not installed)", "-"
+			}
+
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", pref.Identifier, pref.Role, name, pref.App.BundleID, path)
+			written++
+		}
+		w.Flush()
+
+		if written == 0 {
+			b.WriteString("  (none)\n")
+		}
+	}
+
+	return b.String()
+}
+
+// restoreSnapshot applies every mapping in a snapshot, in sorted order
+//
+// Entries without a path, such as those decoded from JSON, are resolved from
+// their bundle ID with find. Failures do not stop the restore; they are
+// collected and returned together.
+func restoreSnapshot(snapshot HandlerSnapshot, find func(bundleID string) (AppInfo, error), setUTI, setScheme func(appPath, identifier string) error) error {
+	var errs []error
+
+	apply := func(kind string, defaults map[string]AppInfo, set func(appPath, identifier string) error) {
+		identifiers := make([]string, 0, len(defaults))
+		for identifier := range defaults {
+			identifiers = append(identifiers, identifier)
+		}
+		sort.Strings(identifiers)
+
+		for _, identifier := range identifiers {
+			app := defaults[identifier]
+			if app.Path == "" && app.BundleID != "" {
+				found, err := find(app.BundleID)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s %s: %s: %w", kind, identifier, app.BundleID, err))
+					continue
+				}
+				app = found
+			}
+
+			if err := set(app.Path, identifier); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", kind, identifier, err))
+			}
+		}
+	}
+
+	apply("UTI", snapshot.UTIs, setUTI)
+	apply("scheme", snapshot.Schemes, setScheme)
+
+	return errors.Join(errs...)
+}
+
+// setDefaults points every UTI and scheme at one application
+//
+// All of them are attempted. Failures are reported as "UTI <uti>" or
+// "scheme <scheme>" and joined, so anything not mentioned in the error was set.
+func setDefaults(appPath string, utis, schemes []string, setUTI, setScheme func(appPath, identifier string) error) error {
+	var errs []error
+
+	for _, uti := range utis {
+		if err := setUTI(appPath, uti); err != nil {
+			errs = append(errs, fmt.Errorf("UTI %s: %w", uti, err))
+		}
+	}
+	for _, scheme := range schemes {
+		if err := setScheme(appPath, scheme); err != nil {
+			errs = append(errs, fmt.Errorf("scheme %s: %w", scheme, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// schemeDescriptions names well-known URL schemes, since macOS has no localized description for them
+//
+// Keep the README table for GetSchemeDescription in sync.
+var schemeDescriptions = map[string]string{
+	"afp":            "AppleShare File Server",
+	"facetime":       "FaceTime Call",
+	"facetime-audio": "FaceTime Audio Call",
+	"feed":           "News Feed",
+	"file":           "Local File",
+	"ftp":            "FTP Site",
+	"http":           "Web Page",
+	"https":          "Web Page",
+	"irc":            "IRC Chat",
+	"itms-apps":      "App Store Page",
+	"mailto":         "Email",
+	"maps":           "Map Location",
+	"news":           "Newsgroup",
+	"sftp":           "SFTP Site",
+	"smb":            "Windows File Share",
+	"sms":            "Text Message",
+	"ssh":            "Secure Shell Connection",
+	"tel":            "Phone Call",
+	"vnc":            "Screen Sharing",
+	"webcal":         "Calendar Subscription",
+}
+
+// schemeDescription returns the name of a well-known scheme and whether the scheme is in the table
+func schemeDescription(scheme string) (string, bool) {
+	description, ok := schemeDescriptions[strings.ToLower(strings.TrimSuffix(scheme, ":"))]
+	return description, ok
+}
+
+// diffHandlerPreferences returns an event for every preference that was added, removed or pointed at another app
+//
+// Preferences are matched by kind, identifier and role. Events are sorted by
+// kind, identifier and role so that they are delivered in a stable order.
+func diffHandlerPreferences(previous, current []handlerPreference) []HandlerChangeEvent {
+	type key struct{ kind, identifier, role string }
+
+	index := func(prefs []handlerPreference) map[key]AppInfo {
+		apps := make(map[key]AppInfo, len(prefs))
+		for _, pref := range prefs {
+			apps[key{pref.Kind, pref.Identifier, pref.Role}] = pref.App
+		}
+		return apps
+	}
+
+	before := index(previous)
+	after := index(current)
+
+	var events []HandlerChangeEvent
+	add := func(k key, prev, cur AppInfo) {
+		events = append(events, HandlerChangeEvent{
+			Kind:       k.kind,
+			Identifier: k.identifier,
+			Role:       Role(k.role),
+			Previous:   prev,
+			Current:    cur,
+		})
+	}
+
+	for k, prev := range before {
+		cur, ok := after[k]
+		if !ok {
+			add(k, prev, AppInfo{})
+		} else if cur.BundleID != prev.BundleID || cur.Path != prev.Path {
+			add(k, prev, cur)
+		}
+	}
+	for k, cur := range after {
+		if _, ok := before[k]; !ok {
+			add(k, AppInfo{}, cur)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind // UTIs before schemes
+		}
+		if a.Identifier != b.Identifier {
+			return a.Identifier < b.Identifier
+		}
+		return a.Role < b.Role
+	})
+
+	return events
+}
+
+// diffAppBundles returns an event for every bundle path that appears in only one of the two lists
+//
+// Events are sorted by path.
+func diffAppBundles(previous, current []string) []AppChangeEvent {
+	before := make(map[string]bool, len(previous))
+	for _, path := range previous {
+		before[path] = true
+	}
+	after := make(map[string]bool, len(current))
+	for _, path := range current {
+		after[path] = true
+	}
+
+	var events []AppChangeEvent
+	for path := range before {
+		if !after[path] {
+			events = append(events, AppChangeEvent{Path: path, Change: AppRemoved})
+		}
+	}
+	for path := range after {
+		if !before[path] {
+			events = append(events, AppChangeEvent{Path: path, Change: AppAdded})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Path < events[j].Path
+	})
+
+	return events
+}
+
+// watchPolled calls list every interval and sends diff's events for the changes since the previous call
+//
+// The first call happens before returning, so a failure to read the initial
+// state is reported to the caller. Later failures are skipped and the next
+// tick tries again. The returned stop function closes the channel and waits
+// for the polling goroutine to exit; calling it more than once is safe.
+func watchPolled[T, E any](list func() (T, error), diff func(previous, current T) []E, interval time.Duration) (<-chan E, func(), error) {
+	state, err := list()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := make(chan E, 16)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			current, err := list()
+			if err != nil {
+				continue
+			}
+
+			for _, event := range diff(state, current) {
+				select {
+				case events <- event:
+				case <-done:
+					return
+				}
+			}
+			state = current
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+
+	return events, stop, nil
+}
+
+// confirmDefault calls set, then reads the default back with get and checks that it is appPath
+//
+// Errors from set are returned as they are. A default that cannot be read
+// back or names another app yields an error wrapping ErrVerificationFailed.
+func confirmDefault(appPath string, set func() error, get func() (string, error)) error {
+	if err := set(); err != nil {
+		return err
+	}
+
+	current, err := get()
+	if err != nil {
+		return fmt.Errorf("%w: reading back the default: %w", ErrVerificationFailed, err)
+	}
+
+	if !appPathsEqual(current, appPath) {
+		return fmt.Errorf("%w: default is %s, want %s", ErrVerificationFailed, current, appPath)
+	}
+
+	return nil
+}
+
+// intersectGroups returns the sorted values that appear in the group of every key
+//
+// A key with no group matches nothing, so the result is empty. No keys also
+// yields an empty result.
+func intersectGroups(groups map[string][]string, keys []string) []string {
+	result := []string{}
+	if len(keys) == 0 {
+		return result
+	}
+
+	// Repeated keys and repeated values within a group must only count once
+	uniqueKeys := sortedUnique(append([]string(nil), keys...))
+	counts := make(map[string]int)
+	for _, key := range uniqueKeys {
+		for _, value := range sortedUnique(append([]string(nil), groups[key]...)) {
+			counts[value]++
+		}
+	}
+
+	for value, count := range counts {
+		if count == len(uniqueKeys) {
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// findAppBundles returns the *.app bundles directly in root or in one level of folders below it
+//
+// This mirrors how apps are installed: in /Applications itself or in a folder such as
+// /Applications/Utilities. Symlinks to bundles and folders are followed, bundles are not
+// looked inside, and folders that cannot be read are skipped. A missing root yields no bundles.
+func findAppBundles(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var bundles []string
+	for _, entry := range entries {
+		path := filepath.Join(root, entry.Name())
+		if !isDirFollowingLinks(path, entry) {
+			continue
+		}
+		if strings.EqualFold(filepath.Ext(path), ".app") {
+			bundles = append(bundles, path)
+			continue
+		}
+
+		subEntries, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, subEntry := range subEntries {
+			subPath := filepath.Join(path, subEntry.Name())
+			if strings.EqualFold(filepath.Ext(subPath), ".app") && isDirFollowingLinks(subPath, subEntry) {
+				bundles = append(bundles, subPath)
+			}
+		}
+	}
+
+	return bundles, nil
+}
+
+// isDirFollowingLinks reports whether entry is a directory or a symlink to one
+func isDirFollowingLinks(path string, entry fs.DirEntry) bool {
+	if entry.Type()&fs.ModeSymlink == 0 {
+		return entry.IsDir()
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// resolveSnapshot fills in the application of every snapshot entry from its bundle ID
+//
+// Entries whose app is not installed are dropped and reported as "UTI <uti>" or
+// "scheme <scheme>", in sorted order.
+func resolveSnapshot(snapshot HandlerSnapshot, find func(bundleID string) (AppInfo, error)) (HandlerSnapshot, []string, error) {
+	resolved := HandlerSnapshot{
+		UTIs:    make(map[string]AppInfo),
+		Schemes: make(map[string]AppInfo),
+	}
+	skipped := []string{}
+
+	resolve := func(kind string, defaults, into map[string]AppInfo) error {
+		identifiers := make([]string, 0, len(defaults))
+		for identifier := range defaults {
+			identifiers = append(identifiers, identifier)
+		}
+		sort.Strings(identifiers)
+
+		for _, identifier := range identifiers {
+			app, err := find(defaults[identifier].BundleID)
+			if err != nil {
+				if hasErrorCode(err, ErrNotFound) || errors.Is(err, ErrInvalidParameters) {
+					skipped = append(skipped, kind+" "+identifier)
+					continue
+				}
+				return err
+			}
+			into[identifier] = app
+		}
+		return nil
+	}
+
+	if err := resolve("UTI", snapshot.UTIs, resolved.UTIs); err != nil {
+		return HandlerSnapshot{}, nil, err
+	}
+	if err := resolve("scheme", snapshot.Schemes, resolved.Schemes); err != nil {
+		return HandlerSnapshot{}, nil, err
+	}
+
+	return resolved, skipped, nil
+}
+
+// applicationLocationRank orders install locations from most to least canonical
+func applicationLocationRank(appPath string) int {
+	switch {
+	case strings.HasPrefix(appPath, "/Applications/"):
+		return 0
+	case strings.HasPrefix(appPath, "/System/Applications/"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// compareVersions compares dotted version strings numerically, returning -1, 0 or 1
+//
+// Non-numeric components compare as strings; missing components count as zero.
+func compareVersions(a, b string) int {
+	partsA := strings.Split(a, ".")
+	partsB := strings.Split(b, ".")
+
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		partA, partB := "0", "0"
+		if i < len(partsA) && partsA[i] != "" {
+			partA = partsA[i]
+		}
+		if i < len(partsB) && partsB[i] != "" {
+			partB = partsB[i]
+		}
+
+		numA, errA := strconv.Atoi(partA)
+		numB, errB := strconv.Atoi(partB)
+		if errA == nil && errB == nil {
+			if numA != numB {
+				if numA < numB {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+
+		if c := strings.Compare(partA, partB); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// preferApplication reports whether candidate should replace current as the copy of an app to keep
+func preferApplication(candidate, current AppInfo) bool {
+	if rankA, rankB := applicationLocationRank(candidate.Path), applicationLocationRank(current.Path); rankA != rankB {
+		return rankA < rankB
+	}
+	if c := compareVersions(candidate.Version, current.Version); c != 0 {
+		return c > 0
+	}
+	return candidate.Path < current.Path
+}
+
+// dedupeApplications keeps one copy of every bundle ID, in order of first appearance
+//
+// Apps without a bundle ID cannot be matched up and are all kept.
+func dedupeApplications(apps []AppInfo) []AppInfo {
+	result := []AppInfo{}
+	index := make(map[string]int)
+
+	for _, app := range apps {
+		if app.BundleID == "" {
+			result = append(result, app)
+			continue
+		}
+
+		i, ok := index[app.BundleID]
+		if !ok {
+			index[app.BundleID] = len(result)
+			result = append(result, app)
+			continue
+		}
+
+		if preferApplication(app, result[i]) {
+			result[i] = app
+		}
+	}
+
+	return result
+}
+
+// filterApplicationsByName returns the apps whose display name contains query, ignoring case
+func filterApplicationsByName(apps []AppInfo, query string) []AppInfo {
+	query = strings.ToLower(query)
+
+	matches := []AppInfo{}
+	for _, app := range apps {
+		if strings.Contains(strings.ToLower(app.Name), query) {
+			matches = append(matches, app)
+		}
+	}
+
+	return matches
+}
+
+// filterApplicationsByCategory returns the apps whose category is exactly category
+func filterApplicationsByCategory(apps []AppInfo, category string) []AppInfo {
+	matches := []AppInfo{}
+	for _, app := range apps {
+		if app.Category == category {
+			matches = append(matches, app)
+		}
+	}
+
+	return matches
+}
+
+// filterDocumentTypesByRole returns the document types declared with role; RoleAll keeps every type
+func filterDocumentTypesByRole(docTypes []DocumentType, role Role) []DocumentType {
+	matches := []DocumentType{}
+	for _, docType := range docTypes {
+		if role == RoleAll || docType.Role == role {
+			matches = append(matches, docType)
+		}
+	}
+
+	return matches
+}
+
+// findDocumentTypeForUTI returns the first document type that lists uti, compared case-insensitively
+func findDocumentTypeForUTI(docTypes []DocumentType, uti string) (DocumentType, bool) {
+	for _, docType := range docTypes {
+		for _, declared := range docType.UTIs {
+			if strings.EqualFold(declared, uti) {
+				return docType, true
+			}
+		}
+	}
+
+	return DocumentType{}, false
+}
+
+// declaresOrConformsTo reports whether a document type lists uti or a UTI that uti conforms to
+func declaresOrConformsTo(docTypes []DocumentType, uti string, conforms func(uti, parentUTI string) bool) bool {
+	if _, ok := findDocumentTypeForUTI(docTypes, uti); ok {
+		return true
+	}
+
+	for _, docType := range docTypes {
+		for _, declared := range docType.UTIs {
+			if conforms(uti, declared) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// handlerRankOrder orders handler ranks from most to least preferred
+var handlerRankOrder = map[HandlerRank]int{
+	HandlerRankOwner:     0,
+	HandlerRankDefault:   1,
+	"":                   2, // Not specified
+	HandlerRankAlternate: 3,
+	HandlerRankNone:      4,
+	HandlerRankUnknown:   5,
+}
+
+// declaredHandlerRole returns the rank and role of the document type an app uses to open a UTI
+//
+// A document type that lists the UTI itself wins; otherwise the first one
+// listing a UTI that the given UTI conforms to is used.
+func declaredHandlerRole(docTypes []DocumentType, uti string, conforms func(uti, parentUTI string) bool) (rank HandlerRank, role Role) {
+	for _, docType := range docTypes {
+		for _, declared := range docType.UTIs {
+			if declared == uti {
+				return docType.HandlerRank, docType.Role
+			}
+		}
+	}
+
+	for _, docType := range docTypes {
+		for _, declared := range docType.UTIs {
+			if conforms(uti, declared) {
+				return docType.HandlerRank, docType.Role
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// strongestClaimants returns the apps with the highest of the Owner and Default ranks present, sorted by path
+func strongestClaimants(apps []RankedApp) []AppInfo {
+	for _, rank := range []HandlerRank{HandlerRankOwner, HandlerRankDefault} {
+		claimants := []AppInfo{}
+		for _, app := range apps {
+			if app.HandlerRank == rank {
+				claimants = append(claimants, app.AppInfo)
+			}
+		}
+		if len(claimants) > 0 {
+			return sortedUniqueApps(claimants)
+		}
+	}
+
+	return []AppInfo{}
+}
+
+// sortRankedApps puts the default handler first, then orders by handler rank
+//
+// The sort is stable, so apps with the same rank keep the order they came in
+// (LaunchServices' order, as ListAppInfosForUTI returns them).
+func sortRankedApps(apps []RankedApp) {
+	sort.SliceStable(apps, func(i, j int) bool {
+		if apps[i].IsDefault != apps[j].IsDefault {
+			return apps[i].IsDefault
+		}
+		return handlerRankOrder[apps[i].HandlerRank] < handlerRankOrder[apps[j].HandlerRank]
+	})
+}
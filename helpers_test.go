@@ -0,0 +1,1187 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPrimaryFamily tests deterministic family selection for multi-conforming UTIs
+func TestPrimaryFamily(t *testing.T) {
+	// com.example.multi conforms to image, text and data at once
+	parents := map[string][]string{
+		"com.example.multi": {"public.image", "public.text", "public.data"},
+		"com.example.doc":   {"public.text", "public.data"},
+		"com.example.blob":  {"public.data"},
+		"com.example.movie": {"public.audiovisual-content", "public.image", "public.data"},
+	}
+	conforms := func(uti, parentUTI string) bool {
+		for _, p := range parents[uti] {
+			if p == parentUTI {
+				return true
+			}
+		}
+		return false
+	}
+
+	tests := []struct {
+		name string
+		utis []string
+		want TypeFamily
+	}{
+		{name: "image and text", utis: []string{"com.example.multi"}, want: FamilyImage},
+		{name: "audiovisual wins over image", utis: []string{"com.example.movie"}, want: FamilyAudiovisual},
+		{name: "text", utis: []string{"com.example.doc"}, want: FamilyText},
+		{name: "data", utis: []string{"com.example.blob"}, want: FamilyData},
+		{name: "mixed UTIs", utis: []string{"com.example.blob", "com.example.doc"}, want: FamilyText},
+		{name: "unknown", utis: []string{"com.example.unknown"}, want: FamilyOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := primaryFamily(tt.utis, conforms); got != tt.want {
+				t.Errorf("primaryFamily(%v) = %q, want %q", tt.utis, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFormatHandlerConfiguration tests that the configuration report is sorted and stable
+func TestFormatHandlerConfiguration(t *testing.T) {
+	prefs := []handlerPreference{
+		{Kind: "scheme", Identifier: "mailto", Role: "All", App: AppInfo{Name: "Mail", Path: "/System/Applications/Mail.app", BundleID: "com.apple.mail"}},
+		{Kind: "uti", Identifier: "public.plain-text", Role: "Viewer", App: AppInfo{BundleID: "com.example.Gone"}},
+		{Kind: "uti", Identifier: "public.html", Role: "All", App: AppInfo{Name: "Safari", Path: "/Applications/Safari.app", BundleID: "com.apple.Safari"}},
+		{Kind: "uti", Identifier: "public.plain-text", Role: "All", App: AppInfo{Name: "TextEdit", Path: "/System/Applications/TextEdit.app", BundleID: "com.apple.TextEdit"}},
+	}
+
+	want := `Handler configuration (4 entries)
+
+UTI defaults:
+  public.html        All     Safari           com.apple.Safari    /Applications/Safari.app
+  public.plain-text  All     TextEdit         com.apple.TextEdit  /System/Applications/TextEdit.app
+  public.plain-text  Viewer  (not installed)  com.example.Gone    -
+
+URL scheme defaults:
+  mailto  All  Mail  com.apple.mail  /System/Applications/Mail.app
+`
+
+	got := formatHandlerConfiguration(prefs)
+	if got != want {
+		t.Errorf("formatHandlerConfiguration() =\n%s\nwant:\n%s", got, want)
+	}
+
+	// Input order must not affect the output
+	reversed := []handlerPreference{prefs[3], prefs[2], prefs[1], prefs[0]}
+	if again := formatHandlerConfiguration(reversed); again != got {
+		t.Errorf("formatHandlerConfiguration() output depends on input order")
+	}
+
+	empty := formatHandlerConfiguration(nil)
+	if !strings.Contains(empty, "UTI defaults:\n  (none)") || !strings.Contains(empty, "URL scheme defaults:\n  (none)") {
+		t.Errorf("formatHandlerConfiguration(nil) = %q, want empty sections marked (none)", empty)
+	}
+}
+
+// TestRunWithContext tests returning early while the work finishes in the background
+func TestRunWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	finished := make(chan struct{})
+	_, err := runWithContext(ctx, func() (int, error) {
+		defer close(finished)
+		time.Sleep(100 * time.Millisecond)
+		return 1, nil
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("runWithContext() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	select {
+	case <-finished:
+		t.Errorf("runWithContext() waited for the work to finish")
+	default:
+	}
+
+	<-finished
+
+	got, err := runWithContext(context.Background(), func() (int, error) { return 42, nil })
+	if err != nil || got != 42 {
+		t.Errorf("runWithContext() = %d, %v, want 42, nil", got, err)
+	}
+}
+
+// TestNormalizeExtension tests stripping the leading dot and case from extensions
+func TestNormalizeExtension(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"txt", "txt"},
+		{".txt", "txt"},
+		{".TXT", "txt"},
+		{"Md", "md"},
+		{"..gz", ".gz"},
+		{".", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeExtension(tt.in); got != tt.want {
+			t.Errorf("normalizeExtension(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestSortedUnique tests sorting and deduplicating string results
+func TestSortedUnique(t *testing.T) {
+	got := sortedUnique([]string{"txt", "text", "txt", "asc", "text"})
+	want := []string{"asc", "text", "txt"}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("sortedUnique() = %v, want %v", got, want)
+	}
+
+	if got := sortedUnique([]string{}); len(got) != 0 {
+		t.Errorf("sortedUnique(empty) = %v, want empty", got)
+	}
+}
+
+// TestIntersectGroups tests finding the values shared by every group
+func TestIntersectGroups(t *testing.T) {
+	groups := map[string][]string{
+		"public.plain-text": {"/Applications/TextEdit.app", "/Applications/Xcode.app", "/Applications/BBEdit.app"},
+		"public.html":       {"/Applications/Safari.app", "/Applications/Xcode.app", "/Applications/BBEdit.app", "/Applications/Xcode.app"},
+	}
+
+	tests := []struct {
+		name string
+		keys []string
+		want []string
+	}{
+		{"shared", []string{"public.plain-text", "public.html"}, []string{"/Applications/BBEdit.app", "/Applications/Xcode.app"}},
+		{"single", []string{"public.html"}, []string{"/Applications/BBEdit.app", "/Applications/Safari.app", "/Applications/Xcode.app"}},
+		{"repeated key", []string{"public.html", "public.html", "public.plain-text"}, []string{"/Applications/BBEdit.app", "/Applications/Xcode.app"}},
+		{"missing key", []string{"public.html", "com.example.unknown"}, []string{}},
+		{"no keys", nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := intersectGroups(groups, tt.keys)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || got == nil {
+				t.Errorf("intersectGroups(%v) = %v, want %v", tt.keys, got, tt.want)
+			}
+		})
+	}
+
+	// The groups themselves must be left untouched
+	if groups["public.html"][0] != "/Applications/Safari.app" {
+		t.Errorf("intersectGroups() reordered its input: %v", groups["public.html"])
+	}
+}
+
+// TestUniqueInOrder tests dropping repeated strings while keeping their order
+func TestUniqueInOrder(t *testing.T) {
+	got := uniqueInOrder([]string{"public.plain-text", "com.example.text", "public.plain-text", "public.text"})
+	want := []string{"public.plain-text", "com.example.text", "public.text"}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("uniqueInOrder() = %v, want %v", got, want)
+	}
+}
+
+// TestUniqueAppsInOrder tests dropping repeated app paths while keeping their order
+func TestUniqueAppsInOrder(t *testing.T) {
+	got := uniqueAppsInOrder([]AppInfo{
+		{Name: "Safari", Path: "/Applications/Safari.app"},
+		{Name: "Chrome", Path: "/Applications/Google Chrome.app"},
+		{Name: "Safari copy", Path: "/Applications/Safari.app"},
+	})
+
+	if len(got) != 2 || got[0].Name != "Safari" || got[1].Name != "Chrome" {
+		t.Errorf("uniqueAppsInOrder() = %v, want Safari then Chrome", got)
+	}
+}
+
+// TestSortedUniqueApps tests sorting and deduplicating applications by path
+func TestSortedUniqueApps(t *testing.T) {
+	apps := []AppInfo{
+		{Name: "TextEdit", Path: "/System/Applications/TextEdit.app"},
+		{Name: "Code", Path: "/Applications/Visual Studio Code.app"},
+		{Name: "TextEdit copy", Path: "/System/Applications/TextEdit.app"},
+	}
+
+	got := sortedUniqueApps(apps)
+	if len(got) != 2 {
+		t.Fatalf("sortedUniqueApps() = %+v, want 2 apps", got)
+	}
+	if got[0].Name != "Code" || got[1].Name != "TextEdit" {
+		t.Errorf("sortedUniqueApps() = %+v, want Code then the first TextEdit", got)
+	}
+}
+
+// TestAppNameFromPath tests deriving a display name from a bundle path
+func TestAppNameFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/Applications/Safari.app", "Safari"},
+		{"/Applications/Visual Studio Code.app/", "Visual Studio Code"},
+		{"/opt/tools/helper", "helper"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := appNameFromPath(tt.path); got != tt.want {
+			t.Errorf("appNameFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestAppPathsEqual tests app path comparison
+func TestAppPathsEqual(t *testing.T) {
+	tests := []struct {
+		name  string
+		path1 string
+		path2 string
+		want  bool
+	}{
+		{
+			name:  "identical",
+			path1: "/Applications/Safari.app",
+			path2: "/Applications/Safari.app",
+			want:  true,
+		},
+		{
+			name:  "trailing slash",
+			path1: "/Applications/Safari.app/",
+			path2: "/Applications/Safari.app",
+			want:  true,
+		},
+		{
+			name:  "different case",
+			path1: "/Applications/NonExistent12345.app",
+			path2: "/applications/nonexistent12345.app",
+			want:  true,
+		},
+		{
+			name:  "different apps",
+			path1: "/Applications/Safari.app",
+			path2: "/Applications/Mail.app",
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appPathsEqual(tt.path1, tt.path2); got != tt.want {
+				t.Errorf("appPathsEqual(%q, %q) = %v, want %v", tt.path1, tt.path2, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAppPathMatcher tests that the matcher agrees with appPathsEqual, including through symlinks
+func TestAppPathMatcher(t *testing.T) {
+	dir := t.TempDir()
+	app := filepath.Join(dir, "Real.app")
+	if err := os.Mkdir(app, 0o755); err != nil {
+		t.Fatalf("failed to create app directory: %v", err)
+	}
+	link := filepath.Join(dir, "Link.app")
+	if err := os.Symlink(app, link); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	paths := []string{
+		app,
+		app + "/",
+		link,
+		filepath.Join(dir, "Other.app"),
+		strings.ToUpper(filepath.Join(dir, "Missing.app")),
+		filepath.Join(dir, "Missing.app"),
+	}
+
+	for _, appPath := range []string{app, link, filepath.Join(dir, "Missing.app")} {
+		m := newAppPathMatcher(appPath)
+		for _, path := range paths {
+			// Ask twice so the remembered answer is checked too
+			for range 2 {
+				if got, want := m.matches(path), appPathsEqual(appPath, path); got != want {
+					t.Errorf("matcher(%q).matches(%q) = %v, appPathsEqual = %v", appPath, path, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestForEachIndex tests that every index is visited exactly once for various worker counts
+func TestForEachIndex(t *testing.T) {
+	for _, workers := range []int{0, 1, 4, 100} {
+		visits := make([]int, 10)
+		forEachIndex(len(visits), workers, func(i int) {
+			visits[i]++
+		})
+
+		for i, n := range visits {
+			if n != 1 {
+				t.Errorf("workers=%d: index %d visited %d times, want 1", workers, i, n)
+			}
+		}
+	}
+
+	forEachIndex(0, 4, func(i int) {
+		t.Errorf("fn called with %d for n = 0", i)
+	})
+}
+
+// TestRestoreSnapshot tests that every mapping is attempted and failures are collected
+func TestRestoreSnapshot(t *testing.T) {
+	snapshot := HandlerSnapshot{
+		UTIs: map[string]AppInfo{
+			"public.plain-text": {Path: "/System/Applications/TextEdit.app"},
+			"public.html":       {Path: "/Applications/Missing.app"},
+		},
+		Schemes: map[string]AppInfo{
+			"http":   {Path: "/Applications/Missing.app"},
+			"mailto": {BundleID: "com.apple.mail"},
+			"webcal": {BundleID: "com.example.gone"},
+		},
+	}
+
+	errNotInstalled := errors.New("not installed")
+	find := func(bundleID string) (AppInfo, error) {
+		if bundleID == "com.apple.mail" {
+			return AppInfo{Path: "/System/Applications/Mail.app", BundleID: bundleID}, nil
+		}
+		return AppInfo{}, errNotInstalled
+	}
+
+	errMissing := errors.New("app missing")
+	var mailtoPath string
+	var applied []string
+	set := func(kind string) func(appPath, identifier string) error {
+		return func(appPath, identifier string) error {
+			applied = append(applied, kind+" "+identifier)
+			if identifier == "mailto" {
+				mailtoPath = appPath
+			}
+			if appPath == "/Applications/Missing.app" {
+				return errMissing
+			}
+			return nil
+		}
+	}
+
+	err := restoreSnapshot(snapshot, find, set("uti"), set("scheme"))
+
+	want := []string{"uti public.html", "uti public.plain-text", "scheme http", "scheme mailto"}
+	if strings.Join(applied, ",") != strings.Join(want, ",") {
+		t.Errorf("restoreSnapshot() applied %v, want %v", applied, want)
+	}
+
+	if mailtoPath != "/System/Applications/Mail.app" {
+		t.Errorf("restoreSnapshot() set mailto to %q, want the path found by bundle ID", mailtoPath)
+	}
+
+	if !errors.Is(err, errMissing) || !errors.Is(err, errNotInstalled) {
+		t.Fatalf("restoreSnapshot() error = %v, want it to wrap the setter and lookup errors", err)
+	}
+	for _, identifier := range []string{"UTI public.html", "scheme http", "scheme webcal: com.example.gone"} {
+		if !strings.Contains(err.Error(), identifier) {
+			t.Errorf("restoreSnapshot() error %q does not mention %q", err, identifier)
+		}
+	}
+
+	if err := restoreSnapshot(HandlerSnapshot{}, find, set("uti"), set("scheme")); err != nil {
+		t.Errorf("restoreSnapshot() on empty snapshot error = %v, want nil", err)
+	}
+}
+
+// TestHandlerSnapshotJSON tests that snapshots are stored by bundle ID with stable output
+func TestHandlerSnapshotJSON(t *testing.T) {
+	snapshot := HandlerSnapshot{
+		UTIs: map[string]AppInfo{
+			"public.plain-text": {Name: "TextEdit", Path: "/System/Applications/TextEdit.app", BundleID: "com.apple.TextEdit"},
+			"public.html":       {Name: "Safari", Path: "/Applications/Safari.app", BundleID: "com.apple.Safari"},
+		},
+		Schemes: map[string]AppInfo{
+			"mailto": {Name: "Mail", Path: "/System/Applications/Mail.app", BundleID: "com.apple.mail"},
+		},
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `{"utis":{"public.html":"com.apple.Safari","public.plain-text":"com.apple.TextEdit"},"schemes":{"mailto":"com.apple.mail"}}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	var decoded HandlerSnapshot
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(decoded.UTIs) != 2 || decoded.UTIs["public.html"].BundleID != "com.apple.Safari" || decoded.UTIs["public.html"].Path != "" {
+		t.Errorf("json.Unmarshal() UTIs = %+v", decoded.UTIs)
+	}
+	if decoded.Schemes["mailto"].BundleID != "com.apple.mail" {
+		t.Errorf("json.Unmarshal() Schemes = %+v", decoded.Schemes)
+	}
+
+	snapshot.UTIs["com.example.doc"] = AppInfo{Name: "Unbundled", Path: "/Applications/Unbundled.app"}
+	snapshot.Schemes["example"] = AppInfo{Name: "Unbundled", Path: "/Applications/Unbundled.app"}
+	_, err = json.Marshal(snapshot)
+	if !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("json.Marshal() with unbundled handlers error = %v, want ErrInvalidParameters", err)
+	}
+	if !strings.Contains(err.Error(), "UTI com.example.doc, scheme example") {
+		t.Errorf("json.Marshal() error %q does not name the unbundled entries", err)
+	}
+}
+
+// TestResolveSnapshot tests resolving bundle IDs and reporting apps that are not installed
+func TestResolveSnapshot(t *testing.T) {
+	installed := map[string]AppInfo{
+		"com.apple.TextEdit": {Name: "TextEdit", Path: "/System/Applications/TextEdit.app", BundleID: "com.apple.TextEdit"},
+		"com.apple.mail":     {Name: "Mail", Path: "/System/Applications/Mail.app", BundleID: "com.apple.mail"},
+	}
+	find := func(bundleID string) (AppInfo, error) {
+		if app, ok := installed[bundleID]; ok {
+			return app, nil
+		}
+		return AppInfo{}, &BridgeError{Code: ErrNotFound, Message: "not installed"}
+	}
+
+	snapshot := HandlerSnapshot{
+		UTIs: map[string]AppInfo{
+			"public.plain-text": {BundleID: "com.apple.TextEdit"},
+			"public.html":       {BundleID: "com.example.gone"},
+		},
+		Schemes: map[string]AppInfo{
+			"mailto": {BundleID: "com.apple.mail"},
+			"http":   {BundleID: "com.example.gone"},
+		},
+	}
+
+	resolved, skipped, err := resolveSnapshot(snapshot, find)
+	if err != nil {
+		t.Fatalf("resolveSnapshot() error = %v", err)
+	}
+
+	if resolved.UTIs["public.plain-text"].Path != "/System/Applications/TextEdit.app" || len(resolved.UTIs) != 1 {
+		t.Errorf("resolveSnapshot() UTIs = %+v", resolved.UTIs)
+	}
+	if resolved.Schemes["mailto"].Path != "/System/Applications/Mail.app" || len(resolved.Schemes) != 1 {
+		t.Errorf("resolveSnapshot() Schemes = %+v", resolved.Schemes)
+	}
+
+	wantSkipped := []string{"UTI public.html", "scheme http"}
+	if strings.Join(skipped, ",") != strings.Join(wantSkipped, ",") {
+		t.Errorf("resolveSnapshot() skipped = %v, want %v", skipped, wantSkipped)
+	}
+
+	errSystem := &BridgeError{Code: ErrSystem, Message: "boom"}
+	_, _, err = resolveSnapshot(snapshot, func(string) (AppInfo, error) { return AppInfo{}, errSystem })
+	if !errors.Is(err, errSystem) {
+		t.Errorf("resolveSnapshot() error = %v, want %v", err, errSystem)
+	}
+}
+
+// TestCompareVersions tests numeric comparison of dotted version strings
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.10", "1.9", 1},
+		{"2", "10", -1},
+		{"1.2", "1.2.0", 0},
+		{"", "1.0", -1},
+		{"1.0b2", "1.0b1", 1},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestDedupeApplications tests the tie-breaking rules for duplicate bundle IDs
+func TestDedupeApplications(t *testing.T) {
+	apps := []AppInfo{
+		{Name: "Editor", Path: "/Users/me/Downloads/Editor.app", BundleID: "com.example.editor", Version: "3.0"},
+		{Name: "Helper", Path: "/Library/Helper.app"},
+		{Name: "Viewer", Path: "/opt/b/Viewer.app", BundleID: "com.example.viewer", Version: "1.2"},
+		{Name: "Editor", Path: "/Applications/Editor.app", BundleID: "com.example.editor", Version: "2.0"},
+		{Name: "Viewer", Path: "/opt/a/Viewer.app", BundleID: "com.example.viewer", Version: "1.10"},
+		{Name: "Helper", Path: "/Library/Other/Helper.app"},
+		{Name: "Mail", Path: "/opt/Mail.app", BundleID: "com.apple.mail", Version: "16.0"},
+		{Name: "Mail", Path: "/System/Applications/Mail.app", BundleID: "com.apple.mail", Version: "16.0"},
+	}
+
+	got := dedupeApplications(apps)
+
+	wantPaths := []string{
+		"/Applications/Editor.app",      // canonical location beats a newer copy elsewhere
+		"/Library/Helper.app",           // no bundle ID, kept
+		"/opt/a/Viewer.app",             // same location rank, higher version
+		"/Library/Other/Helper.app",     // no bundle ID, kept
+		"/System/Applications/Mail.app", // /System/Applications beats other locations
+	}
+
+	var gotPaths []string
+	for _, app := range got {
+		gotPaths = append(gotPaths, app.Path)
+	}
+
+	if strings.Join(gotPaths, ",") != strings.Join(wantPaths, ",") {
+		t.Errorf("dedupeApplications() = %v, want %v", gotPaths, wantPaths)
+	}
+
+	if empty := dedupeApplications(nil); empty == nil || len(empty) != 0 {
+		t.Errorf("dedupeApplications(nil) = %v, want empty slice", empty)
+	}
+}
+
+// TestFilterApplicationsByName tests case-insensitive substring matching on display names
+func TestFilterApplicationsByName(t *testing.T) {
+	apps := []AppInfo{
+		{Name: "TextEdit", Path: "/System/Applications/TextEdit.app"},
+		{Name: "Visual Studio Code", Path: "/Applications/Visual Studio Code.app"},
+		{Name: "Sublime Text", Path: "/Applications/Sublime Text.app"},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "text", want: []string{"TextEdit", "Sublime Text"}},
+		{query: "CODE", want: []string{"Visual Studio Code"}},
+		{query: "xcode", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := filterApplicationsByName(apps, tt.query)
+			if got == nil {
+				t.Fatalf("filterApplicationsByName() returned nil, want empty slice")
+			}
+
+			var names []string
+			for _, app := range got {
+				names = append(names, app.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("filterApplicationsByName(%q) = %v, want %v", tt.query, names, tt.want)
+			}
+		})
+	}
+}
+
+// TestFilterApplicationsByCategory tests exact matching of app categories
+func TestFilterApplicationsByCategory(t *testing.T) {
+	apps := []AppInfo{
+		{Name: "Xcode", Category: "public.app-category.developer-tools"},
+		{Name: "Pages", Category: "public.app-category.productivity"},
+		{Name: "Script", Category: ""},
+	}
+
+	tests := []struct {
+		category string
+		want     []string
+	}{
+		{"public.app-category.developer-tools", []string{"Xcode"}},
+		{"public.app-category.developer", []string{}},
+		{"PUBLIC.APP-CATEGORY.PRODUCTIVITY", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			got := filterApplicationsByCategory(apps, tt.category)
+			if got == nil {
+				t.Fatalf("filterApplicationsByCategory() = nil, want empty slice")
+			}
+
+			names := make([]string, len(got))
+			for i, app := range got {
+				names[i] = app.Name
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("filterApplicationsByCategory(%q) = %v, want %v", tt.category, names, tt.want)
+			}
+		})
+	}
+}
+
+// TestDeclaredHandlerRole tests picking the document type an app uses for a UTI
+func TestDeclaredHandlerRole(t *testing.T) {
+	docTypes := []DocumentType{
+		{TypeName: "Text", Role: "Editor", HandlerRank: "Alternate", UTIs: []string{"public.text"}},
+		{TypeName: "Markdown", Role: "Viewer", HandlerRank: "Owner", UTIs: []string{"net.daringfireball.markdown"}},
+	}
+	conforms := func(uti, parentUTI string) bool {
+		return parentUTI == "public.text" && uti != "public.image"
+	}
+
+	tests := []struct {
+		uti      string
+		wantRank HandlerRank
+		wantRole Role
+	}{
+		{uti: "net.daringfireball.markdown", wantRank: "Owner", wantRole: "Viewer"},
+		{uti: "public.plain-text", wantRank: "Alternate", wantRole: "Editor"},
+		{uti: "public.image", wantRank: "", wantRole: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uti, func(t *testing.T) {
+			rank, role := declaredHandlerRole(docTypes, tt.uti, conforms)
+			if rank != tt.wantRank || role != tt.wantRole {
+				t.Errorf("declaredHandlerRole() = %q, %q, want %q, %q", rank, role, tt.wantRank, tt.wantRole)
+			}
+		})
+	}
+}
+
+// TestSetDefaults tests that every identifier is attempted and failures are labelled
+func TestSetDefaults(t *testing.T) {
+	var attempted []string
+	setUTI := func(appPath, uti string) error {
+		attempted = append(attempted, uti)
+		return nil
+	}
+	setScheme := func(appPath, scheme string) error {
+		attempted = append(attempted, scheme)
+		if scheme == "http" {
+			return ErrUserDeclinedError
+		}
+		return nil
+	}
+
+	err := setDefaults("/Applications/Firefox.app", []string{"public.html"}, []string{"http", "https"}, setUTI, setScheme)
+
+	if got := strings.Join(attempted, ","); got != "public.html,http,https" {
+		t.Errorf("setDefaults() attempted %s, want public.html,http,https", got)
+	}
+	if !errors.Is(err, ErrUserDeclinedError) {
+		t.Errorf("setDefaults() error = %v, want ErrUserDeclinedError", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "scheme http") || strings.Contains(err.Error(), "https") {
+		t.Errorf("setDefaults() error = %v, want only scheme http reported", err)
+	}
+
+	if err := setDefaults("/Applications/Mail.app", nil, []string{"mailto"}, setUTI, setScheme); err != nil {
+		t.Errorf("setDefaults() error = %v, want nil", err)
+	}
+}
+
+// TestSchemeDescription tests the built-in names for well-known URL schemes
+func TestSchemeDescription(t *testing.T) {
+	tests := []struct {
+		scheme string
+		want   string
+		wantOK bool
+	}{
+		{"mailto", "Email", true},
+		{"http", "Web Page", true},
+		{"HTTPS", "Web Page", true},
+		{"tel:", "Phone Call", true},
+		{"vscode", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := schemeDescription(tt.scheme)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("schemeDescription(%q) = %q, %v, want %q, %v", tt.scheme, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestDiffHandlerPreferences tests reporting added, removed and changed defaults in a stable order
+func TestDiffHandlerPreferences(t *testing.T) {
+	textEdit := AppInfo{Name: "TextEdit", Path: "/System/Applications/TextEdit.app", BundleID: "com.apple.TextEdit"}
+	bbedit := AppInfo{Name: "BBEdit", Path: "/Applications/BBEdit.app", BundleID: "com.barebones.bbedit"}
+	safari := AppInfo{Name: "Safari", Path: "/Applications/Safari.app", BundleID: "com.apple.Safari"}
+
+	previous := []handlerPreference{
+		{Kind: "uti", Identifier: "public.plain-text", Role: "All", App: textEdit},
+		{Kind: "uti", Identifier: "public.html", Role: "Viewer", App: safari},
+		{Kind: "scheme", Identifier: "http", Role: "All", App: safari},
+	}
+	current := []handlerPreference{
+		{Kind: "scheme", Identifier: "http", Role: "All", App: safari},
+		{Kind: "uti", Identifier: "public.plain-text", Role: "All", App: bbedit},
+		{Kind: "scheme", Identifier: "https", Role: "All", App: safari},
+	}
+
+	got := diffHandlerPreferences(previous, current)
+	want := []HandlerChangeEvent{
+		{Kind: "uti", Identifier: "public.html", Role: RoleViewer, Previous: safari},
+		{Kind: "uti", Identifier: "public.plain-text", Role: RoleAll, Previous: textEdit, Current: bbedit},
+		{Kind: "scheme", Identifier: "https", Role: RoleAll, Current: safari},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("diffHandlerPreferences() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("diffHandlerPreferences()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := diffHandlerPreferences(current, current); len(got) != 0 {
+		t.Errorf("diffHandlerPreferences(unchanged) = %+v, want none", got)
+	}
+}
+
+// TestDiffAppBundles tests reporting installed and removed bundles sorted by path
+func TestDiffAppBundles(t *testing.T) {
+	previous := []string{"/Applications/Safari.app", "/Applications/Old.app", "/Applications/Utilities/Terminal.app"}
+	current := []string{"/Applications/Utilities/Terminal.app", "/Applications/New.app", "/Applications/Safari.app"}
+
+	got := diffAppBundles(previous, current)
+	want := []AppChangeEvent{
+		{Path: "/Applications/New.app", Change: AppAdded},
+		{Path: "/Applications/Old.app", Change: AppRemoved},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("diffAppBundles() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("diffAppBundles()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// TestWatchPolled tests that changes are sent and stop closes the channel
+func TestWatchPolled(t *testing.T) {
+	safari := AppInfo{Name: "Safari", Path: "/Applications/Safari.app", BundleID: "com.apple.Safari"}
+
+	var mu sync.Mutex
+	var prefs []handlerPreference
+	list := func() ([]handlerPreference, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return prefs, nil
+	}
+
+	events, stop, err := watchPolled(list, diffHandlerPreferences, time.Millisecond)
+	if err != nil {
+		t.Fatalf("watchPolled() error = %v", err)
+	}
+	defer stop()
+
+	mu.Lock()
+	prefs = []handlerPreference{{Kind: "scheme", Identifier: "https", Role: "All", App: safari}}
+	mu.Unlock()
+
+	select {
+	case event := <-events:
+		if event.Identifier != "https" || event.Current != safari {
+			t.Errorf("event = %+v, want https set to Safari", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received after a preference changed")
+	}
+
+	stop()
+	for range events {
+		// Drain anything sent before stop; the loop ends once the channel is closed
+	}
+	stop()
+
+	if _, _, err := watchPolled(func() ([]handlerPreference, error) {
+		return nil, ErrSystemError
+	}, diffHandlerPreferences, time.Millisecond); !errors.Is(err, ErrSystemError) {
+		t.Errorf("watchPolled() error = %v, want ErrSystemError", err)
+	}
+}
+
+// TestStrongestClaimants tests picking the apps that compete for a UTI
+func TestStrongestClaimants(t *testing.T) {
+	ranked := func(path string, rank HandlerRank) RankedApp {
+		return RankedApp{AppInfo: AppInfo{Path: path}, HandlerRank: rank}
+	}
+
+	tests := []struct {
+		name string
+		apps []RankedApp
+		want []string
+	}{
+		{
+			name: "two owners",
+			apps: []RankedApp{ranked("/B.app", HandlerRankOwner), ranked("/C.app", HandlerRankDefault), ranked("/A.app", HandlerRankOwner)},
+			want: []string{"/A.app", "/B.app"},
+		},
+		{
+			name: "defaults when no owner",
+			apps: []RankedApp{ranked("/A.app", HandlerRankAlternate), ranked("/B.app", HandlerRankDefault)},
+			want: []string{"/B.app"},
+		},
+		{
+			name: "no claims",
+			apps: []RankedApp{ranked("/A.app", ""), ranked("/B.app", HandlerRankNone)},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strongestClaimants(tt.apps)
+			paths := make([]string, len(got))
+			for i, app := range got {
+				paths[i] = app.Path
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("strongestClaimants() = %v, want %v", paths, tt.want)
+			}
+		})
+	}
+}
+
+// TestConfirmDefault tests checking the default read back after setting it
+func TestConfirmDefault(t *testing.T) {
+	set := func() error { return nil }
+	get := func(path string, err error) func() (string, error) {
+		return func() (string, error) { return path, err }
+	}
+
+	if err := confirmDefault("/Applications/Safari.app", set, get("/Applications/Safari.app", nil)); err != nil {
+		t.Errorf("confirmDefault(matching) error = %v, want nil", err)
+	}
+
+	err := confirmDefault("/Applications/Firefox.app", set, get("/Applications/Safari.app", nil))
+	if !errors.Is(err, ErrVerificationFailed) || !strings.Contains(err.Error(), "/Applications/Safari.app") {
+		t.Errorf("confirmDefault(ignored) error = %v, want ErrVerificationFailed naming the current default", err)
+	}
+
+	err = confirmDefault("/Applications/Firefox.app", set, get("", &BridgeError{Code: ErrNotFound, Message: "no default"}))
+	if !errors.Is(err, ErrVerificationFailed) || !errors.Is(err, ErrNotFoundError) {
+		t.Errorf("confirmDefault(unreadable) error = %v, want ErrVerificationFailed wrapping ErrNotFoundError", err)
+	}
+
+	err = confirmDefault("/Applications/Firefox.app", func() error { return ErrUserDeclinedError }, get("", nil))
+	if !errors.Is(err, ErrUserDeclinedError) || errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("confirmDefault(set fails) error = %v, want the set error unchanged", err)
+	}
+}
+
+// TestFindAppBundles tests finding app bundles below a directory without entering them
+func TestFindAppBundles(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"Top.app/Contents/MacOS",
+		"Utilities/Tool.app/Contents/Helpers/Nested.app",
+		"Docs",
+		"Deep/Folder/TooDeep.app",
+		"Elsewhere/Linked.app",
+		"Locked/Hidden.app",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "Fake.app"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "Elsewhere", "Linked.app"), filepath.Join(root, "Linked.app")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "missing"), filepath.Join(root, "Broken.app")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, "Locked"), 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(filepath.Join(root, "Locked"), 0o755)
+
+	bundles, err := findAppBundles(root)
+	if err != nil {
+		t.Fatalf("findAppBundles() error = %v", err)
+	}
+
+	want := []string{
+		filepath.Join(root, "Elsewhere", "Linked.app"),
+		filepath.Join(root, "Linked.app"),
+		filepath.Join(root, "Top.app"),
+		filepath.Join(root, "Utilities", "Tool.app"),
+	}
+	if os.Geteuid() == 0 {
+		// Permissions do not stop root from reading the locked folder
+		want = append(want[:2], append([]string{filepath.Join(root, "Locked", "Hidden.app")}, want[2:]...)...)
+	}
+	if strings.Join(bundles, ",") != strings.Join(want, ",") {
+		t.Errorf("findAppBundles() = %v, want %v", bundles, want)
+	}
+
+	bundles, err = findAppBundles(filepath.Join(root, "missing"))
+	if err != nil || len(bundles) != 0 {
+		t.Errorf("findAppBundles(missing) = %v, %v, want no bundles and no error", bundles, err)
+	}
+}
+
+// TestParseRole tests converting CFBundleTypeRole values to Role constants
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Role
+		wantErr bool
+	}{
+		{"Editor", RoleEditor, false},
+		{"viewer", RoleViewer, false},
+		{"Shell", RoleShell, false},
+		{"None", RoleNone, false},
+		{"All", RoleAll, false},
+		{"", "", false},
+		{"Owner", RoleUnknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRole(tt.input)
+			if got != tt.want {
+				t.Errorf("ParseRole(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRole(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidParameters) {
+				t.Errorf("ParseRole(%q) error = %v, want ErrInvalidParameters", tt.input, err)
+			}
+		})
+	}
+
+	if got := RoleEditor.String(); got != "Editor" {
+		t.Errorf("RoleEditor.String() = %q, want %q", got, "Editor")
+	}
+}
+
+// TestParseHandlerRank tests converting LSHandlerRank values to HandlerRank constants
+func TestParseHandlerRank(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    HandlerRank
+		wantErr bool
+	}{
+		{"Owner", HandlerRankOwner, false},
+		{"default", HandlerRankDefault, false},
+		{"Alternate", HandlerRankAlternate, false},
+		{"None", HandlerRankNone, false},
+		{"", "", false},
+		{"Editor", HandlerRankUnknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseHandlerRank(tt.input)
+			if got != tt.want {
+				t.Errorf("ParseHandlerRank(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseHandlerRank(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+
+	data, err := json.Marshal(DocumentType{Role: RoleEditor, HandlerRank: HandlerRankOwner})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"Role":"Editor","HandlerRank":"Owner"`) {
+		t.Errorf("json.Marshal() = %s, want plain string role and rank", data)
+	}
+}
+
+// TestFilterDocumentTypesByRole tests keeping only the document types with a given role
+func TestFilterDocumentTypesByRole(t *testing.T) {
+	docTypes := []DocumentType{
+		{TypeName: "Text", Role: "Editor"},
+		{TypeName: "PDF", Role: "Viewer"},
+		{TypeName: "Plugin", Role: "None"},
+		{TypeName: "Unspecified", Role: ""},
+	}
+
+	tests := []struct {
+		role Role
+		want []string
+	}{
+		{RoleEditor, []string{"Text"}},
+		{RoleViewer, []string{"PDF"}},
+		{RoleNone, []string{"Plugin"}},
+		{RoleShell, []string{}},
+		{RoleAll, []string{"Text", "PDF", "Plugin", "Unspecified"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			got := filterDocumentTypesByRole(docTypes, tt.role)
+			if got == nil {
+				t.Fatalf("filterDocumentTypesByRole() = nil, want empty slice")
+			}
+
+			names := make([]string, len(got))
+			for i, docType := range got {
+				names[i] = docType.TypeName
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("filterDocumentTypesByRole(%s) = %v, want %v", tt.role, names, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindDocumentTypeForUTI tests looking up the document type that declares a UTI
+func TestFindDocumentTypeForUTI(t *testing.T) {
+	docTypes := []DocumentType{
+		{TypeName: "Text", UTIs: []string{"public.plain-text", "public.utf8-plain-text"}},
+		{TypeName: "Image", UTIs: []string{"public.image"}},
+	}
+
+	tests := []struct {
+		uti      string
+		want     string
+		wantFind bool
+	}{
+		{"public.utf8-plain-text", "Text", true},
+		{"Public.Image", "Image", true},
+		{"public.jpeg", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uti, func(t *testing.T) {
+			got, ok := findDocumentTypeForUTI(docTypes, tt.uti)
+			if ok != tt.wantFind || got.TypeName != tt.want {
+				t.Errorf("findDocumentTypeForUTI(%s) = %q, %v, want %q, %v", tt.uti, got.TypeName, ok, tt.want, tt.wantFind)
+			}
+		})
+	}
+}
+
+// TestDeclaresOrConformsTo tests matching a UTI against declared types and their conformance
+func TestDeclaresOrConformsTo(t *testing.T) {
+	docTypes := []DocumentType{
+		{TypeName: "Image", UTIs: []string{"public.image"}},
+		{TypeName: "Text", UTIs: []string{"public.plain-text"}},
+	}
+	conforms := func(uti, parentUTI string) bool {
+		return uti == "public.jpeg" && parentUTI == "public.image"
+	}
+
+	tests := []struct {
+		uti  string
+		want bool
+	}{
+		{"public.plain-text", true},
+		{"public.jpeg", true},
+		{"com.adobe.pdf", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uti, func(t *testing.T) {
+			if got := declaresOrConformsTo(docTypes, tt.uti, conforms); got != tt.want {
+				t.Errorf("declaresOrConformsTo(%s) = %v, want %v", tt.uti, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSortRankedApps tests that the default comes first and ranks order the rest
+func TestSortRankedApps(t *testing.T) {
+	apps := []RankedApp{
+		{AppInfo: AppInfo{Name: "A"}, HandlerRank: "Alternate"},
+		{AppInfo: AppInfo{Name: "B"}, HandlerRank: "Owner"},
+		{AppInfo: AppInfo{Name: "C"}, HandlerRank: "None", IsDefault: true},
+		{AppInfo: AppInfo{Name: "D"}, HandlerRank: ""},
+		{AppInfo: AppInfo{Name: "E"}, HandlerRank: "Default"},
+		{AppInfo: AppInfo{Name: "F"}, HandlerRank: "Alternate"},
+	}
+
+	sortRankedApps(apps)
+
+	var names []string
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+
+	if got, want := strings.Join(names, ""), "CBEDAF"; got != want {
+		t.Errorf("sortRankedApps() order = %s, want %s", got, want)
+	}
+}
+
+// TestBridgeErrorIs tests that BridgeError codes match their sentinel errors
+func TestBridgeErrorIs(t *testing.T) {
+	tests := []struct {
+		code int
+		want error
+	}{
+		{ErrInvalidApp, ErrInvalidAppError},
+		{ErrInvalidUTI, ErrInvalidUTIError},
+		{ErrInvalidScheme, ErrInvalidSchemeError},
+		{ErrSystem, ErrSystemError},
+		{ErrUserDeclined, ErrUserDeclinedError},
+		{ErrNotFound, ErrNotFoundError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want.Error(), func(t *testing.T) {
+			err := fmt.Errorf("lookup: %w", &BridgeError{Code: tt.code, Message: "details"})
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.want)
+			}
+			if errors.Is(err, ErrInvalidParameters) {
+				t.Errorf("errors.Is(%v, ErrInvalidParameters) = true, want false", err)
+			}
+			if !strings.Contains(err.Error(), "details") {
+				t.Errorf("error %q lost the original message", err)
+			}
+		})
+	}
+
+	if errors.Is(&BridgeError{Code: ErrNotFound}, ErrInvalidUTIError) {
+		t.Errorf("ErrNotFound BridgeError matched ErrInvalidUTIError")
+	}
+}
+
+// TestBridgeErrorOSStatus tests that the OSStatus is reachable through errors.As and shown when set
+func TestBridgeErrorOSStatus(t *testing.T) {
+	err := fmt.Errorf("set default: %w", &BridgeError{Code: ErrSystem, Message: "denied", OSStatus: -54})
+
+	var bridgeErr *BridgeError
+	if !errors.As(err, &bridgeErr) {
+		t.Fatalf("errors.As(%v) = false, want true", err)
+	}
+	if bridgeErr.OSStatus != -54 {
+		t.Errorf("OSStatus = %d, want -54", bridgeErr.OSStatus)
+	}
+
+	want := "bridge error (code -4, OSStatus -54): denied"
+	if got := bridgeErr.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	want = "bridge error (code -4): denied"
+	if got := (&BridgeError{Code: ErrSystem, Message: "denied"}).Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package bridge
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// logger receives a record for every call into the macOS APIs; nil disables logging
+var logger atomic.Pointer[slog.Logger]
+
+// SetLogger sets the logger that traces calls into the macOS APIs
+//
+// Every call across the cgo boundary is logged at Debug level when it starts
+// and when it returns, with its UTI, scheme or path arguments, the bridge
+// result code and how long it took. Failed calls are logged at Error level
+// with the error message, except ErrNotFound results, which are an expected
+// answer to many queries and stay at Debug level.
+//
+// Logging is off by default. Passing nil turns it off again. SetLogger is safe
+// to call while other goroutines use the package.
+//
+// Parameters:
+//   - l: Logger to write to, or nil to disable logging
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+// callTrace is one call into the macOS APIs being logged
+type callTrace struct {
+	logger *slog.Logger
+	op     string
+	args   []any
+	start  time.Time
+}
+
+// startCall logs the start of a call and returns the trace to finish once it returns
+//
+// args are slog key-value pairs describing the call's arguments. When no
+// logger is set the returned trace does nothing.
+func startCall(op string, args ...any) callTrace {
+	l := logger.Load()
+	if l == nil {
+		return callTrace{}
+	}
+
+	l.Debug("bridge call", append([]any{"op", op}, args...)...)
+
+	return callTrace{logger: l, op: op, args: args, start: time.Now()}
+}
+
+// finish logs the result code of the call and, if it failed, the error message
+func (t callTrace) finish(code int, message string) {
+	if t.logger == nil {
+		return
+	}
+
+	attrs := append([]any{"op", t.op}, t.args...)
+	attrs = append(attrs, "code", code, "duration", time.Since(t.start))
+
+	switch code {
+	case ErrOK:
+		t.logger.Debug("bridge call returned", attrs...)
+	case ErrNotFound:
+		t.logger.Debug("bridge call failed", append(attrs, "error", message)...)
+	default:
+		t.logger.Error("bridge call failed", append(attrs, "error", message)...)
+	}
+}
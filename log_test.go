@@ -0,0 +1,55 @@
+package bridge
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestCallTrace tests the records written for successful, not-found and failed calls
+func TestCallTrace(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	startCall("GetDefaultAppForUTI", "uti", "public.plain-text").finish(ErrOK, "")
+	startCall("GetDefaultAppForScheme", "scheme", "gopher").finish(ErrNotFound, "no handler")
+	startCall("SetDefaultForUTI", "app", "/Applications/Safari.app", "uti", "public.html").finish(ErrSystem, "LaunchServices failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("got %d records, want 6:\n%s", len(lines), buf.String())
+	}
+
+	tests := []struct {
+		line int
+		want []string
+	}{
+		{0, []string{"level=DEBUG", `msg="bridge call"`, "op=GetDefaultAppForUTI", "uti=public.plain-text"}},
+		{1, []string{"level=DEBUG", `msg="bridge call returned"`, "code=0", "duration="}},
+		{3, []string{"level=DEBUG", `msg="bridge call failed"`, "code=-6", `error="no handler"`}},
+		{5, []string{"level=ERROR", "op=SetDefaultForUTI", "app=/Applications/Safari.app", "uti=public.html", "code=-4", `error="LaunchServices failed"`}},
+	}
+
+	for _, tt := range tests {
+		for _, want := range tt.want {
+			if !strings.Contains(lines[tt.line], want) {
+				t.Errorf("record %d = %q, want it to contain %q", tt.line, lines[tt.line], want)
+			}
+		}
+	}
+}
+
+// TestCallTraceWithoutLogger tests that nothing is logged until a logger is set
+func TestCallTraceWithoutLogger(t *testing.T) {
+	SetLogger(nil)
+
+	trace := startCall("OpenFile", "file", "/tmp/a.txt")
+	if trace.logger != nil {
+		t.Errorf("startCall() with no logger returned a trace with logger %v", trace.logger)
+	}
+
+	// Must not panic
+	trace.finish(ErrSystem, "failed")
+}
@@ -0,0 +1,369 @@
+package bridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BridgeError represents an error from the macOS bridge layer
+//
+// Use errors.As to inspect it:
+//
+//	var bridgeErr *BridgeError
+//	if errors.As(err, &bridgeErr) && bridgeErr.OSStatus != 0 {
+//		log.Printf("LaunchServices returned OSStatus %d", bridgeErr.OSStatus)
+//	}
+type BridgeError struct {
+	Code    int
+	Message string
+	// OSStatus is the status reported by LaunchServices or NSWorkspace when
+	// the failure came from the system, and 0 otherwise
+	OSStatus int
+}
+
+func (e *BridgeError) Error() string {
+	if e.OSStatus != 0 {
+		return fmt.Sprintf("bridge error (code %d, OSStatus %d): %s", e.Code, e.OSStatus, e.Message)
+	}
+	return fmt.Sprintf("bridge error (code %d): %s", e.Code, e.Message)
+}
+
+// Unwrap returns the sentinel error for the error code, so that
+// errors.Is(err, ErrNotFoundError) and friends work while the message is kept
+func (e *BridgeError) Unwrap() error {
+	switch e.Code {
+	case ErrInvalidApp:
+		return ErrInvalidAppError
+	case ErrInvalidUTI:
+		return ErrInvalidUTIError
+	case ErrInvalidScheme:
+		return ErrInvalidSchemeError
+	case ErrSystem:
+		return ErrSystemError
+	case ErrUserDeclined:
+		return ErrUserDeclinedError
+	case ErrNotFound:
+		return ErrNotFoundError
+	default:
+		return nil
+	}
+}
+
+// Error codes matching bridge.h
+const (
+	ErrOK            = 0
+	ErrInvalidApp    = -1
+	ErrInvalidUTI    = -2
+	ErrInvalidScheme = -3
+	ErrSystem        = -4
+	ErrUserDeclined  = -5
+	ErrNotFound      = -6
+)
+
+// Common errors
+var (
+	ErrInvalidParameters = errors.New("invalid parameters")
+	ErrMemoryAllocation  = errors.New("memory allocation failed")
+
+	// ErrDefaultHandlerInvalid is returned when LaunchServices reports a default
+	// handler whose path is not a valid application bundle
+	ErrDefaultHandlerInvalid = errors.New("default handler is not a valid application bundle")
+
+	// ErrAppDoesNotSupportUTI is returned by SetDefaultForUTI when the application
+	// declares no document type that can open the UTI
+	ErrAppDoesNotSupportUTI = errors.New("application does not support UTI")
+
+	// ErrCodeSignatureInvalid is returned by SetDefaultForUTIVerified when the
+	// application is unsigned or its signature does not validate
+	ErrCodeSignatureInvalid = errors.New("application code signature is invalid")
+
+	// ErrVerificationFailed is returned by SetDefaultForSchemeVerified and
+	// SetDefaultForUTIConfirmed when the default read back after setting it is
+	// not the requested app
+	ErrVerificationFailed = errors.New("default handler did not change")
+
+	// ErrUnsupportedPlatform is returned by every operation on platforms other than macOS
+	ErrUnsupportedPlatform = errors.New("macos-apphandlers-bridge: unsupported platform")
+)
+
+// Sentinel errors matching the BridgeError codes, for use with errors.Is
+var (
+	ErrInvalidAppError    = errors.New("invalid application")
+	ErrInvalidUTIError    = errors.New("invalid or unknown UTI")
+	ErrInvalidSchemeError = errors.New("invalid URL scheme")
+	ErrSystemError        = errors.New("system error")
+	ErrUserDeclinedError  = errors.New("user declined")
+	ErrNotFoundError      = errors.New("not found")
+)
+
+// AppInfo represents an installed application with its metadata
+type AppInfo struct {
+	Name         string // Application display name, or the bundle's file name without ".app" if it has none
+	Path         string // Full path to application bundle
+	BundleID     string // Bundle identifier (e.g., "com.apple.Safari"), empty if the bundle declares none
+	Version      string // CFBundleShortVersionString, empty if not declared
+	BuildVersion string // CFBundleVersion, empty if not declared
+	Category     string // LSApplicationCategoryType (e.g., "public.app-category.developer-tools"), empty if not declared
+}
+
+// AppSummary is a compact description of an application and the document types it handles
+type AppSummary struct {
+	AppInfo
+	DisplayVersion     string // AppInfo.Version, falling back to AppInfo.BuildVersion when empty
+	SupportedTypeCount int    // Number of document types the app declares
+	OwnedTypeCount     int    // Number of those document types with handler rank "Owner"
+}
+
+// AppProfile collects the document types and URL schemes an application supports and is the default for
+type AppProfile struct {
+	AppInfo
+	SupportedDocumentTypes []DocumentType // As returned by ListSupportedDocumentTypes
+	DefaultDocumentTypes   []DocumentType // As returned by ListDefaultDocumentTypes
+	SupportedSchemes       []string       // As returned by ListSupportedSchemes
+	DefaultSchemes         []string       // As returned by ListDefaultSchemes
+}
+
+// DocumentType represents a document type that an application can handle
+type DocumentType struct {
+	TypeName          string      // Human-readable name (e.g., "JPEG Image", "PDF Document")
+	Role              Role        // Role: "Editor", "Viewer", "Shell", "None", "Unknown" if unrecognized, or empty if not specified
+	HandlerRank       HandlerRank // Handler rank: "Owner", "Default", "Alternate", "None", "Unknown" if unrecognized, or empty if not specified
+	UTIs              []string    // Array of UTI identifiers
+	Extensions        []string    // Filename extensions the app declares for this document type
+	DerivedExtensions []string    // Extensions the type system reports for UTIs (only populated with WithDerived)
+	IsPackage         bool        // true if this is a package/bundle type
+}
+
+// TagSpec lists the tags a UTI is known by, grouped by tag class, in the order the type declares them
+type TagSpec struct {
+	Extensions      []string // Filename extensions, without dots
+	MIMETypes       []string // MIME types
+	PasteboardTypes []string // Legacy NSPasteboard type names (com.apple.nspboard-type)
+	OSTypes         []string // Classic Mac OS four-character type codes (com.apple.ostype)
+}
+
+// RankedApp is an application that can open a UTI, with the rank and role it declares for that UTI
+type RankedApp struct {
+	AppInfo
+	HandlerRank HandlerRank // Handler rank: "Owner", "Default", "Alternate", "None", or empty if not specified
+	Role        Role        // Role: "Editor", "Viewer", "Shell", "None", or empty if the app declares no matching type
+	IsDefault   bool        // true if the app is the current default handler for the UTI
+}
+
+// Role is the capacity in which an application handles a content type
+type Role string
+
+// Handler roles, mirroring LaunchServices' LSRolesMask
+const (
+	RoleViewer Role = "Viewer" // Can read and present the type
+	RoleEditor Role = "Editor" // Can read, manipulate and save the type
+	RoleShell  Role = "Shell"  // Provides runtime services for the type
+	RoleAll    Role = "All"    // Any role
+	RoleNone   Role = "None"   // Declares the type without being able to open it
+
+	// RoleUnknown stands in for a role value this package does not recognize
+	RoleUnknown Role = "Unknown"
+)
+
+// ParseRole converts a CFBundleTypeRole value to a Role
+//
+// Matching is case-insensitive. An empty string means no role was declared and
+// parses to the empty Role. Any other unrecognized value returns RoleUnknown and
+// ErrInvalidParameters.
+func ParseRole(s string) (Role, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, role := range []Role{RoleViewer, RoleEditor, RoleShell, RoleAll, RoleNone} {
+		if strings.EqualFold(s, string(role)) {
+			return role, nil
+		}
+	}
+	return RoleUnknown, fmt.Errorf("%w: unknown role %q", ErrInvalidParameters, s)
+}
+
+// String returns the role as it appears in CFBundleTypeRole
+func (r Role) String() string {
+	return string(r)
+}
+
+// HandlerRank is how strongly an application claims a document type (LSHandlerRank)
+type HandlerRank string
+
+// Handler ranks, from most to least preferred
+const (
+	HandlerRankOwner     HandlerRank = "Owner"     // The app created the format
+	HandlerRankDefault   HandlerRank = "Default"   // The app is a primary editor or viewer
+	HandlerRankAlternate HandlerRank = "Alternate" // The app is a secondary handler
+	HandlerRankNone      HandlerRank = "None"      // The app should never be chosen to open the type
+
+	// HandlerRankUnknown stands in for a rank value this package does not recognize
+	HandlerRankUnknown HandlerRank = "Unknown"
+)
+
+// ParseHandlerRank converts an LSHandlerRank value to a HandlerRank
+//
+// Matching is case-insensitive. An empty string means no rank was declared and
+// parses to the empty HandlerRank. Any other unrecognized value returns
+// HandlerRankUnknown and ErrInvalidParameters.
+func ParseHandlerRank(s string) (HandlerRank, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, rank := range []HandlerRank{HandlerRankOwner, HandlerRankDefault, HandlerRankAlternate, HandlerRankNone} {
+		if strings.EqualFold(s, string(rank)) {
+			return rank, nil
+		}
+	}
+	return HandlerRankUnknown, fmt.Errorf("%w: unknown handler rank %q", ErrInvalidParameters, s)
+}
+
+// String returns the rank as it appears in LSHandlerRank
+func (r HandlerRank) String() string {
+	return string(r)
+}
+
+// DocumentTypeOption configures how ListSupportedDocumentTypes builds its results
+type DocumentTypeOption func(*documentTypeOptions)
+
+type documentTypeOptions struct {
+	derived bool
+}
+
+// WithDerived populates DocumentType.DerivedExtensions from the UTIs alone
+//
+// Extensions present in Extensions but missing from DerivedExtensions were
+// declared by the app (CFBundleTypeExtensions) without being backed by its UTIs.
+func WithDerived() DocumentTypeOption {
+	return func(o *documentTypeOptions) {
+		o.derived = true
+	}
+}
+
+// TypeFamily is a broad top-level category of document types
+type TypeFamily string
+
+// Document type families, used by GroupSupportedDocumentTypesByFamily
+const (
+	FamilyAudiovisual TypeFamily = "audiovisual" // Conforms to public.audiovisual-content
+	FamilyImage       TypeFamily = "image"       // Conforms to public.image
+	FamilyText        TypeFamily = "text"        // Conforms to public.text
+	FamilyData        TypeFamily = "data"        // Conforms to public.data
+	FamilyOther       TypeFamily = "other"       // Conforms to none of the above
+)
+
+// familyPriority lists the families in the order used to pick a primary family
+var familyPriority = []struct {
+	family TypeFamily
+	uti    string
+}{
+	{FamilyAudiovisual, "public.audiovisual-content"},
+	{FamilyImage, "public.image"},
+	{FamilyText, "public.text"},
+	{FamilyData, "public.data"},
+}
+
+// HandlerSnapshot captures the default handlers for content types and URL schemes
+//
+// In JSON each handler is stored by bundle ID rather than path so that a
+// snapshot survives app relocations and can be shared across machines; use
+// ResolveSnapshot to turn the bundle IDs back into paths after decoding.
+type HandlerSnapshot struct {
+	UTIs    map[string]AppInfo // UTI -> default application
+	Schemes map[string]AppInfo // URL scheme -> default application
+}
+
+// handlerSnapshotJSON is the on-disk form of a HandlerSnapshot
+type handlerSnapshotJSON struct {
+	UTIs    map[string]string `json:"utis"`    // UTI -> bundle ID
+	Schemes map[string]string `json:"schemes"` // URL scheme -> bundle ID
+}
+
+// MarshalJSON encodes the snapshot keyed by bundle ID
+//
+// Map keys are sorted by encoding/json, so the output is stable. Handlers
+// without a bundle ID cannot be restored from JSON, so encoding fails with an
+// ErrInvalidParameters error naming them as "UTI <uti>" or "scheme <scheme>";
+// delete those entries first to save the rest.
+func (s HandlerSnapshot) MarshalJSON() ([]byte, error) {
+	var missing []string
+	bundleIDs := func(kind string, defaults map[string]AppInfo) map[string]string {
+		out := make(map[string]string, len(defaults))
+		for identifier, app := range defaults {
+			if app.BundleID == "" {
+				missing = append(missing, kind+" "+identifier)
+				continue
+			}
+			out[identifier] = app.BundleID
+		}
+		return out
+	}
+
+	wire := handlerSnapshotJSON{
+		UTIs:    bundleIDs("UTI", s.UTIs),
+		Schemes: bundleIDs("scheme", s.Schemes),
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: no bundle ID for %s", ErrInvalidParameters, strings.Join(missing, ", "))
+	}
+
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON decodes a snapshot written by MarshalJSON
+//
+// Only the bundle IDs are known after decoding; ResolveSnapshot fills in the
+// paths, and RestoreDefaults looks them up itself.
+func (s *HandlerSnapshot) UnmarshalJSON(data []byte) error {
+	var wire handlerSnapshotJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	apps := func(bundleIDs map[string]string) map[string]AppInfo {
+		out := make(map[string]AppInfo, len(bundleIDs))
+		for identifier, bundleID := range bundleIDs {
+			out[identifier] = AppInfo{BundleID: bundleID}
+		}
+		return out
+	}
+
+	s.UTIs = apps(wire.UTIs)
+	s.Schemes = apps(wire.Schemes)
+	return nil
+}
+
+// HandlerChangeEvent reports that the user's default handler for a UTI or URL scheme changed
+type HandlerChangeEvent struct {
+	Kind       string  `json:"kind"`       // "uti" or "scheme"
+	Identifier string  `json:"identifier"` // UTI or URL scheme
+	Role       Role    `json:"role"`       // Role whose handler changed
+	Previous   AppInfo `json:"previous"`   // Handler before the change; zero if there was none
+	Current    AppInfo `json:"current"`    // Handler after the change; zero if the default was removed
+}
+
+// AppChange says whether an application appeared or disappeared
+type AppChange string
+
+const (
+	AppAdded   AppChange = "added"
+	AppRemoved AppChange = "removed"
+)
+
+// AppChangeEvent reports that an application bundle was installed or removed
+type AppChangeEvent struct {
+	Path   string    `json:"path"`   // Full path to the application bundle
+	Change AppChange `json:"change"` // AppAdded or AppRemoved
+}
+
+// handlerPreference is one role of a LaunchServices LSHandlers entry
+type handlerPreference struct {
+	Kind       string // "uti" or "scheme"
+	Identifier string // UTI or URL scheme
+	Role       string // "All", "Viewer", "Editor", "Shell"
+	App        AppInfo
+}